@@ -0,0 +1,320 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+const (
+	GroupByVariety = "variety"
+	GroupByOrigin  = "origin"
+	GroupByQuality = "quality"
+
+	MetricWeight          = "weight"
+	MetricPrice           = "price"
+	MetricWeightHistogram = "weight_histogram"
+	MetricPriceHistogram  = "price_histogram"
+	MetricAge             = "age"
+	MetricAll             = "all"
+
+	weightBinKg = 0.1
+	priceBin    = 1.0
+
+	// maxHistogramBins caps a histogram's length; wider ranges get wider
+	// bins, so one outlier cannot blow up the response.
+	maxHistogramBins = 50
+)
+
+var (
+	ErrInvalidGroupBy = errors.New("group_by must be a combination of variety, origin and quality")
+	ErrInvalidMetric  = errors.New("metrics must be a combination of weight, price, weight_histogram, price_histogram, age or all")
+
+	dimensions = []string{GroupByVariety, GroupByOrigin, GroupByQuality}
+	metrics    = []string{MetricWeight, MetricPrice, MetricWeightHistogram, MetricPriceHistogram, MetricAge}
+
+	// Age bins in days since harvest; the last is open-ended.
+	ageBins = []float64{0, 7, 14, 30, 60}
+)
+
+// Normalize lowercases and de-duplicates the query, expands "all" and
+// rejects unknown dimensions and metrics. Dimensions keep the order given,
+// since it is the order of the group key.
+func Normalize(query models.AnalyticsQuery) (models.AnalyticsQuery, error) {
+	groupBy, err := normalizeList(query.GroupBy, dimensions, "", ErrInvalidGroupBy)
+	if err != nil {
+		return models.AnalyticsQuery{}, err
+	}
+	metricList, err := normalizeList(query.Metrics, metrics, MetricAll, ErrInvalidMetric)
+	if err != nil {
+		return models.AnalyticsQuery{}, err
+	}
+	return models.AnalyticsQuery{GroupBy: groupBy, Metrics: metricList}, nil
+}
+
+func normalizeList(values, allowed []string, all string, invalid error) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" || seen[value] {
+			continue
+		}
+		if all != "" && value == all {
+			return allowed, nil
+		}
+		known := false
+		for _, candidate := range allowed {
+			known = known || candidate == value
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown %q", invalid, value)
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// Compute describes the given potatoes as of now. The query must have been
+// normalized.
+func Compute(potatoes []models.Potato, query models.AnalyticsQuery, now time.Time) models.PotatoAnalytics {
+	result := models.PotatoAnalytics{
+		TotalPotatoes:    len(potatoes),
+		AnalyticsMetrics: computeMetrics(potatoes, query.Metrics, now),
+		GroupBy:          query.GroupBy,
+	}
+	if len(potatoes) == 0 {
+		return result
+	}
+
+	varietyCount := make(map[string]int)
+	totalWeight := 0.0
+	premiumCount := 0
+	for _, potato := range potatoes {
+		varietyCount[potato.Variety]++
+		totalWeight += potato.Weight
+		result.TotalValue += potato.Price
+		if potato.Quality == string(models.Premium) {
+			premiumCount++
+		}
+	}
+	result.MostPopularVarieties = MostPopular(varietyCount)
+	result.MostPopularVariety = result.MostPopularVarieties[0]
+	result.AverageWeight = totalWeight / float64(len(potatoes))
+	result.PremiumPercentage = float64(premiumCount) / float64(len(potatoes)) * 100
+
+	if len(query.GroupBy) > 0 {
+		result.Groups = computeGroups(potatoes, query, now)
+	}
+	return result
+}
+
+// FromAggregates builds the basic analytics, without metrics or groups,
+// from maintained stock totals instead of a scan.
+func FromAggregates(aggregates models.InventoryAggregates) models.PotatoAnalytics {
+	result := models.PotatoAnalytics{TotalPotatoes: aggregates.Total.Count}
+	if aggregates.Total.Count == 0 {
+		return result
+	}
+
+	varietyCount := make(map[string]int, len(aggregates.ByVariety))
+	for variety, totals := range aggregates.ByVariety {
+		varietyCount[variety] = totals.Count
+	}
+	total := float64(aggregates.Total.Count)
+	result.MostPopularVarieties = MostPopular(varietyCount)
+	result.MostPopularVariety = result.MostPopularVarieties[0]
+	result.AverageWeight = aggregates.Total.Weight / total
+	result.PremiumPercentage = float64(aggregates.ByQuality[string(models.Premium)].Count) / total * 100
+	result.TotalValue = aggregates.Total.Value
+	return result
+}
+
+// MostPopular returns every variety with the highest count, sorted by name,
+// so ties are reported rather than broken by map order.
+func MostPopular(counts map[string]int) []string {
+	best := 0
+	var varieties []string
+	for variety, count := range counts {
+		switch {
+		case count > best:
+			best = count
+			varieties = []string{variety}
+		case count == best:
+			varieties = append(varieties, variety)
+		}
+	}
+	sort.Strings(varieties)
+	return varieties
+}
+
+func computeGroups(potatoes []models.Potato, query models.AnalyticsQuery, now time.Time) []models.AnalyticsGroup {
+	members := make(map[string][]models.Potato)
+	keys := make(map[string]map[string]string)
+	for _, potato := range potatoes {
+		key := make(map[string]string, len(query.GroupBy))
+		parts := make([]string, len(query.GroupBy))
+		for i, dimension := range query.GroupBy {
+			key[dimension] = dimensionValue(potato, dimension)
+			parts[i] = key[dimension]
+		}
+		id := strings.Join(parts, "\x00")
+		members[id] = append(members[id], potato)
+		keys[id] = key
+	}
+
+	groups := make([]models.AnalyticsGroup, 0, len(members))
+	for id, group := range members {
+		g := models.AnalyticsGroup{
+			Key:              keys[id],
+			Count:            len(group),
+			AnalyticsMetrics: computeMetrics(group, query.Metrics, now),
+		}
+		for _, potato := range group {
+			g.TotalWeight += potato.Weight
+			g.TotalValue += potato.Price
+		}
+		g.TotalWeight = round(g.TotalWeight, 3)
+		g.TotalValue = round(g.TotalValue, 2)
+		groups = append(groups, g)
+	}
+
+	// Largest groups first, then by key for a stable order.
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groupID(groups[i], query.GroupBy) < groupID(groups[j], query.GroupBy)
+	})
+	return groups
+}
+
+func groupID(group models.AnalyticsGroup, dimensions []string) string {
+	parts := make([]string, len(dimensions))
+	for i, dimension := range dimensions {
+		parts[i] = group.Key[dimension]
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func dimensionValue(potato models.Potato, dimension string) string {
+	switch dimension {
+	case GroupByVariety:
+		return potato.Variety
+	case GroupByOrigin:
+		return potato.Origin
+	default:
+		return potato.Quality
+	}
+}
+
+func computeMetrics(potatoes []models.Potato, requested []string, now time.Time) models.AnalyticsMetrics {
+	var result models.AnalyticsMetrics
+	if len(potatoes) == 0 {
+		return result
+	}
+
+	weights := make([]float64, len(potatoes))
+	prices := make([]float64, len(potatoes))
+	for i, potato := range potatoes {
+		weights[i] = potato.Weight
+		prices[i] = potato.Price
+	}
+	sort.Float64s(weights)
+	sort.Float64s(prices)
+
+	for _, metric := range requested {
+		switch metric {
+		case MetricWeight:
+			result.Weight = summarize(weights, 3)
+		case MetricPrice:
+			result.Price = summarize(prices, 2)
+		case MetricWeightHistogram:
+			result.WeightHistogram = histogram(weights, weightBinKg, "%.1f-%.1f kg")
+		case MetricPriceHistogram:
+			result.PriceHistogram = histogram(prices, priceBin, "%.2f-%.2f")
+		case MetricAge:
+			result.AgeDistribution = ageDistribution(potatoes, now)
+		}
+	}
+	return result
+}
+
+// summarize expects sorted values.
+func summarize(sorted []float64, decimals int) *models.Summary {
+	total := 0.0
+	for _, value := range sorted {
+		total += value
+	}
+	return &models.Summary{
+		Min:  round(sorted[0], decimals),
+		Max:  round(sorted[len(sorted)-1], decimals),
+		Mean: round(total/float64(len(sorted)), decimals),
+		P50:  round(percentile(sorted, 50), decimals),
+		P90:  round(percentile(sorted, 90), decimals),
+		P99:  round(percentile(sorted, 99), decimals),
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of sorted
+// values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// histogram buckets sorted values into fixed-width bins from the one holding
+// the smallest value to the one holding the largest, including empty bins
+// in between so charts keep an even axis. When that would take more than
+// maxHistogramBins, the width is multiplied until the range fits.
+func histogram(sorted []float64, width float64, label string) []models.HistogramBin {
+	first := math.Floor(sorted[0]/width + 1e-9)
+	last := math.Floor(sorted[len(sorted)-1]/width + 1e-9)
+	for last-first+1 > maxHistogramBins {
+		width *= math.Max(math.Ceil((last-first+1)/maxHistogramBins), 2)
+		first = math.Floor(sorted[0]/width + 1e-9)
+		last = math.Floor(sorted[len(sorted)-1]/width + 1e-9)
+	}
+	bins := make([]models.HistogramBin, int(last-first)+1)
+	for i := range bins {
+		from := round((first+float64(i))*width, 2)
+		to := round(from+width, 2)
+		bins[i] = models.HistogramBin{Label: fmt.Sprintf(label, from, to), From: from, To: to}
+	}
+	for _, value := range sorted {
+		bins[int(math.Floor(value/width+1e-9)-first)].Count++
+	}
+	return bins
+}
+
+func ageDistribution(potatoes []models.Potato, now time.Time) []models.HistogramBin {
+	bins := make([]models.HistogramBin, len(ageBins))
+	for i, from := range ageBins {
+		bins[i].From = from
+		if i+1 < len(ageBins) {
+			bins[i].To = ageBins[i+1]
+			bins[i].Label = fmt.Sprintf("%.0f-%.0f days", from, ageBins[i+1]-1)
+		} else {
+			bins[i].Label = fmt.Sprintf("%.0f+ days", from)
+		}
+	}
+	for _, potato := range potatoes {
+		age := math.Max(math.Floor(now.Sub(potato.HarvestDate).Hours()/24), 0)
+		i := sort.SearchFloat64s(ageBins, age+1) - 1
+		bins[i].Count++
+	}
+	return bins
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
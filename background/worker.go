@@ -3,11 +3,16 @@ package background
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
 	"github.com/williamdumont/potato-demo/storage"
+	"github.com/williamdumont/potato-demo/taxonomy"
 	logapi "go.opentelemetry.io/otel/log"
 )
 
@@ -24,27 +29,36 @@ var fakeUserEmails = []string{
 }
 
 var (
-	varieties = []string{"Russet", "Yukon Gold", "Red Potato", "Fingerling", "Sweet Potato", "Purple Potato"}
 	origins   = []string{"Idaho", "Washington", "Maine", "California", "North Carolina", "Quebec", "Peru", "Colorado"}
 	qualities = []string{string(models.Premium), string(models.Standard), string(models.Economy)}
 
-	recipeNames = map[string][]string{
-		"Russet":        {"Loaded Baked Potato", "Perfect French Fries", "Potato Wedges", "Russet Gratin"},
-		"Yukon Gold":    {"Creamy Potato Soup", "Golden Potato Pancakes", "Yukon Scalloped Potatoes"},
-		"Red Potato":    {"Red Potato Hash", "Potato Salad Deluxe", "Herbed Red Potatoes"},
-		"Fingerling":    {"Crispy Fingerlings", "Fingerling Confit", "Fancy Fingerling Salad"},
-		"Sweet Potato":  {"Sweet Potato Casserole", "Sweet Potato Chips", "Candied Sweet Potatoes"},
-		"Purple Potato": {"Purple Potato Mash", "Colorful Potato Medley", "Purple Potato Gnocchi"},
+	// Recipe names are built from a variety's best cooking methods, so new
+	// catalog entries get sensible recipes without touching the worker.
+	methodRecipeNames = map[models.CookingMethod][]string{
+		models.Baked:   {"Loaded Baked %s", "%s Gratin", "Twice-Baked %s"},
+		models.Fried:   {"Crispy %s Fries", "%s Pancakes", "%s Chips"},
+		models.Mashed:  {"Creamy %s Mash", "Garlic %s Purée"},
+		models.Boiled:  {"%s Salad", "Buttered %s", "%s Soup"},
+		models.Roasted: {"Roasted %s", "Herbed %s Medley", "%s Hash"},
 	}
 
 	difficulties = []string{"Easy", "Medium", "Hard"}
 
+	lotCertifications = []string{"", "Organic", "GlobalG.A.P."}
+
 	counter = 1000
 )
 
+const workerAuthor = "background-worker"
+
 type Worker struct {
-	storage storage.Storage
-	logger  Logger
+	storage     storage.Storage
+	degradation *service.DegradationService
+	pricing     *service.PricingService
+	inventory   *service.InventoryHistoryService
+	forecasts   *service.ForecastService
+	warehouses  *service.WarehouseService
+	logger      Logger
 }
 
 type Logger interface {
@@ -52,10 +66,20 @@ type Logger interface {
 	EmitInfoLog(ctx context.Context, message string, attrs ...logapi.KeyValue)
 }
 
-func NewWorker(storage storage.Storage, logger Logger) *Worker {
+// AlertRecorder is implemented by loggers that also export metrics.
+type AlertRecorder interface {
+	RecordStockAlert(ctx context.Context, variety, severity string)
+}
+
+func NewWorker(storage storage.Storage, degradation *service.DegradationService, pricing *service.PricingService, inventory *service.InventoryHistoryService, forecasts *service.ForecastService, warehouses *service.WarehouseService, logger Logger) *Worker {
 	return &Worker{
-		storage: storage,
-		logger:  logger,
+		storage:     storage,
+		degradation: degradation,
+		pricing:     pricing,
+		inventory:   inventory,
+		forecasts:   forecasts,
+		warehouses:  warehouses,
+		logger:      logger,
 	}
 }
 
@@ -86,6 +110,42 @@ func (w *Worker) StartQualityDegradation(interval time.Duration) {
 	}()
 }
 
+func (w *Worker) StartRepricing(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			w.repricePotatoes()
+		}
+	}()
+}
+
+// StartInventorySnapshots records a snapshot straight away, so trends have
+// a starting point, and then once per interval.
+func (w *Worker) StartInventorySnapshots(interval time.Duration) {
+	if w.inventory == nil {
+		return
+	}
+	w.inventory.Snapshot()
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			w.inventory.Snapshot()
+		}
+	}()
+}
+
+// StartAlertCheck checks stock straight away, so the alerts list is filled
+// from the start, and then once per interval.
+func (w *Worker) StartAlertCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		w.checkStockAlerts()
+		for range ticker.C {
+			w.checkStockAlerts()
+		}
+	}()
+}
+
 func (w *Worker) StartPotatoRemover(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	go func() {
@@ -96,7 +156,10 @@ func (w *Worker) StartPotatoRemover(interval time.Duration) {
 }
 
 func (w *Worker) removeRandomPotatoes() {
-	potatoes := w.storage.GetAllPotatoes()
+	// Quarantined potatoes wait for a recall disposal and are never sold.
+	potatoes := slices.DeleteFunc(w.storage.GetAllPotatoes(), func(p models.Potato) bool {
+		return p.Quarantined
+	})
 	if len(potatoes) == 0 {
 		return
 	}
@@ -114,7 +177,12 @@ func (w *Worker) removeRandomPotatoes() {
 
 	for i := 0; i < numToRemove; i++ {
 		potato := potatoes[i]
-		err := w.storage.DeletePotato(potato.ID)
+		// Most stock leaves sold; the odd potato is dropped in handling.
+		reason := models.RemovalSold
+		if rand.Float64() < 0.1 {
+			reason = models.RemovalDamaged
+		}
+		err := w.storage.DeletePotato(potato.ID, reason)
 		if err == nil {
 			// Simulate a log with sensitive data (for exercise purposes)
 			userEmail := fakeUserEmails[rand.Intn(len(fakeUserEmails))]
@@ -125,6 +193,7 @@ func (w *Worker) removeRandomPotatoes() {
 					logapi.String("potato_id", potato.ID),
 					logapi.String("variety", potato.Variety),
 					logapi.Float64("weight_kg", potato.Weight),
+					logapi.String("reason", string(reason)),
 					logapi.String("action_id", actionID))
 			}
 		}
@@ -135,69 +204,140 @@ func (w *Worker) addRandomPotato() {
 	counter++
 	id := fmt.Sprintf("p%d", counter)
 
-	variety := varieties[rand.Intn(len(varieties))]
+	catalog, ok := w.randomVariety()
+	if !ok {
+		return
+	}
+	variety := catalog.Name
 	origin := origins[rand.Intn(len(origins))]
 	quality := qualities[rand.Intn(len(qualities))]
 
 	weight := 0.20 + rand.Float64()*0.40
-	basePrice := 2.0
-	if quality == string(models.Premium) {
-		basePrice = 3.5
-	} else if quality == string(models.Economy) {
-		basePrice = 1.5
-	}
-	price := basePrice + rand.Float64()*1.5
 
 	daysAgo := rand.Intn(14)
-	harvestDate := time.Now().AddDate(0, 0, -daysAgo)
+	lot, ok := w.harvestLot(origin, time.Now().AddDate(0, 0, -daysAgo))
+	if !ok {
+		return
+	}
+
+	// Growers are paid 45-65% of the catalog base price.
+	cost := catalog.BasePrice * weight * (0.45 + rand.Float64()*0.20)
 
 	potato := models.Potato{
-		ID:          id,
-		Variety:     variety,
-		Origin:      origin,
-		Weight:      weight,
-		Quality:     quality,
-		HarvestDate: harvestDate,
-		Price:       price,
+		ID:           id,
+		Variety:      variety,
+		Origin:       origin,
+		Weight:       weight,
+		Quality:      quality,
+		HarvestDate:  lot.HarvestDate,
+		PurchaseCost: math.Round(cost*100) / 100,
+		LotID:        lot.ID,
+	}
+	if w.pricing != nil {
+		potato.Price = w.pricing.Quote(potato, time.Now()).Price
 	}
 
-	w.storage.AddPotato(potato)
+	if w.warehouses != nil {
+		placed, err := w.warehouses.Place(potato, w.storage.AddPotato)
+		if err != nil {
+			if w.logger != nil {
+				w.logger.EmitDebugLog(context.Background(), "Background worker could not store potato",
+					logapi.String("potato_id", id),
+					logapi.String("error", err.Error()))
+			}
+			return
+		}
+		potato = placed
+	} else {
+		w.storage.AddPotato(potato)
+	}
 
 	if w.logger != nil {
 		w.logger.EmitDebugLog(context.Background(), "Background worker added potato",
 			logapi.String("potato_id", id),
 			logapi.String("variety", variety),
-			logapi.String("quality", quality))
+			logapi.String("quality", quality),
+			logapi.String("location_id", potato.LocationID))
+	}
+}
+
+// harvestLot returns the lot for a day's harvest from origin, creating it
+// on first use. It reports false once the lot has been recalled, so no
+// more stock is taken from it.
+func (w *Worker) harvestLot(origin string, harvested time.Time) (models.HarvestLot, bool) {
+	day := harvested.UTC().Truncate(24 * time.Hour)
+	id := strings.ToLower(strings.ReplaceAll(origin, " ", "-")) + "-" + day.Format("20060102")
+	if lot, err := w.storage.GetLot(id); err == nil {
+		return lot, lot.Status != models.LotRecalled
+	}
+
+	lot := models.HarvestLot{
+		ID:            id,
+		Farm:          origin + " Growers Co-op",
+		Field:         fmt.Sprintf("Field %d", 1+rand.Intn(12)),
+		Origin:        origin,
+		HarvestDate:   day,
+		Certification: lotCertifications[rand.Intn(len(lotCertifications))],
+		Status:        models.LotActive,
+		CreatedAt:     time.Now(),
 	}
+	w.storage.AddLot(lot)
+	return lot, true
 }
 
 func (w *Worker) addRandomRecipe() {
 	counter++
 	id := fmt.Sprintf("r%d", counter)
 
-	variety := varieties[rand.Intn(len(varieties))]
-	names := recipeNames[variety]
-	name := names[rand.Intn(len(names))]
+	catalog, ok := w.randomVariety()
+	if !ok {
+		return
+	}
+	variety := catalog.Name
+	methods := catalog.BestCookingMethods
+	if len(methods) == 0 {
+		methods = models.CookingMethods
+	}
+	method := methods[rand.Intn(len(methods))]
+	names := methodRecipeNames[method]
+	name := fmt.Sprintf(names[rand.Intn(len(names))], variety)
 
 	difficulty := difficulties[rand.Intn(len(difficulties))]
 	cookingTime := 20 + rand.Intn(60)
 	servings := 2 + rand.Intn(6)
 
 	ingredients := generateRandomIngredients(variety)
-	instructions := generateRandomInstructions()
+	instructions := generateRandomInstructions(method)
+
+	tags := []string{strings.ToLower(variety), strings.ToLower(difficulty)}
+	if cookingTime <= 30 {
+		tags = append(tags, "quick")
+	}
 
 	recipe := models.Recipe{
-		ID:           id,
-		Name:         name,
-		Variety:      variety,
-		CookingTime:  cookingTime,
-		Difficulty:   difficulty,
-		Ingredients:  ingredients,
-		Instructions: instructions,
-		Servings:     servings,
+		ID:            id,
+		Name:          name,
+		Variety:       variety,
+		CookingTime:   cookingTime,
+		Difficulty:    difficulty,
+		Ingredients:   ingredients,
+		Instructions:  instructions,
+		Servings:      servings,
+		CookingMethod: method,
+		Tags:          tags,
+		Dietary:       taxonomy.InferDietary(ingredients),
+		Allergens:     taxonomy.DetectAllergens(ingredients),
+		UpdatedBy:     workerAuthor,
 	}
 
-	w.storage.AddRecipe(recipe)
+	if err := w.storage.AddRecipe(recipe); err != nil {
+		if w.logger != nil {
+			w.logger.EmitDebugLog(context.Background(), "Background worker could not store recipe",
+				logapi.String("recipe_id", id),
+				logapi.String("error", err.Error()))
+		}
+		return
+	}
 
 	if w.logger != nil {
 		w.logger.EmitDebugLog(context.Background(), "Background worker added recipe",
@@ -207,27 +347,72 @@ func (w *Worker) addRandomRecipe() {
 	}
 }
 
-func (w *Worker) degradePotatoQuality() {
-	potatoes := w.storage.GetAllPotatoes()
-	degradedCount := 0
-
-	for _, potato := range potatoes {
-		daysSinceHarvest := int(time.Since(potato.HarvestDate).Hours() / 24)
-
-		if daysSinceHarvest > 30 && potato.Quality == string(models.Premium) {
-			potato.Quality = string(models.Standard)
-			w.storage.UpdatePotato(potato.ID, potato)
-			degradedCount++
-		} else if daysSinceHarvest > 60 && potato.Quality == string(models.Standard) {
-			potato.Quality = string(models.Economy)
-			w.storage.UpdatePotato(potato.ID, potato)
-			degradedCount++
+// randomVariety picks from the live catalog, so varieties added or removed
+// through the API are reflected in generated stock and recipes.
+func (w *Worker) randomVariety() (models.Variety, bool) {
+	varieties := w.storage.GetAllVarieties()
+	if len(varieties) == 0 {
+		return models.Variety{}, false
+	}
+	return varieties[rand.Intn(len(varieties))], true
+}
+
+func (w *Worker) repricePotatoes() {
+	if w.pricing == nil {
+		return
+	}
+	changes := w.pricing.Reprice()
+
+	if len(changes) > 0 && w.logger != nil {
+		w.logger.EmitDebugLog(context.Background(), "Background worker repriced potatoes",
+			logapi.Int("count", len(changes)))
+	}
+}
+
+// checkStockAlerts reports each low-stock alert once when raised, and again
+// if it becomes critical.
+func (w *Worker) checkStockAlerts() {
+	if w.forecasts == nil {
+		return
+	}
+	_, raised := w.forecasts.CheckAlerts()
+
+	ctx := context.Background()
+	recorder, _ := w.logger.(AlertRecorder)
+	for _, alert := range raised {
+		if w.logger != nil {
+			w.logger.EmitInfoLog(ctx, "Low stock alert: "+alert.Message,
+				logapi.String("variety", alert.Variety),
+				logapi.String("severity", string(alert.Severity)),
+				logapi.Int("on_hand", alert.OnHand),
+				logapi.Float64("reorder_point", alert.ReorderPoint),
+				logapi.Int("suggested_order_quantity", alert.SuggestedOrderQuantity))
 		}
+		if recorder != nil {
+			recorder.RecordStockAlert(ctx, alert.Variety, string(alert.Severity))
+		}
+	}
+}
+
+// degradePotatoQuality applies the configured degradation policy. The
+// service records an audit entry for every potato it changes.
+func (w *Worker) degradePotatoQuality() {
+	if w.degradation == nil {
+		return
 	}
+	entries := w.degradation.Apply(workerAuthor)
 
-	if degradedCount > 0 && w.logger != nil {
+	if len(entries) > 0 && w.logger != nil {
+		discarded := 0
+		for _, entry := range entries {
+			if entry.Action == models.DiscardPotato {
+				discarded++
+			}
+		}
 		w.logger.EmitDebugLog(context.Background(), "Background worker degraded potato quality",
-			logapi.Int("count", degradedCount))
+			logapi.String("policy", entries[0].Policy),
+			logapi.Int("count", len(entries)-discarded),
+			logapi.Int("discarded", discarded))
 	}
 }
 
@@ -259,11 +444,24 @@ func generateRandomIngredients(variety string) []string {
 	return ingredients
 }
 
-func generateRandomInstructions() []string {
+var methodInstructions = map[models.CookingMethod]string{
+	models.Baked:   "Bake at 400°F (200°C) until tender",
+	models.Fried:   "Fry in hot oil until crisp",
+	models.Mashed:  "Boil until fork-tender, then mash",
+	models.Boiled:  "Boil in salted water until tender",
+	models.Roasted: "Roast at 425°F (220°C), turning once",
+}
+
+func generateRandomInstructions(method models.CookingMethod) []string {
+	step, ok := methodInstructions[method]
+	if !ok {
+		step = "Follow cooking method appropriate for the dish"
+	}
+
 	return []string{
 		"Prepare all ingredients",
 		"Wash and prepare potatoes",
-		"Follow cooking method appropriate for the dish",
+		step,
 		"Season to taste",
 		"Cook until golden and tender",
 		"Serve hot and enjoy",
@@ -0,0 +1,201 @@
+package degradation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+const (
+	AgePolicyName           = "age"
+	TemperaturePolicyName   = "temperature"
+	ProbabilisticPolicyName = "probabilistic"
+)
+
+type AgeThresholds struct {
+	StandardAfterDays int `json:"standard_after_days"`
+	EconomyAfterDays  int `json:"economy_after_days"`
+	DiscardAfterDays  int `json:"discard_after_days,omitempty"`
+}
+
+// AgeConfig sets thresholds in days since harvest, with overrides per
+// variety. Without a discard threshold, potatoes are discarded once the
+// freshness rules say their shelf life has run out.
+type AgeConfig struct {
+	Default   AgeThresholds            `json:"default"`
+	Varieties map[string]AgeThresholds `json:"varieties"`
+}
+
+type agePolicy struct {
+	cfg AgeConfig
+}
+
+func newAgePolicy(cfg AgeConfig) (*agePolicy, error) {
+	varieties := make(map[string]AgeThresholds, len(cfg.Varieties))
+	for variety, thresholds := range cfg.Varieties {
+		if thresholds.StandardAfterDays < 0 || thresholds.EconomyAfterDays < 0 || thresholds.DiscardAfterDays < 0 {
+			return nil, fmt.Errorf("age policy: negative threshold for %s", variety)
+		}
+		varieties[strings.ToLower(variety)] = thresholds
+	}
+	cfg.Varieties = varieties
+	if cfg.Default.StandardAfterDays < 0 || cfg.Default.EconomyAfterDays < 0 || cfg.Default.DiscardAfterDays < 0 {
+		return nil, errors.New("age policy: negative default threshold")
+	}
+	return &agePolicy{cfg: cfg}, nil
+}
+
+func (p *agePolicy) Name() string {
+	return AgePolicyName
+}
+
+func (p *agePolicy) Evaluate(subject Subject) models.DegradationDecision {
+	thresholds, ok := p.cfg.Varieties[strings.ToLower(subject.Potato.Variety)]
+	if !ok {
+		thresholds = p.cfg.Default
+	}
+	discardAfter := thresholds.DiscardAfterDays
+	if discardAfter == 0 {
+		discardAfter = subject.Freshness.ShelfLifeDays
+	}
+
+	age := subject.Freshness.AgeDays
+	decision := grade(subject, float64(age),
+		float64(thresholds.StandardAfterDays), float64(thresholds.EconomyAfterDays), float64(discardAfter))
+	switch decision.Action {
+	case models.DiscardPotato:
+		decision.Reason = fmt.Sprintf("%d days since harvest, past the %d-day limit", age, discardAfter)
+	case models.DowngradePotato:
+		decision.Reason = fmt.Sprintf("%d days since harvest", age)
+	}
+	return decision
+}
+
+// TemperatureConfig estimates heat exposure from the storage condition:
+// every day adds the distance between the storage temperature and the ideal
+// one, with a minimum of one degree-day so stock always ages a little.
+type TemperatureConfig struct {
+	IdealCelsius            float64            `json:"ideal_celsius"`
+	DefaultCelsius          float64            `json:"default_celsius"`
+	StorageCelsius          map[string]float64 `json:"storage_celsius"`
+	StandardAfterDegreeDays float64            `json:"standard_after_degree_days"`
+	EconomyAfterDegreeDays  float64            `json:"economy_after_degree_days"`
+	DiscardAfterDegreeDays  float64            `json:"discard_after_degree_days"`
+}
+
+type temperaturePolicy struct {
+	cfg TemperatureConfig
+}
+
+func newTemperaturePolicy(cfg TemperatureConfig) (*temperaturePolicy, error) {
+	if cfg.StandardAfterDegreeDays < 0 || cfg.EconomyAfterDegreeDays < 0 || cfg.DiscardAfterDegreeDays < 0 {
+		return nil, errors.New("temperature policy: negative threshold")
+	}
+	storage := make(map[string]float64, len(cfg.StorageCelsius))
+	for condition, celsius := range cfg.StorageCelsius {
+		storage[strings.ToLower(condition)] = celsius
+	}
+	cfg.StorageCelsius = storage
+	return &temperaturePolicy{cfg: cfg}, nil
+}
+
+func (p *temperaturePolicy) Name() string {
+	return TemperaturePolicyName
+}
+
+func (p *temperaturePolicy) Evaluate(subject Subject) models.DegradationDecision {
+	condition := subject.Freshness.StorageCondition
+	celsius, ok := p.cfg.StorageCelsius[condition]
+	if !ok {
+		celsius = p.cfg.DefaultCelsius
+	}
+	exposure := float64(subject.Freshness.AgeDays) * math.Max(math.Abs(celsius-p.cfg.IdealCelsius), 1)
+
+	decision := grade(subject, exposure,
+		p.cfg.StandardAfterDegreeDays, p.cfg.EconomyAfterDegreeDays, p.cfg.DiscardAfterDegreeDays)
+	if decision.Action != models.KeepPotato {
+		decision.Reason = fmt.Sprintf("%.0f degree-days of exposure in %s storage at %.0f°C", exposure, condition, celsius)
+	}
+	return decision
+}
+
+// ProbabilisticConfig gives every potato past MinAgeDays a chance of
+// degrading on each run that grows with its age: rate × age in days, capped
+// at MaxProbability. Economy potatoes are discarded instead of downgraded.
+type ProbabilisticConfig struct {
+	MinAgeDays     int     `json:"min_age_days"`
+	DowngradeRate  float64 `json:"downgrade_rate"`
+	DiscardRate    float64 `json:"discard_rate"`
+	MaxProbability float64 `json:"max_probability"`
+}
+
+type probabilisticPolicy struct {
+	cfg ProbabilisticConfig
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newProbabilisticPolicy(cfg ProbabilisticConfig) (*probabilisticPolicy, error) {
+	if cfg.DowngradeRate < 0 || cfg.DiscardRate < 0 || cfg.MaxProbability < 0 || cfg.MaxProbability > 1 {
+		return nil, errors.New("probabilistic policy: rates must be non-negative and max_probability at most 1")
+	}
+	return &probabilisticPolicy{
+		cfg: cfg,
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+func (p *probabilisticPolicy) Name() string {
+	return ProbabilisticPolicyName
+}
+
+func (p *probabilisticPolicy) Evaluate(subject Subject) models.DegradationDecision {
+	decision := models.DegradationDecision{
+		PotatoID: subject.Potato.ID,
+		Variety:  subject.Potato.Variety,
+		Quality:  subject.Potato.Quality,
+		Action:   models.KeepPotato,
+	}
+	age := subject.Freshness.AgeDays
+	if age < p.cfg.MinAgeDays {
+		return decision
+	}
+
+	next := ""
+	rate := p.cfg.DowngradeRate
+	switch subject.Potato.Quality {
+	case string(models.Premium):
+		next = string(models.Standard)
+	case string(models.Standard):
+		next = string(models.Economy)
+	case string(models.Economy):
+		rate = p.cfg.DiscardRate
+	default:
+		return decision
+	}
+
+	probability := math.Min(rate*float64(age), p.cfg.MaxProbability)
+	decision.Probability = math.Round(probability*10000) / 10000
+
+	p.mu.Lock()
+	draw := p.rnd.Float64()
+	p.mu.Unlock()
+	if draw >= probability {
+		return decision
+	}
+
+	if next == "" {
+		decision.Action = models.DiscardPotato
+	} else {
+		decision.Action = models.DowngradePotato
+		decision.NewQuality = next
+	}
+	decision.Reason = fmt.Sprintf("random wear at %d days since harvest (p=%.4f)", age, probability)
+	return decision
+}
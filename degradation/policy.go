@@ -0,0 +1,168 @@
+package degradation
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+//go:embed policy.json
+var defaultConfig []byte
+
+var ErrUnknownPolicy = errors.New("unknown degradation policy")
+
+// Subject is a potato together with what the rest of the service knows about
+// its condition, so policies need no access to storage.
+type Subject struct {
+	Potato    models.Potato
+	Freshness models.FreshnessReport
+	Now       time.Time
+}
+
+// Policy decides whether a potato should keep its quality, be downgraded or
+// be discarded. Implementations must be safe for concurrent use.
+type Policy interface {
+	Name() string
+	Evaluate(subject Subject) models.DegradationDecision
+}
+
+type Config struct {
+	Policy        string              `json:"policy"`
+	Age           AgeConfig           `json:"age"`
+	Temperature   TemperatureConfig   `json:"temperature"`
+	Probabilistic ProbabilisticConfig `json:"probabilistic"`
+}
+
+// Registry holds every built-in policy configured from one file, and which
+// of them the background worker applies.
+type Registry struct {
+	active   string
+	policies map[string]Policy
+}
+
+// Load reads the policy configuration from path, or the built-in defaults
+// when path is empty. A non-empty active overrides the configured policy.
+func Load(path, active string) (*Registry, error) {
+	data := defaultConfig
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("degradation config: %w", err)
+		}
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("degradation config: %w", err)
+	}
+	if active != "" {
+		cfg.Policy = active
+	}
+
+	age, err := newAgePolicy(cfg.Age)
+	if err != nil {
+		return nil, err
+	}
+	temperature, err := newTemperaturePolicy(cfg.Temperature)
+	if err != nil {
+		return nil, err
+	}
+	probabilistic, err := newProbabilisticPolicy(cfg.Probabilistic)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{
+		active: cfg.Policy,
+		policies: map[string]Policy{
+			age.Name():           age,
+			temperature.Name():   temperature,
+			probabilistic.Name(): probabilistic,
+		},
+	}
+	if _, ok := registry.policies[registry.active]; !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownPolicy, registry.active)
+	}
+	return registry, nil
+}
+
+func (r *Registry) Active() Policy {
+	return r.policies[r.active]
+}
+
+// Policy returns the named policy, or the active one when name is empty.
+func (r *Registry) Policy(name string) (Policy, error) {
+	if name == "" {
+		return r.Active(), nil
+	}
+	policy, ok := r.policies[name]
+	if !ok {
+		return nil, ErrUnknownPolicy
+	}
+	return policy, nil
+}
+
+// UsesVariety reports whether the age policy has thresholds for a variety.
+// The other policies treat every variety alike.
+func (r *Registry) UsesVariety(variety string) bool {
+	age, ok := r.policies[AgePolicyName].(*agePolicy)
+	if !ok {
+		return false
+	}
+	_, ok = age.cfg.Varieties[strings.ToLower(variety)]
+	return ok
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.policies))
+	for name := range r.policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var qualityRanks = map[string]int{
+	string(models.Economy):  1,
+	string(models.Standard): 2,
+	string(models.Premium):  3,
+}
+
+// grade turns a measure of wear (days, degree-days...) into a decision using
+// three thresholds. A zero threshold is never reached. Potatoes are only
+// ever moved down: an Economy potato is not "downgraded" to Standard, and
+// potatoes of unknown quality are left alone unless they are discarded.
+func grade(subject Subject, wear, standardAfter, economyAfter, discardAfter float64) models.DegradationDecision {
+	decision := models.DegradationDecision{
+		PotatoID: subject.Potato.ID,
+		Variety:  subject.Potato.Variety,
+		Quality:  subject.Potato.Quality,
+		Action:   models.KeepPotato,
+	}
+
+	if discardAfter > 0 && wear >= discardAfter {
+		decision.Action = models.DiscardPotato
+		return decision
+	}
+
+	target := ""
+	switch {
+	case economyAfter > 0 && wear >= economyAfter:
+		target = string(models.Economy)
+	case standardAfter > 0 && wear >= standardAfter:
+		target = string(models.Standard)
+	}
+	current, known := qualityRanks[subject.Potato.Quality]
+	if target != "" && known && qualityRanks[target] < current {
+		decision.Action = models.DowngradePotato
+		decision.NewQuality = target
+	}
+	return decision
+}
@@ -0,0 +1,100 @@
+package degradation
+
+import (
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func subject(variety, quality string, ageDays, shelfLifeDays int, storage string) Subject {
+	return Subject{
+		Potato:    models.Potato{ID: "p1", Variety: variety, Quality: quality},
+		Freshness: models.FreshnessReport{AgeDays: ageDays, ShelfLifeDays: shelfLifeDays, StorageCondition: storage},
+	}
+}
+
+func TestAgePolicyThresholds(t *testing.T) {
+	policy, err := newAgePolicy(AgeConfig{
+		Default: AgeThresholds{StandardAfterDays: 30, EconomyAfterDays: 60},
+		Varieties: map[string]AgeThresholds{
+			"Sweet Potato": {StandardAfterDays: 10, EconomyAfterDays: 20, DiscardAfterDays: 40},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		subject     Subject
+		wantAction  models.DegradationAction
+		wantQuality string
+	}{
+		{"fresh premium kept", subject("Russet", "Premium", 29, 90, ""), models.KeepPotato, ""},
+		{"standard threshold reached", subject("Russet", "Premium", 30, 90, ""), models.DowngradePotato, "Standard"},
+		{"economy threshold skips standard", subject("Russet", "Premium", 60, 90, ""), models.DowngradePotato, "Economy"},
+		{"past shelf life discarded", subject("Russet", "Standard", 90, 90, ""), models.DiscardPotato, ""},
+		{"variety override, case-insensitive", subject("sweet potato", "Premium", 10, 90, ""), models.DowngradePotato, "Standard"},
+		{"variety discard before shelf life", subject("Sweet Potato", "Economy", 40, 90, ""), models.DiscardPotato, ""},
+		{"never upgraded: economy stays economy", subject("Russet", "Economy", 30, 90, ""), models.KeepPotato, ""},
+		{"never upgraded: standard at economy age", subject("Russet", "Standard", 45, 90, ""), models.KeepPotato, ""},
+		{"unknown quality left alone", subject("Russet", "Heirloom", 60, 90, ""), models.KeepPotato, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Evaluate(tt.subject)
+			if decision.Action != tt.wantAction || decision.NewQuality != tt.wantQuality {
+				t.Errorf("got %s %q, want %s %q", decision.Action, decision.NewQuality, tt.wantAction, tt.wantQuality)
+			}
+		})
+	}
+}
+
+func TestTemperaturePolicyThresholds(t *testing.T) {
+	policy, err := newTemperaturePolicy(TemperatureConfig{
+		IdealCelsius:            8,
+		DefaultCelsius:          20,
+		StorageCelsius:          map[string]float64{"Pantry": 20, "cellar": 8},
+		StandardAfterDegreeDays: 150,
+		EconomyAfterDegreeDays:  300,
+		DiscardAfterDegreeDays:  600,
+	})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		subject     Subject
+		wantAction  models.DegradationAction
+		wantQuality string
+	}{
+		// 12 degrees off ideal: 12 days is 144 degree-days, 13 is 156.
+		{"pantry below threshold", subject("Russet", "Premium", 12, 0, "pantry"), models.KeepPotato, ""},
+		{"pantry past standard", subject("Russet", "Premium", 13, 0, "pantry"), models.DowngradePotato, "Standard"},
+		{"pantry past economy", subject("Russet", "Premium", 25, 0, "pantry"), models.DowngradePotato, "Economy"},
+		{"pantry past discard", subject("Russet", "Premium", 50, 0, "pantry"), models.DiscardPotato, ""},
+		// At the ideal temperature stock still ages a degree-day a day.
+		{"cellar ages slowly", subject("Russet", "Premium", 149, 0, "cellar"), models.KeepPotato, ""},
+		{"cellar eventually downgrades", subject("Russet", "Premium", 150, 0, "cellar"), models.DowngradePotato, "Standard"},
+		{"unknown storage uses default", subject("Russet", "Premium", 13, 0, "shed"), models.DowngradePotato, "Standard"},
+		{"never upgraded", subject("Russet", "Economy", 13, 0, "pantry"), models.KeepPotato, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Evaluate(tt.subject)
+			if decision.Action != tt.wantAction || decision.NewQuality != tt.wantQuality {
+				t.Errorf("got %s %q, want %s %q", decision.Action, decision.NewQuality, tt.wantAction, tt.wantQuality)
+			}
+		})
+	}
+}
+
+func TestNegativeThresholdsRejected(t *testing.T) {
+	if _, err := newAgePolicy(AgeConfig{Varieties: map[string]AgeThresholds{"Russet": {StandardAfterDays: -1}}}); err == nil {
+		t.Error("age policy accepted a negative variety threshold")
+	}
+	if _, err := newTemperaturePolicy(TemperatureConfig{DiscardAfterDegreeDays: -1}); err == nil {
+		t.Error("temperature policy accepted a negative threshold")
+	}
+}
@@ -0,0 +1,186 @@
+package forecast
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+//go:embed settings.json
+var defaultSettings []byte
+
+const (
+	MovingAverageMethod = "moving_average"
+	HoltWintersMethod   = "holt_winters"
+)
+
+// VarietySettings override the replenishment settings for one variety.
+type VarietySettings struct {
+	LeadTimeDays int `json:"lead_time_days,omitempty"`
+	ReviewDays   int `json:"review_days,omitempty"`
+}
+
+// Settings configure the forecasts and the reorder policy. Alpha, Beta and
+// Gamma smooth the level, trend and seasonal components of Holt-Winters.
+type Settings struct {
+	HistoryDays       int                        `json:"history_days"`
+	MovingAverageDays int                        `json:"moving_average_days"`
+	SeasonDays        int                        `json:"season_days"`
+	Alpha             float64                    `json:"alpha"`
+	Beta              float64                    `json:"beta"`
+	Gamma             float64                    `json:"gamma"`
+	LeadTimeDays      int                        `json:"lead_time_days"`
+	ReviewDays        int                        `json:"review_days"`
+	ServiceLevelZ     float64                    `json:"service_level_z"`
+	Varieties         map[string]VarietySettings `json:"varieties"`
+}
+
+// Load reads forecast settings from path, or the built-in settings when
+// path is empty.
+func Load(path string) (Settings, error) {
+	data := defaultSettings
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return Settings{}, fmt.Errorf("forecast settings: %w", err)
+		}
+	}
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Settings{}, fmt.Errorf("forecast settings: %w", err)
+	}
+	if err := settings.validate(); err != nil {
+		return Settings{}, fmt.Errorf("forecast settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s Settings) validate() error {
+	if s.HistoryDays < 1 || s.MovingAverageDays < 1 || s.SeasonDays < 1 {
+		return errors.New("history_days, moving_average_days and season_days must be positive")
+	}
+	for name, value := range map[string]float64{"alpha": s.Alpha, "beta": s.Beta, "gamma": s.Gamma} {
+		if value < 0 || value > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if s.LeadTimeDays < 0 || s.ReviewDays < 0 || s.ServiceLevelZ < 0 {
+		return errors.New("lead_time_days, review_days and service_level_z must not be negative")
+	}
+	for variety, override := range s.Varieties {
+		if override.LeadTimeDays < 0 || override.ReviewDays < 0 {
+			return fmt.Errorf("negative lead or review time for %s", variety)
+		}
+	}
+	return nil
+}
+
+// Replenishment returns the lead and review times for a variety.
+func (s Settings) Replenishment(variety string) (leadTimeDays, reviewDays int) {
+	leadTimeDays, reviewDays = s.LeadTimeDays, s.ReviewDays
+	for name, override := range s.Varieties {
+		if !strings.EqualFold(name, variety) {
+			continue
+		}
+		if override.LeadTimeDays > 0 {
+			leadTimeDays = override.LeadTimeDays
+		}
+		if override.ReviewDays > 0 {
+			reviewDays = override.ReviewDays
+		}
+	}
+	return leadTimeDays, reviewDays
+}
+
+// UsesVariety reports whether the settings override a variety.
+func (s Settings) UsesVariety(variety string) bool {
+	for name := range s.Varieties {
+		if strings.EqualFold(name, variety) {
+			return true
+		}
+	}
+	return false
+}
+
+// MovingAverage forecasts every future day as the mean of the last window
+// days of history.
+func MovingAverage(history []float64, window, horizon int) []float64 {
+	forecast := make([]float64, horizon)
+	if len(history) == 0 {
+		return forecast
+	}
+	window = min(window, len(history))
+	total := 0.0
+	for _, demand := range history[len(history)-window:] {
+		total += demand
+	}
+	for i := range forecast {
+		forecast[i] = total / float64(window)
+	}
+	return forecast
+}
+
+// HoltWinters forecasts with additive triple exponential smoothing over a
+// season of the given length, so weekly peaks carry into the forecast. It
+// needs two full seasons of history and reports false otherwise.
+// Forecasts are floored at zero.
+func HoltWinters(history []float64, season int, alpha, beta, gamma float64, horizon int) ([]float64, bool) {
+	if season < 2 || len(history) < 2*season {
+		return nil, false
+	}
+
+	// Initial level is the first season's mean, the trend the average
+	// change between the first two seasons, and each seasonal index the
+	// first season's deviation from its mean.
+	first, second := mean(history[:season]), mean(history[season:2*season])
+	level := first
+	trend := (second - first) / float64(season)
+	seasonal := make([]float64, season)
+	for i := range seasonal {
+		seasonal[i] = history[i] - first
+	}
+
+	for t := season; t < len(history); t++ {
+		demand := history[t]
+		s := seasonal[t%season]
+		previous := level
+		level = alpha*(demand-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+		seasonal[t%season] = gamma*(demand-level) + (1-gamma)*s
+	}
+
+	forecast := make([]float64, horizon)
+	for h := range forecast {
+		value := level + float64(h+1)*trend + seasonal[(len(history)+h)%season]
+		forecast[h] = math.Max(value, 0)
+	}
+	return forecast, true
+}
+
+// StdDev is the sample standard deviation of daily demand.
+func StdDev(history []float64) float64 {
+	if len(history) < 2 {
+		return 0
+	}
+	m := mean(history)
+	total := 0.0
+	for _, demand := range history {
+		total += (demand - m) * (demand - m)
+	}
+	return math.Sqrt(total / float64(len(history)-1))
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
@@ -0,0 +1,96 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+// week is a demand pattern with a weekend rush.
+var week = []float64{2, 2, 3, 2, 6, 8, 4}
+
+func repeat(pattern []float64, times int) []float64 {
+	var history []float64
+	for range times {
+		history = append(history, pattern...)
+	}
+	return history
+}
+
+func TestHoltWintersNeedsTwoSeasons(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []float64
+		season  int
+		wantOK  bool
+	}{
+		{"no history", nil, 7, false},
+		{"one season", week, 7, false},
+		{"a day short of two seasons", repeat(week, 2)[:13], 7, false},
+		{"two full seasons", repeat(week, 2), 7, true},
+		{"three seasons", repeat(week, 3), 7, true},
+		{"season too short", []float64{1, 2, 3, 4}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast, ok := HoltWinters(tt.history, tt.season, 0.3, 0.1, 0.2, 7)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && len(forecast) != 7 {
+				t.Errorf("got %d days, want 7", len(forecast))
+			}
+		})
+	}
+}
+
+func TestHoltWintersCarriesTheSeason(t *testing.T) {
+	forecast, ok := HoltWinters(repeat(week, 3), 7, 0.3, 0.1, 0.2, 14)
+	if !ok {
+		t.Fatal("no forecast from three seasons")
+	}
+	for i, value := range forecast {
+		if want := week[i%7]; math.Abs(value-want) > 1e-9 {
+			t.Errorf("day %d: got %v, want %v", i, value, want)
+		}
+	}
+}
+
+func TestHoltWintersFloorsAtZero(t *testing.T) {
+	// Every day of the second week sells six fewer than in the first.
+	history := []float64{10, 11, 10, 11, 10, 11, 10, 4, 5, 4, 5, 4, 5, 4}
+	forecast, ok := HoltWinters(history, 7, 0.3, 0.1, 0.2, 14)
+	if !ok {
+		t.Fatal("no forecast from two seasons")
+	}
+	for i, value := range forecast {
+		if value < 0 {
+			t.Errorf("day %d: got %v, want at least 0", i, value)
+		}
+	}
+	if forecast[len(forecast)-1] != 0 {
+		t.Errorf("falling demand ends at %v, want 0", forecast[len(forecast)-1])
+	}
+}
+
+func TestMovingAverageWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []float64
+		window  int
+		want    float64
+	}{
+		{"last days only", []float64{10, 10, 1, 2, 3}, 3, 2},
+		{"window longer than history", []float64{1, 3}, 7, 2},
+		{"no history", nil, 7, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := MovingAverage(tt.history, tt.window, 3)
+			for i, value := range forecast {
+				if value != tt.want {
+					t.Errorf("day %d: got %v, want %v", i, value, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package freshness
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+var ErrUnknownStorageCondition = errors.New("unknown storage condition")
+
+// Band is one freshness grade. A potato falls in the first band whose
+// MaxAgePct is at least the share of its shelf life already used.
+type Band struct {
+	Status    string  `json:"status"`
+	MaxAgePct float64 `json:"max_age_pct,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+type StorageCondition struct {
+	ShelfLifeFactor float64 `json:"shelf_life_factor"`
+}
+
+// Rule overrides the shelf life and/or bands for a variety, optionally only
+// under one storage condition. Fields left empty fall through to the less
+// specific level.
+type Rule struct {
+	Variety       string `json:"variety"`
+	Storage       string `json:"storage,omitempty"`
+	ShelfLifeDays int    `json:"shelf_life_days,omitempty"`
+	Bands         []Band `json:"bands,omitempty"`
+}
+
+type Rules struct {
+	DefaultStorage       string                      `json:"default_storage"`
+	DefaultShelfLifeDays int                         `json:"default_shelf_life_days"`
+	Bands                []Band                      `json:"bands"`
+	Expired              Band                        `json:"expired"`
+	StorageConditions    map[string]StorageCondition `json:"storage_conditions"`
+	Rules                []Rule                      `json:"rules"`
+}
+
+// Input describes the potato being assessed. CatalogShelfLifeDays is the
+// variety's typical shelf life from the catalog, used when no rule sets one.
+type Input struct {
+	Variety              string
+	CatalogShelfLifeDays int
+	Storage              string
+	HarvestDate          time.Time
+	Now                  time.Time
+}
+
+// Engine evaluates freshness against a rule set loaded from a JSON file, or
+// from the built-in defaults when no file is configured. The file is re-read
+// whenever its modification time changes; a file that fails to parse or
+// validate is ignored and the previous rules stay in force.
+type Engine struct {
+	path    string
+	mu      sync.RWMutex
+	rules   Rules
+	modTime time.Time
+}
+
+func NewEngine(path string) (*Engine, error) {
+	e := &Engine{path: path}
+	if path == "" {
+		rules, err := parseRules(defaultRules)
+		if err != nil {
+			return nil, fmt.Errorf("built-in freshness rules: %w", err)
+		}
+		e.rules = rules
+		return e, nil
+	}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Rules returns the rules currently in force. They are replaced, never
+// modified, on reload.
+func (e *Engine) Rules() Rules {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules
+}
+
+// Reload re-reads the rules file if it changed since the last load and
+// reports whether new rules were applied.
+func (e *Engine) Reload() (bool, error) {
+	if e.path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false, fmt.Errorf("freshness rules: %w", err)
+	}
+
+	e.mu.RLock()
+	unchanged := info.ModTime().Equal(e.modTime)
+	e.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return false, fmt.Errorf("freshness rules: %w", err)
+	}
+	rules, err := parseRules(data)
+	if err != nil {
+		return false, fmt.Errorf("freshness rules %s: %w", e.path, err)
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.modTime = info.ModTime()
+	e.mu.Unlock()
+	return true, nil
+}
+
+// Watch polls the rules file and reloads it when it changes. onReload is
+// called after every reload attempt that applied new rules or failed.
+func (e *Engine) Watch(interval time.Duration, onReload func(err error)) {
+	if e.path == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			reloaded, err := e.Reload()
+			if (reloaded || err != nil) && onReload != nil {
+				onReload(err)
+			}
+		}
+	}()
+}
+
+// StorageCondition returns the configured name for a storage condition,
+// matched case-insensitively. An empty name means the default condition.
+func (e *Engine) StorageCondition(name string) (string, error) {
+	return e.Rules().storageCondition(name)
+}
+
+// Evaluate grades a potato. Shelf life starts from the catalog (or the
+// default), is replaced by a variety rule, scaled by the storage factor and
+// finally replaced by a variety-and-storage rule. Bands follow the same
+// order without the scaling.
+func (e *Engine) Evaluate(in Input) (models.FreshnessReport, error) {
+	rules := e.Rules()
+	storage, err := rules.storageCondition(in.Storage)
+	if err != nil {
+		return models.FreshnessReport{}, err
+	}
+
+	shelfLife := float64(rules.DefaultShelfLifeDays)
+	applied := []string{"default"}
+	if in.CatalogShelfLifeDays > 0 {
+		shelfLife = float64(in.CatalogShelfLifeDays)
+		applied = []string{"catalog"}
+	}
+	bands := rules.Bands
+
+	if rule, ok := rules.find(in.Variety, ""); ok {
+		if rule.ShelfLifeDays > 0 {
+			shelfLife = float64(rule.ShelfLifeDays)
+		}
+		if len(rule.Bands) > 0 {
+			bands = rule.Bands
+		}
+		applied = append(applied, rule.Variety)
+	}
+	shelfLife *= rules.StorageConditions[storage].ShelfLifeFactor
+	if rule, ok := rules.find(in.Variety, storage); ok {
+		if rule.ShelfLifeDays > 0 {
+			shelfLife = float64(rule.ShelfLifeDays)
+		}
+		if len(rule.Bands) > 0 {
+			bands = rule.Bands
+		}
+		applied = append(applied, rule.Variety+"/"+rule.Storage)
+	}
+
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	shelfLifeDays := max(int(math.Round(shelfLife)), 1)
+	ageDays := int(now.Sub(in.HarvestDate).Hours() / 24)
+	agePct := float64(ageDays) / float64(shelfLifeDays) * 100
+
+	band := rules.Expired
+	for _, b := range bands {
+		if agePct <= b.MaxAgePct {
+			band = b
+			break
+		}
+	}
+
+	return models.FreshnessReport{
+		Freshness:              band.Status,
+		Score:                  band.Score,
+		StorageCondition:       storage,
+		AgeDays:                ageDays,
+		ShelfLifeDays:          shelfLifeDays,
+		RemainingShelfLifeDays: max(shelfLifeDays-ageDays, 0),
+		ExpiryDate:             in.HarvestDate.AddDate(0, 0, shelfLifeDays),
+		Rules:                  applied,
+	}, nil
+}
+
+// UsesVariety reports whether the current rules override a variety.
+func (e *Engine) UsesVariety(variety string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, rule := range e.rules.Rules {
+		if strings.EqualFold(rule.Variety, variety) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rules) storageCondition(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return r.DefaultStorage, nil
+	}
+	if _, ok := r.StorageConditions[name]; !ok {
+		return "", ErrUnknownStorageCondition
+	}
+	return name, nil
+}
+
+func (r Rules) find(variety, storage string) (Rule, bool) {
+	for _, rule := range r.Rules {
+		if strings.EqualFold(rule.Variety, variety) && rule.Storage == storage {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func parseRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, err
+	}
+
+	conditions := make(map[string]StorageCondition, len(rules.StorageConditions))
+	for name, condition := range rules.StorageConditions {
+		if condition.ShelfLifeFactor <= 0 {
+			return Rules{}, fmt.Errorf("storage condition %q needs a positive shelf_life_factor", name)
+		}
+		conditions[strings.ToLower(name)] = condition
+	}
+	rules.StorageConditions = conditions
+
+	rules.DefaultStorage = strings.ToLower(rules.DefaultStorage)
+	if _, ok := rules.StorageConditions[rules.DefaultStorage]; !ok {
+		return Rules{}, fmt.Errorf("default_storage %q is not a storage condition", rules.DefaultStorage)
+	}
+	if rules.DefaultShelfLifeDays <= 0 {
+		return Rules{}, errors.New("default_shelf_life_days must be positive")
+	}
+	if len(rules.Bands) == 0 {
+		return Rules{}, errors.New("default bands are required")
+	}
+	if err := validateBands(rules.Bands); err != nil {
+		return Rules{}, err
+	}
+	if rules.Expired.Status == "" || rules.Expired.Score < 0 || rules.Expired.Score > 1 {
+		return Rules{}, errors.New("expired needs a status and a score between 0 and 1")
+	}
+
+	seen := make(map[string]bool, len(rules.Rules))
+	for i, rule := range rules.Rules {
+		if rule.Variety == "" {
+			return Rules{}, fmt.Errorf("rule %d has no variety", i+1)
+		}
+		rule.Storage = strings.ToLower(rule.Storage)
+		if _, ok := rules.StorageConditions[rule.Storage]; rule.Storage != "" && !ok {
+			return Rules{}, fmt.Errorf("rule %d uses unknown storage condition %q", i+1, rule.Storage)
+		}
+		if rule.ShelfLifeDays < 0 {
+			return Rules{}, fmt.Errorf("rule %d has a negative shelf_life_days", i+1)
+		}
+		if err := validateBands(rule.Bands); err != nil {
+			return Rules{}, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		key := strings.ToLower(rule.Variety) + "/" + rule.Storage
+		if seen[key] {
+			return Rules{}, fmt.Errorf("rule %d duplicates an earlier rule for %s", i+1, key)
+		}
+		seen[key] = true
+		rules.Rules[i] = rule
+	}
+
+	return rules, nil
+}
+
+// validateBands requires ascending thresholds that cover the whole shelf
+// life, so only potatoes past their expiry date get the expired band.
+func validateBands(bands []Band) error {
+	if len(bands) == 0 {
+		return nil
+	}
+	previous := 0.0
+	for _, band := range bands {
+		if band.Status == "" {
+			return errors.New("every band needs a status")
+		}
+		if band.Score < 0 || band.Score > 1 {
+			return fmt.Errorf("band %q needs a score between 0 and 1", band.Status)
+		}
+		if band.MaxAgePct <= previous {
+			return fmt.Errorf("band %q must have a higher max_age_pct than the band before it", band.Status)
+		}
+		previous = band.MaxAgePct
+	}
+	if previous < 100 {
+		return errors.New("the last band must reach max_age_pct 100")
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/render"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const sharedCollectionPath = "/api/v1/shared/collections/"
+
+type collectionRecipeRequest struct {
+	RecipeID string `json:"recipe_id"`
+	Position int    `json:"position"`
+}
+
+type collectionOrderRequest struct {
+	RecipeIDs []string `json:"recipe_ids"`
+}
+
+func (h *RecipeHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	owner := mux.Vars(r)["user"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.CreateCollection")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.owner", owner))
+
+	var collection models.Collection
+	if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateCollection(owner, collection)
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Collection created successfully",
+			logapi.String("collection_id", created.ID),
+			logapi.Int("recipe_count", len(created.RecipeIDs)))
+	}
+
+	span.SetAttributes(attribute.String("collection.id", created.ID))
+	span.SetStatus(codes.Ok, "collection created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *RecipeHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	owner := mux.Vars(r)["user"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetCollections")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.owner", owner))
+
+	collections := h.service.GetCollections(owner)
+
+	span.SetAttributes(attribute.Int("collection.count", len(collections)))
+	span.SetStatus(codes.Ok, "collection list retrieved")
+	respondWithJSON(w, http.StatusOK, collections)
+}
+
+func (h *RecipeHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetCollection")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("collection.owner", vars["user"]),
+		attribute.String("collection.id", vars["id"]),
+	)
+
+	detail, err := h.service.GetCollection(vars["user"], vars["id"])
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "collection retrieved")
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+func (h *RecipeHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.UpdateCollection")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("collection.owner", vars["user"]),
+		attribute.String("collection.id", vars["id"]),
+	)
+
+	var collection models.Collection
+	if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.UpdateCollection(vars["user"], vars["id"], collection)
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "collection updated")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *RecipeHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.DeleteCollection")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("collection.owner", vars["user"]),
+		attribute.String("collection.id", vars["id"]),
+	)
+
+	if err := h.service.DeleteCollection(vars["user"], vars["id"]); err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Collection deleted successfully",
+			logapi.String("collection_id", vars["id"]))
+	}
+
+	span.SetStatus(codes.Ok, "collection deleted")
+	respondWithJSON(w, http.StatusOK, map[string]string{"result": "success"})
+}
+
+func (h *RecipeHandler) AddRecipeToCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.AddRecipeToCollection")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.id", vars["id"]))
+
+	var req collectionRecipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	span.SetAttributes(
+		attribute.String("recipe.id", req.RecipeID),
+		attribute.Int("collection.position", req.Position),
+	)
+
+	updated, err := h.service.AddRecipeToCollection(vars["user"], vars["id"], req.RecipeID, req.Position)
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "recipe added to collection")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *RecipeHandler) RemoveRecipeFromCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.RemoveRecipeFromCollection")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("collection.id", vars["id"]),
+		attribute.String("recipe.id", vars["recipeId"]),
+	)
+
+	updated, err := h.service.RemoveRecipeFromCollection(vars["user"], vars["id"], vars["recipeId"])
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "recipe removed from collection")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *RecipeHandler) ReorderCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.ReorderCollection")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.id", vars["id"]))
+
+	var req collectionOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.ReorderCollection(vars["user"], vars["id"], req.RecipeIDs)
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "collection reordered")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *RecipeHandler) ShareCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.ShareCollection")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.id", vars["id"]))
+
+	shared, err := h.service.ShareCollection(vars["user"], vars["id"])
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Collection shared",
+			logapi.String("collection_id", shared.ID))
+	}
+
+	span.SetStatus(codes.Ok, "collection shared")
+	respondWithJSON(w, http.StatusOK, models.CollectionShare{
+		CollectionID: shared.ID,
+		Token:        shared.ShareToken,
+		Path:         sharedCollectionPath + shared.ShareToken,
+	})
+}
+
+func (h *RecipeHandler) UnshareCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.UnshareCollection")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.id", vars["id"]))
+
+	if _, err := h.service.UnshareCollection(vars["user"], vars["id"]); err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Collection share link revoked",
+			logapi.String("collection_id", vars["id"]))
+	}
+
+	span.SetStatus(codes.Ok, "collection share link revoked")
+	respondWithJSON(w, http.StatusOK, map[string]string{"result": "success"})
+}
+
+func (h *RecipeHandler) ExportCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.ExportCollection")
+	defer span.End()
+	span.SetAttributes(attribute.String("collection.id", vars["id"]))
+
+	detail, err := h.service.GetCollection(vars["user"], vars["id"])
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+	h.respondWithCollectionDocument(w, r, span, detail)
+}
+
+func (h *RecipeHandler) GetSharedCollection(w http.ResponseWriter, r *http.Request) {
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetSharedCollection")
+	defer span.End()
+
+	detail, err := h.service.GetSharedCollection(mux.Vars(r)["token"])
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.String("collection.id", detail.ID))
+	span.SetStatus(codes.Ok, "shared collection retrieved")
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+func (h *RecipeHandler) ExportSharedCollection(w http.ResponseWriter, r *http.Request) {
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.ExportSharedCollection")
+	defer span.End()
+
+	detail, err := h.service.GetSharedCollection(mux.Vars(r)["token"])
+	if err != nil {
+		respondWithCollectionError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.String("collection.id", detail.ID))
+	h.respondWithCollectionDocument(w, r, span, detail)
+}
+
+// respondWithCollectionDocument renders a collection as one cookbook-style
+// document, using the same templates as the variety cookbook.
+func (h *RecipeHandler) respondWithCollectionDocument(w http.ResponseWriter, r *http.Request, span trace.Span, detail models.CollectionDetail) {
+	format, err := negotiateFormat(w, r, formatMarkdown, formatHTML)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.String("collection.format", format))
+
+	book := render.Book{Title: detail.Name, Description: detail.Description, Recipes: detail.Recipes}
+	var doc bytes.Buffer
+	if err := h.cards.Cookbook(&doc, render.Format(format), book); err != nil {
+		recordSpanError(span, err, "render_error", "server_error", "failed to render collection")
+		respondWithError(w, http.StatusInternalServerError, "Failed to render collection")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "collection exported")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, render.Slug(detail.Name), render.Format(format).Extension()))
+	respondWithText(w, http.StatusOK, render.Format(format).ContentType(), doc.Bytes())
+}
+
+func respondWithCollectionError(w http.ResponseWriter, span trace.Span, err error) {
+	status := http.StatusBadRequest
+	msg := err.Error()
+	errType := "validation_error"
+	if errors.Is(err, storage.ErrCollectionNotFound) {
+		status = http.StatusNotFound
+		msg = "Collection not found"
+		errType = "not_found"
+	} else if errors.Is(err, service.ErrRecipeNotInCollection) {
+		status = http.StatusNotFound
+		errType = "not_found"
+	} else if errors.Is(err, service.ErrDuplicateCollectionName) || errors.Is(err, service.ErrDuplicateRecipe) {
+		status = http.StatusConflict
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
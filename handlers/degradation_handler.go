@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var degradationTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/degradation")
+
+type DegradationHandler struct {
+	service *service.DegradationService
+	obs     ObservabilityLogger
+}
+
+func NewDegradationHandler(service *service.DegradationService, obs ObservabilityLogger) *DegradationHandler {
+	return &DegradationHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *DegradationHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	_, span := degradationTracer.Start(r.Context(), "DegradationHandler.GetPolicies")
+	defer span.End()
+
+	active := h.service.ActivePolicy()
+
+	span.SetAttributes(attribute.String("degradation.policy", active))
+	span.SetStatus(codes.Ok, "degradation policies retrieved")
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"active":   active,
+		"policies": h.service.PolicyNames(),
+	})
+}
+
+func (h *DegradationHandler) PreviewDegradation(w http.ResponseWriter, r *http.Request) {
+	policy := r.URL.Query().Get("policy")
+
+	_, span := degradationTracer.Start(r.Context(), "DegradationHandler.PreviewDegradation")
+	defer span.End()
+
+	preview, err := h.service.Preview(policy)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("degradation.policy", preview.Policy),
+		attribute.Int("degradation.evaluated", preview.Evaluated),
+		attribute.Int("degradation.decision_count", len(preview.Decisions)),
+	)
+	span.SetStatus(codes.Ok, "degradation previewed")
+	respondWithJSON(w, http.StatusOK, preview)
+}
+
+func (h *DegradationHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	_, span := degradationTracer.Start(r.Context(), "DegradationHandler.GetAuditLog")
+	defer span.End()
+
+	filter := models.AuditFilter{
+		PotatoID: query.Get("potato_id"),
+		Action:   models.DegradationAction(query.Get("action")),
+	}
+	switch filter.Action {
+	case "", models.DowngradePotato, models.DiscardPotato:
+	default:
+		recordSpanError(span, nil, "validation_error", "client_error", "invalid action")
+		respondWithError(w, http.StatusBadRequest, "action must be downgrade or discard")
+		return
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid limit")
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries := h.service.GetAuditLog(filter)
+
+	span.SetAttributes(attribute.Int("audit.count", len(entries)))
+	span.SetStatus(codes.Ok, "audit log retrieved")
+	respondWithJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var forecastTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/forecast")
+
+type ForecastHandler struct {
+	service *service.ForecastService
+	obs     ObservabilityLogger
+}
+
+func NewForecastHandler(service *service.ForecastService, obs ObservabilityLogger) *ForecastHandler {
+	return &ForecastHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *ForecastHandler) GetForecasts(w http.ResponseWriter, r *http.Request) {
+	_, span := forecastTracer.Start(r.Context(), "ForecastHandler.GetForecasts")
+	defer span.End()
+
+	horizon, ok := parseHorizon(w, r, span)
+	if !ok {
+		return
+	}
+	forecasts, err := h.service.Forecasts(horizon)
+	if err != nil {
+		respondWithForecastError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("forecast.variety_count", len(forecasts)))
+	span.SetStatus(codes.Ok, "forecasts retrieved")
+	respondWithJSON(w, http.StatusOK, forecasts)
+}
+
+func (h *ForecastHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	variety := mux.Vars(r)["variety"]
+
+	_, span := forecastTracer.Start(r.Context(), "ForecastHandler.GetForecast")
+	defer span.End()
+	span.SetAttributes(attribute.String("potato.variety", variety))
+
+	horizon, ok := parseHorizon(w, r, span)
+	if !ok {
+		return
+	}
+	forecast, err := h.service.Forecast(variety, horizon)
+	if err != nil {
+		respondWithForecastError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("forecast.method", forecast.Method),
+		attribute.Float64("forecast.reorder_point", forecast.ReorderPoint),
+	)
+	span.SetStatus(codes.Ok, "forecast retrieved")
+	respondWithJSON(w, http.StatusOK, forecast)
+}
+
+func (h *ForecastHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	_, span := forecastTracer.Start(r.Context(), "ForecastHandler.GetAlerts")
+	defer span.End()
+
+	alerts := h.service.Alerts()
+
+	span.SetAttributes(attribute.Int("alert.count", len(alerts)))
+	span.SetStatus(codes.Ok, "alerts retrieved")
+	respondWithJSON(w, http.StatusOK, alerts)
+}
+
+func parseHorizon(w http.ResponseWriter, r *http.Request, span trace.Span) (int, bool) {
+	raw := r.URL.Query().Get("horizon")
+	if raw == "" {
+		return 0, true
+	}
+	horizon, err := strconv.Atoi(raw)
+	if err != nil || horizon <= 0 {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid horizon")
+		respondWithError(w, http.StatusBadRequest, service.ErrInvalidHorizon.Error())
+		return 0, false
+	}
+	return horizon, true
+}
+
+func respondWithForecastError(w http.ResponseWriter, span trace.Span, err error) {
+	status := http.StatusBadRequest
+	msg := err.Error()
+	errType := "validation_error"
+	if errors.Is(err, service.ErrUnknownVariety) {
+		status = http.StatusNotFound
+		msg = "Variety not found"
+		errType = "not_found"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/timeseries"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var inventoryHistoryTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/inventory_history")
+
+type InventoryHistoryHandler struct {
+	service *service.InventoryHistoryService
+	obs     ObservabilityLogger
+}
+
+func NewInventoryHistoryHandler(service *service.InventoryHistoryService, obs ObservabilityLogger) *InventoryHistoryHandler {
+	return &InventoryHistoryHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *InventoryHistoryHandler) GetInventoryTrend(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	_, span := inventoryHistoryTracer.Start(r.Context(), "InventoryHistoryHandler.GetInventoryTrend")
+	defer span.End()
+
+	query := models.InventoryTrendQuery{
+		Variety:    params.Get("variety"),
+		Quality:    params.Get("quality"),
+		Resolution: params.Get("resolution"),
+	}
+	for name, date := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseTrendTime(raw)
+		if err != nil {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid "+name)
+			respondWithError(w, http.StatusBadRequest, name+" must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+		*date = parsed
+	}
+	if raw := params.Get("window"); raw != "" {
+		window, err := parseWindow(raw)
+		if err != nil || !query.From.IsZero() {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid window")
+			respondWithError(w, http.StatusBadRequest, "window must be a positive duration such as 90m, 6h or 30d, and cannot be combined with from")
+			return
+		}
+		if query.To.IsZero() {
+			query.To = time.Now().UTC()
+		}
+		query.From = query.To.Add(-window)
+	}
+
+	trend, err := h.service.Trend(query)
+	if err != nil {
+		msg := err.Error()
+		if errors.Is(err, timeseries.ErrUnknownResolution) {
+			var names []string
+			for _, tier := range h.service.Tiers() {
+				names = append(names, tier.Resolution)
+			}
+			msg = "resolution must be one of " + strings.Join(names, ", ")
+		}
+		recordSpanError(span, err, "validation_error", "client_error", msg)
+		respondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("inventory_history.resolution", trend.Resolution),
+		attribute.Int("inventory_history.point_count", len(trend.Points)),
+	)
+	if trend.Variety != "" {
+		span.SetAttributes(attribute.String("potato.variety", trend.Variety))
+	}
+	span.SetStatus(codes.Ok, "inventory trend retrieved")
+	respondWithJSON(w, http.StatusOK, trend)
+}
+
+func parseTrendTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+// parseWindow accepts Go durations plus a "d" suffix for whole days.
+func parseWindow(raw string) (time.Duration, error) {
+	var window time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if window, err = time.ParseDuration(raw); err != nil {
+			return 0, err
+		}
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("window must be positive")
+	}
+	return window, nil
+}
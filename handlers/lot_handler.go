@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var lotTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/lot")
+
+type LotHandler struct {
+	service *service.LotService
+	obs     ObservabilityLogger
+}
+
+func NewLotHandler(service *service.LotService, obs ObservabilityLogger) *LotHandler {
+	return &LotHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *LotHandler) GetAllLots(w http.ResponseWriter, r *http.Request) {
+	_, span := lotTracer.Start(r.Context(), "LotHandler.GetAllLots")
+	defer span.End()
+
+	lots := h.service.GetAllLots()
+
+	span.SetAttributes(attribute.Int("lot.count", len(lots)))
+	span.SetStatus(codes.Ok, "lots retrieved")
+	respondWithJSON(w, http.StatusOK, lots)
+}
+
+func (h *LotHandler) CreateLot(w http.ResponseWriter, r *http.Request) {
+	_, span := lotTracer.Start(r.Context(), "LotHandler.CreateLot")
+	defer span.End()
+
+	var lot models.HarvestLot
+	if err := json.NewDecoder(r.Body).Decode(&lot); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateLot(lot)
+	if err != nil {
+		respondWithLotError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Harvest lot created",
+			logapi.String("lot_id", created.ID),
+			logapi.String("farm", created.Farm))
+	}
+
+	span.SetAttributes(attribute.String("lot.id", created.ID))
+	span.SetStatus(codes.Ok, "lot created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *LotHandler) GetLot(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := lotTracer.Start(r.Context(), "LotHandler.GetLot")
+	defer span.End()
+	span.SetAttributes(attribute.String("lot.id", id))
+
+	lot, err := h.service.GetLot(id)
+	if err != nil {
+		respondWithLotError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "lot retrieved")
+	respondWithJSON(w, http.StatusOK, lot)
+}
+
+func (h *LotHandler) TraceLot(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := lotTracer.Start(r.Context(), "LotHandler.TraceLot")
+	defer span.End()
+	span.SetAttributes(attribute.String("lot.id", id))
+
+	lotTrace, err := h.service.Trace(id)
+	if err != nil {
+		respondWithLotError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("lot.received", lotTrace.Received),
+		attribute.Int("lot.in_stock", len(lotTrace.InStock)),
+		attribute.Int("lot.cook_events", len(lotTrace.CookEvents)),
+	)
+	span.SetStatus(codes.Ok, "lot traced")
+	respondWithJSON(w, http.StatusOK, lotTrace)
+}
+
+func (h *LotHandler) RecallLot(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := lotTracer.Start(r.Context(), "LotHandler.RecallLot")
+	defer span.End()
+	span.SetAttributes(attribute.String("lot.id", id))
+
+	var request models.RecallRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := h.service.Recall(id, request.Reason)
+	if err != nil {
+		respondWithLotError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Harvest lot recalled",
+			logapi.String("lot_id", id),
+			logapi.String("reason", result.Lot.RecallReason),
+			logapi.Int("quarantined", len(result.Quarantined)))
+	}
+
+	span.SetAttributes(attribute.Int("lot.quarantined", len(result.Quarantined)))
+	span.SetStatus(codes.Ok, "lot recalled")
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func respondWithLotError(w http.ResponseWriter, span trace.Span, err error) {
+	status := http.StatusBadRequest
+	msg := err.Error()
+	errType := "validation_error"
+	switch {
+	case errors.Is(err, storage.ErrLotNotFound):
+		status = http.StatusNotFound
+		msg = "Lot not found"
+		errType = "not_found"
+	case errors.Is(err, service.ErrDuplicateLot):
+		status = http.StatusConflict
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var mealPlanTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/mealplan")
+
+type MealPlanHandler struct {
+	service *service.MealPlanService
+	obs     ObservabilityLogger
+}
+
+func NewMealPlanHandler(service *service.MealPlanService, obs ObservabilityLogger) *MealPlanHandler {
+	return &MealPlanHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *MealPlanHandler) CreateMealPlan(w http.ResponseWriter, r *http.Request) {
+	_, span := mealPlanTracer.Start(r.Context(), "MealPlanHandler.CreateMealPlan")
+	defer span.End()
+
+	var plan models.MealPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateMealPlan(plan)
+	if err != nil {
+		respondWithMealPlanError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Meal plan created successfully",
+			logapi.String("meal_plan_id", created.ID),
+			logapi.Int("meal_count", len(created.Meals)))
+	}
+
+	span.SetAttributes(attribute.String("meal_plan.id", created.ID))
+	span.SetStatus(codes.Ok, "meal plan created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *MealPlanHandler) GetMealPlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := mealPlanTracer.Start(r.Context(), "MealPlanHandler.GetMealPlan")
+	defer span.End()
+	span.SetAttributes(attribute.String("meal_plan.id", id))
+
+	plan, err := h.service.GetMealPlan(id)
+	if err != nil {
+		respondWithMealPlanError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "meal plan retrieved")
+	respondWithJSON(w, http.StatusOK, plan)
+}
+
+func (h *MealPlanHandler) GetAllMealPlans(w http.ResponseWriter, r *http.Request) {
+	_, span := mealPlanTracer.Start(r.Context(), "MealPlanHandler.GetAllMealPlans")
+	defer span.End()
+
+	plans := h.service.GetAllMealPlans()
+
+	span.SetAttributes(attribute.Int("meal_plan.count", len(plans)))
+	span.SetStatus(codes.Ok, "meal plan list retrieved")
+	respondWithJSON(w, http.StatusOK, plans)
+}
+
+func (h *MealPlanHandler) UpdateMealPlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := mealPlanTracer.Start(r.Context(), "MealPlanHandler.UpdateMealPlan")
+	defer span.End()
+	span.SetAttributes(attribute.String("meal_plan.id", id))
+
+	var plan models.MealPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.UpdateMealPlan(id, plan)
+	if err != nil {
+		respondWithMealPlanError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Meal plan updated successfully",
+			logapi.String("meal_plan_id", id))
+	}
+
+	span.SetStatus(codes.Ok, "meal plan updated")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *MealPlanHandler) DeleteMealPlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := mealPlanTracer.Start(r.Context(), "MealPlanHandler.DeleteMealPlan")
+	defer span.End()
+	span.SetAttributes(attribute.String("meal_plan.id", id))
+
+	if err := h.service.DeleteMealPlan(id); err != nil {
+		respondWithMealPlanError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Meal plan deleted successfully",
+			logapi.String("meal_plan_id", id))
+	}
+
+	span.SetStatus(codes.Ok, "meal plan deleted")
+	respondWithJSON(w, http.StatusOK, map[string]string{"result": "success"})
+}
+
+func (h *MealPlanHandler) GetShoppingList(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	units := models.UnitSystem(strings.ToLower(r.URL.Query().Get("units")))
+
+	_, span := mealPlanTracer.Start(r.Context(), "MealPlanHandler.GetShoppingList")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("meal_plan.id", id),
+		attribute.String("export.format", format),
+	)
+
+	list, err := h.service.GenerateShoppingList(id, units)
+	if err != nil {
+		respondWithMealPlanError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitDebugLog(r.Context(), "Shopping list generated",
+			logapi.String("meal_plan_id", id),
+			logapi.Int("item_count", len(list.Items)),
+			logapi.String("format", format))
+	}
+
+	switch format {
+	case "", "json":
+		span.SetStatus(codes.Ok, "shopping list generated")
+		respondWithJSON(w, http.StatusOK, list)
+	case "csv":
+		body, err := shoppingListCSV(list)
+		if err != nil {
+			recordSpanError(span, err, "export_error", "server_error", "failed to render csv")
+			respondWithError(w, http.StatusInternalServerError, "failed to render csv")
+			return
+		}
+		span.SetStatus(codes.Ok, "shopping list generated")
+		respondWithText(w, http.StatusOK, "text/csv; charset=utf-8", body)
+	case "markdown", "md":
+		span.SetStatus(codes.Ok, "shopping list generated")
+		respondWithText(w, http.StatusOK, "text/markdown; charset=utf-8", shoppingListMarkdown(list))
+	default:
+		recordSpanError(span, nil, "validation_error", "client_error", "unsupported format")
+		respondWithError(w, http.StatusBadRequest, "format must be json, csv or markdown")
+	}
+}
+
+func respondWithMealPlanError(w http.ResponseWriter, span trace.Span, err error) {
+	status := http.StatusBadRequest
+	msg := err.Error()
+	errType := "validation_error"
+	switch {
+	case errors.Is(err, storage.ErrMealPlanNotFound):
+		status = http.StatusNotFound
+		msg = "Meal plan not found"
+		errType = "not_found"
+	case errors.Is(err, service.ErrDuplicateMealPlan):
+		status = http.StatusConflict
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
+
+func shoppingListCSV(list models.ShoppingList) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"section", "item", "quantity", "unit", "notes"})
+
+	for _, potato := range list.Potatoes {
+		writer.Write([]string{"potatoes", potato.Variety, formatFloat(potato.ToBuy), potato.Unit,
+			fmt.Sprintf("required %s, in stock %s", formatFloat(potato.Required), formatFloat(potato.InStock))})
+	}
+	for _, item := range list.Items {
+		writer.Write([]string{"ingredients", item.Name, formatFloat(item.Quantity), item.Unit, strings.Join(item.Recipes, "; ")})
+	}
+	for _, item := range list.ToTaste {
+		writer.Write([]string{"to_taste", item.Name, "", "", strings.Join(item.Recipes, "; ")})
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func shoppingListMarkdown(list models.ShoppingList) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Shopping list for %s\n", list.MealPlanID)
+
+	if len(list.Potatoes) > 0 {
+		b.WriteString("\n## Potatoes\n\n| Variety | Required | In stock | To buy |\n|---|---|---|---|\n")
+		for _, potato := range list.Potatoes {
+			fmt.Fprintf(&b, "| %s | %s %s | %s %s | %s %s |\n", potato.Variety,
+				formatFloat(potato.Required), potato.Unit,
+				formatFloat(potato.InStock), potato.Unit,
+				formatFloat(potato.ToBuy), potato.Unit)
+		}
+	}
+	if len(list.Items) > 0 {
+		b.WriteString("\n## Ingredients\n\n")
+		for _, item := range list.Items {
+			fmt.Fprintf(&b, "- [ ] %s _(%s)_\n", item.Text, strings.Join(item.Recipes, ", "))
+		}
+	}
+	if len(list.ToTaste) > 0 {
+		b.WriteString("\n## To taste\n\n")
+		for _, item := range list.ToTaste {
+			fmt.Fprintf(&b, "- [ ] %s\n", item.Text)
+		}
+	}
+
+	return []byte(b.String())
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
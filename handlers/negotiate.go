@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatJSON     = "json"
+	formatJSONLD   = "jsonld"
+	formatMarkdown = "markdown"
+	formatHTML     = "html"
+)
+
+var formatNames = map[string]string{
+	"json":     formatJSON,
+	"jsonld":   formatJSONLD,
+	"markdown": formatMarkdown,
+	"md":       formatMarkdown,
+	"html":     formatHTML,
+}
+
+var formatMediaTypes = map[string]string{
+	"application/json":    formatJSON,
+	"application/ld+json": formatJSONLD,
+	"text/markdown":       formatMarkdown,
+	"text/x-markdown":     formatMarkdown,
+	"text/html":           formatHTML,
+}
+
+// negotiateFormat picks a response format from the ?format= parameter or,
+// failing that, the Accept header. offered lists the formats the endpoint
+// supports, default first. An explicit but unsupported ?format= is an error;
+// an Accept header with nothing we offer falls back to the default, as most
+// clients send one without caring. The response varies with Accept, so it
+// says so for caches whichever way the format was chosen.
+func negotiateFormat(w http.ResponseWriter, r *http.Request, offered ...string) (string, error) {
+	w.Header().Add("Vary", "Accept")
+
+	if raw := r.URL.Query().Get("format"); raw != "" {
+		format, ok := formatNames[strings.ToLower(raw)]
+		if !ok || !containsFormat(offered, format) {
+			return "", fmt.Errorf("format must be one of %s", strings.Join(offered, ", "))
+		}
+		return format, nil
+	}
+
+	best, bestQ := offered[0], 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if format, ok := matchMediaType(mediaType, offered); ok {
+			best, bestQ = format, q
+		}
+	}
+	return best, nil
+}
+
+func matchMediaType(mediaType string, offered []string) (string, bool) {
+	if format, ok := formatMediaTypes[mediaType]; ok {
+		return format, containsFormat(offered, format)
+	}
+	if mediaType == "*/*" {
+		return offered[0], true
+	}
+	if prefix, found := strings.CutSuffix(mediaType, "/*"); found {
+		for _, format := range offered {
+			for candidate, f := range formatMediaTypes {
+				if f == format && strings.HasPrefix(candidate, prefix+"/") {
+					return format, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func containsFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
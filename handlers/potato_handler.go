@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/williamdumont/potato-demo/models"
@@ -59,7 +58,7 @@ func (h *PotatoHandler) CreatePotato(w http.ResponseWriter, r *http.Request) {
 	createdPotato, err := h.service.CreatePotato(potato)
 	if err != nil {
 		recordSpanError(span, err, "validation_error", "client_error", err.Error())
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		respondWithError(w, placementStatus(err), err.Error())
 		return
 	}
 
@@ -156,7 +155,7 @@ func (h *PotatoHandler) UpdatePotato(w http.ResponseWriter, r *http.Request) {
 	potato.ID = id
 	updatedPotato, err := h.service.UpdatePotato(id, potato)
 	if err != nil {
-		status := http.StatusBadRequest
+		status := placementStatus(err)
 		msg := err.Error()
 		errType := "validation_error"
 		errCategory := "client_error"
@@ -192,7 +191,7 @@ func (h *PotatoHandler) DeletePotato(w http.ResponseWriter, r *http.Request) {
 			logapi.String("potato_id", id))
 	}
 
-	if err := h.service.DeletePotato(id); err != nil {
+	if err := h.service.DeletePotato(id, r.URL.Query().Get("reason")); err != nil {
 		status := http.StatusInternalServerError
 		msg := err.Error()
 		errType := "storage_error"
@@ -202,6 +201,14 @@ func (h *PotatoHandler) DeletePotato(w http.ResponseWriter, r *http.Request) {
 			msg = "Potato not found"
 			errType = "not_found"
 			errCategory = "client_error"
+		} else if err == service.ErrInvalidRemovalReason {
+			status = http.StatusBadRequest
+			errType = "validation_error"
+			errCategory = "client_error"
+		} else if err == service.ErrPotatoQuarantined {
+			status = http.StatusConflict
+			errType = "conflict"
+			errCategory = "client_error"
 		}
 		recordSpanError(span, err, errType, errCategory, msg)
 		respondWithError(w, status, msg)
@@ -225,12 +232,18 @@ func (h *PotatoHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
 		h.obs.EmitDebugLog(r.Context(), "Processing inventory request")
 	}
 
-	summary := h.service.GetInventorySummary()
+	summary, err := h.service.GetInventorySummary(r.URL.Query().Get("warehouse"))
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	span.SetAttributes(
 		attribute.Int("inventory.total_potatoes", summary.TotalPotatoes),
 		attribute.Int("inventory.variety_count", len(summary.ByVariety)),
 	)
-	if h.telemetry != nil {
+	// Only whole-inventory counts feed the inventory gauge.
+	if h.telemetry != nil && summary.Warehouse == "" {
 		for _, item := range summary.ByVariety {
 			h.telemetry.RecordInventory(r.Context(), item.Variety, item.TotalQuantity)
 		}
@@ -239,6 +252,23 @@ func (h *PotatoHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, summary)
 }
 
+func (h *PotatoHandler) CheckInventoryConsistency(w http.ResponseWriter, r *http.Request) {
+	_, span := potatoTracer.Start(r.Context(), "PotatoHandler.CheckInventoryConsistency")
+	defer span.End()
+
+	report := h.service.CheckInventoryConsistency()
+	span.SetAttributes(
+		attribute.Bool("inventory.consistent", report.Consistent),
+		attribute.Int("inventory.drift_count", len(report.Drift)),
+	)
+	if !report.Consistent && h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Inventory aggregates drifted from a recount",
+			logapi.Int("drift_count", len(report.Drift)))
+	}
+	span.SetStatus(codes.Ok, "inventory consistency checked")
+	respondWithJSON(w, http.StatusOK, report)
+}
+
 func (h *PotatoHandler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 	_, span := potatoTracer.Start(r.Context(), "PotatoHandler.GetAnalytics")
 	defer span.End()
@@ -247,7 +277,18 @@ func (h *PotatoHandler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 		h.obs.EmitDebugLog(r.Context(), "Calculating analytics")
 	}
 
-	analytics := h.service.GetAnalytics()
+	query := models.AnalyticsQuery{
+		GroupBy: splitQueryList(r.URL.Query().Get("group_by")),
+		Metrics: splitQueryList(r.URL.Query().Get("metrics")),
+	}
+	analytics, err := h.service.GetAnalytics(query)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(attribute.Int("analytics.group_count", len(analytics.Groups)))
 	if analytics.MostPopularVariety != "" {
 		span.SetAttributes(attribute.String("analytics.most_popular", analytics.MostPopularVariety))
 	}
@@ -284,30 +325,32 @@ func (h *PotatoHandler) CheckFreshness(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	freshness := h.service.CalculateFreshness(potato)
-	span.SetAttributes(attribute.String("potato.freshness", freshness))
+	report, err := h.service.CalculateFreshness(potato, r.URL.Query().Get("storage"))
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("potato.freshness", report.Freshness),
+		attribute.String("potato.storage_condition", report.StorageCondition),
+		attribute.Int("potato.remaining_shelf_life_days", report.RemainingShelfLifeDays),
+	)
 	if h.telemetry != nil {
-		h.telemetry.RecordFreshness(r.Context(), potato.Variety, freshnessScoreForStatus(freshness))
+		h.telemetry.RecordFreshness(r.Context(), potato.Variety, report.Score)
 	}
 	span.SetStatus(codes.Ok, "freshness calculated")
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"id":        potato.ID,
-		"variety":   potato.Variety,
-		"freshness": freshness,
-	})
+	respondWithJSON(w, http.StatusOK, report)
 }
 
-func freshnessScoreForStatus(status string) float64 {
-	switch strings.ToLower(status) {
-	case "fresh":
-		return 1.0
-	case "good":
-		return 0.75
-	case "fair":
-		return 0.5
-	case "old":
-		return 0.25
-	default:
-		return 0.0
-	}
+func (h *PotatoHandler) GetFreshnessRules(w http.ResponseWriter, r *http.Request) {
+	_, span := potatoTracer.Start(r.Context(), "PotatoHandler.GetFreshnessRules")
+	defer span.End()
+
+	rules := h.service.FreshnessRules()
+
+	span.SetAttributes(attribute.Int("freshness.rule_count", len(rules.Rules)))
+	span.SetStatus(codes.Ok, "freshness rules retrieved")
+	respondWithJSON(w, http.StatusOK, rules)
 }
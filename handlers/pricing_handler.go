@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var pricingTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/pricing")
+
+type PricingHandler struct {
+	service *service.PricingService
+	obs     ObservabilityLogger
+}
+
+func NewPricingHandler(service *service.PricingService, obs ObservabilityLogger) *PricingHandler {
+	return &PricingHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *PricingHandler) GetPriceQuote(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := pricingTracer.Start(r.Context(), "PricingHandler.GetPriceQuote")
+	defer span.End()
+	span.SetAttributes(attribute.String("potato.id", id))
+
+	quote, err := h.service.QuotePrice(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			recordSpanError(span, err, "not_found", "client_error", "Potato not found")
+			respondWithError(w, http.StatusNotFound, "Potato not found")
+			return
+		}
+		recordSpanError(span, err, "storage_error", "server_error", err.Error())
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Float64("potato.price_quote", quote.Price),
+		attribute.Float64("potato.current_price", quote.CurrentPrice),
+	)
+	if quote.Promotion != "" {
+		span.SetAttributes(attribute.String("potato.promotion", quote.Promotion))
+	}
+	span.SetStatus(codes.Ok, "price quoted")
+	respondWithJSON(w, http.StatusOK, quote)
+}
+
+func (h *PricingHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := pricingTracer.Start(r.Context(), "PricingHandler.GetPriceHistory")
+	defer span.End()
+	span.SetAttributes(attribute.String("potato.id", id))
+
+	history, err := h.service.PriceHistory(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			recordSpanError(span, err, "not_found", "client_error", "Potato not found")
+			respondWithError(w, http.StatusNotFound, "Potato not found")
+			return
+		}
+		recordSpanError(span, err, "storage_error", "server_error", err.Error())
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	span.SetAttributes(attribute.Int("price_history.count", len(history)))
+	span.SetStatus(codes.Ok, "price history retrieved")
+	respondWithJSON(w, http.StatusOK, history)
+}
+
+func (h *PricingHandler) GetPriceAnalytics(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	_, span := pricingTracer.Start(r.Context(), "PricingHandler.GetPriceAnalytics")
+	defer span.End()
+
+	query := models.PriceSeriesQuery{
+		Variety:  params.Get("variety"),
+		Interval: models.PriceInterval(params.Get("interval")),
+		Type:     models.PriceSeriesType(params.Get("type")),
+	}
+	switch query.Interval {
+	case "", models.DailyInterval, models.WeeklyInterval, models.MonthlyInterval:
+	default:
+		recordSpanError(span, nil, "validation_error", "client_error", "invalid interval")
+		respondWithError(w, http.StatusBadRequest, "interval must be day, week or month")
+		return
+	}
+	switch query.Type {
+	case "", models.OHLCSeries, models.AverageSeries:
+	default:
+		recordSpanError(span, nil, "validation_error", "client_error", "invalid type")
+		respondWithError(w, http.StatusBadRequest, "type must be ohlc or average")
+		return
+	}
+	for name, date := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid "+name)
+			respondWithError(w, http.StatusBadRequest, name+" must be a date in YYYY-MM-DD format")
+			return
+		}
+		*date = parsed
+	}
+
+	analytics, err := h.service.PriceSeries(query)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("price_analytics.interval", string(analytics.Interval)),
+		attribute.String("price_analytics.type", string(analytics.Type)),
+		attribute.Int("price_analytics.series_count", len(analytics.Series)),
+	)
+	if query.Variety != "" {
+		span.SetAttributes(attribute.String("potato.variety", query.Variety))
+	}
+	span.SetStatus(codes.Ok, "price analytics retrieved")
+	respondWithJSON(w, http.StatusOK, analytics)
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var purchasingTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/purchasing")
+
+type PurchasingHandler struct {
+	service *service.PurchasingService
+	obs     ObservabilityLogger
+}
+
+func NewPurchasingHandler(service *service.PurchasingService, obs ObservabilityLogger) *PurchasingHandler {
+	return &PurchasingHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *PurchasingHandler) GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetAllSuppliers")
+	defer span.End()
+
+	suppliers := h.service.GetAllSuppliers()
+
+	span.SetAttributes(attribute.Int("supplier.count", len(suppliers)))
+	span.SetStatus(codes.Ok, "suppliers retrieved")
+	respondWithJSON(w, http.StatusOK, suppliers)
+}
+
+func (h *PurchasingHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.CreateSupplier")
+	defer span.End()
+
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateSupplier(supplier)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Supplier created",
+			logapi.String("supplier_id", created.ID))
+	}
+
+	span.SetAttributes(attribute.String("supplier.id", created.ID))
+	span.SetStatus(codes.Ok, "supplier created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *PurchasingHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetSupplier")
+	defer span.End()
+	span.SetAttributes(attribute.String("supplier.id", id))
+
+	supplier, err := h.service.GetSupplier(id)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "supplier retrieved")
+	respondWithJSON(w, http.StatusOK, supplier)
+}
+
+func (h *PurchasingHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.UpdateSupplier")
+	defer span.End()
+	span.SetAttributes(attribute.String("supplier.id", id))
+
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.UpdateSupplier(id, supplier)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Supplier updated",
+			logapi.String("supplier_id", id))
+	}
+
+	span.SetStatus(codes.Ok, "supplier updated")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *PurchasingHandler) GetSupplierPerformance(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetSupplierPerformance")
+	defer span.End()
+	span.SetAttributes(attribute.String("supplier.id", id))
+
+	performance, err := h.service.GetPerformance(id)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("supplier.deliveries", performance.Deliveries),
+		attribute.Float64("supplier.on_time_rate", performance.OnTimeRate),
+	)
+	span.SetStatus(codes.Ok, "supplier performance computed")
+	respondWithJSON(w, http.StatusOK, performance)
+}
+
+func (h *PurchasingHandler) GetSupplierReport(w http.ResponseWriter, r *http.Request) {
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetSupplierReport")
+	defer span.End()
+
+	performance := h.service.GetAllPerformance()
+
+	span.SetAttributes(attribute.Int("supplier.count", len(performance)))
+	span.SetStatus(codes.Ok, "supplier report computed")
+	respondWithJSON(w, http.StatusOK, performance)
+}
+
+func (h *PurchasingHandler) GetPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetPurchaseOrders")
+	defer span.End()
+
+	orders, err := h.service.GetPurchaseOrders(models.PurchaseOrderFilter{
+		SupplierID: query.Get("supplier_id"),
+		Status:     models.PurchaseOrderStatus(query.Get("status")),
+	})
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("purchase_order.count", len(orders)))
+	span.SetStatus(codes.Ok, "purchase orders retrieved")
+	respondWithJSON(w, http.StatusOK, orders)
+}
+
+func (h *PurchasingHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.CreatePurchaseOrder")
+	defer span.End()
+
+	var order models.PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreatePurchaseOrder(order)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Purchase order created",
+			logapi.String("purchase_order_id", created.ID),
+			logapi.String("supplier_id", created.SupplierID),
+			logapi.Float64("ordered_kg", created.OrderedKg))
+	}
+
+	span.SetAttributes(
+		attribute.String("purchase_order.id", created.ID),
+		attribute.String("supplier.id", created.SupplierID),
+	)
+	span.SetStatus(codes.Ok, "purchase order created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *PurchasingHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetPurchaseOrder")
+	defer span.End()
+	span.SetAttributes(attribute.String("purchase_order.id", id))
+
+	order, err := h.service.GetPurchaseOrder(id)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "purchase order retrieved")
+	respondWithJSON(w, http.StatusOK, order)
+}
+
+func (h *PurchasingHandler) ClosePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.ClosePurchaseOrder")
+	defer span.End()
+	span.SetAttributes(attribute.String("purchase_order.id", id))
+
+	order, err := h.service.ClosePurchaseOrder(id)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Purchase order closed",
+			logapi.String("purchase_order_id", id),
+			logapi.Float64("received_kg", order.ReceivedKg),
+			logapi.Float64("ordered_kg", order.OrderedKg))
+	}
+
+	span.SetStatus(codes.Ok, "purchase order closed")
+	respondWithJSON(w, http.StatusOK, order)
+}
+
+func (h *PurchasingHandler) ReceivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.ReceivePurchaseOrder")
+	defer span.End()
+	span.SetAttributes(attribute.String("purchase_order.id", id))
+
+	var request models.ReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	receipt, err := h.service.Receive(id, request)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	potatoes := 0
+	for _, line := range receipt.Lines {
+		potatoes += line.Potatoes
+	}
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Purchase order delivery received",
+			logapi.String("purchase_order_id", id),
+			logapi.String("receipt_id", receipt.ID),
+			logapi.Int("potatoes", potatoes),
+			logapi.Bool("on_time", receipt.OnTime))
+	}
+
+	span.SetAttributes(
+		attribute.String("receipt.id", receipt.ID),
+		attribute.Int("receipt.potatoes", potatoes),
+		attribute.Bool("receipt.on_time", receipt.OnTime),
+	)
+	span.SetStatus(codes.Ok, "delivery received")
+	respondWithJSON(w, http.StatusCreated, receipt)
+}
+
+func (h *PurchasingHandler) GetReceipts(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := purchasingTracer.Start(r.Context(), "PurchasingHandler.GetReceipts")
+	defer span.End()
+	span.SetAttributes(attribute.String("purchase_order.id", id))
+
+	receipts, err := h.service.GetReceipts(id)
+	if err != nil {
+		respondWithPurchasingError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("receipt.count", len(receipts)))
+	span.SetStatus(codes.Ok, "receipts retrieved")
+	respondWithJSON(w, http.StatusOK, receipts)
+}
+
+// respondWithPurchasingError uses placementStatus for deliveries that do
+// not fit in stock or belong to a recalled lot.
+func respondWithPurchasingError(w http.ResponseWriter, span trace.Span, err error) {
+	status := placementStatus(err)
+	msg := err.Error()
+	errType := "validation_error"
+	switch {
+	case errors.Is(err, storage.ErrSupplierNotFound):
+		status = http.StatusNotFound
+		msg = "Supplier not found"
+		errType = "not_found"
+	case errors.Is(err, storage.ErrPurchaseOrderNotFound):
+		status = http.StatusNotFound
+		msg = "Purchase order not found"
+		errType = "not_found"
+	case errors.Is(err, service.ErrDuplicateSupplier), errors.Is(err, service.ErrDuplicatePurchaseOrder),
+		errors.Is(err, service.ErrPurchaseOrderClosed), errors.Is(err, service.ErrOverReceipt):
+		status = http.StatusConflict
+		errType = "conflict"
+	case status == http.StatusConflict:
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
@@ -1,30 +1,40 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/render"
 	"github.com/williamdumont/potato-demo/service"
 	"github.com/williamdumont/potato-demo/storage"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var recipeTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/recipe")
 
 type RecipeHandler struct {
 	service   *service.RecipeService
+	cards     *render.RecipeRenderer
 	telemetry TelemetryRecorder
 	obs       ObservabilityLogger
 }
 
-func NewRecipeHandler(service *service.RecipeService, telemetry TelemetryRecorder, obs ObservabilityLogger) *RecipeHandler {
+func NewRecipeHandler(service *service.RecipeService, cards *render.RecipeRenderer, telemetry TelemetryRecorder, obs ObservabilityLogger) *RecipeHandler {
 	return &RecipeHandler{
 		service:   service,
+		cards:     cards,
 		telemetry: telemetry,
 		obs:       obs,
 	}
@@ -51,8 +61,7 @@ func (h *RecipeHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
 
 	createdRecipe, err := h.service.CreateRecipe(recipe)
 	if err != nil {
-		recordSpanError(span, err, "validation_error", "client_error", err.Error())
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		respondWithRecipeError(w, span, err)
 		return
 	}
 
@@ -66,6 +75,46 @@ func (h *RecipeHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusCreated, createdRecipe)
 }
 
+func (h *RecipeHandler) ImportRecipes(w http.ResponseWriter, r *http.Request) {
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.ImportRecipes")
+	defer span.End()
+	defer r.Body.Close()
+
+	var document interface{}
+	if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	result, err := h.service.ImportRecipesJSONLD(document, r.URL.Query().Get("author"))
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("recipe.imported_count", len(result.Imported)),
+		attribute.Int("recipe.failed_count", len(result.Failed)),
+	)
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Recipes imported from JSON-LD",
+			logapi.Int("imported", len(result.Imported)),
+			logapi.Int("failed", len(result.Failed)))
+	}
+
+	if len(result.Imported) == 0 {
+		recordSpanError(span, nil, "validation_error", "client_error", "no recipes imported")
+		respondWithJSON(w, http.StatusBadRequest, result)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "recipes imported")
+	respondWithJSON(w, http.StatusCreated, result)
+}
+
 func (h *RecipeHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -74,6 +123,14 @@ func (h *RecipeHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 	defer span.End()
 	span.SetAttributes(attribute.String("recipe.id", id))
 
+	format, err := negotiateFormat(w, r, formatJSON, formatJSONLD, formatMarkdown, formatHTML)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.String("recipe.format", format))
+
 	recipe, err := h.service.GetRecipe(id)
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -91,28 +148,167 @@ func (h *RecipeHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.service.RecordView(recipe.ID)
 	if h.telemetry != nil {
 		h.telemetry.RecordRecipeView(r.Context(), recipe.ID, recipe.Name)
 	}
-	span.SetStatus(codes.Ok, "recipe retrieved")
-	respondWithJSON(w, http.StatusOK, recipe)
+
+	query := r.URL.Query()
+	if query.Has("servings") || query.Has("units") {
+		scaled, ok := h.scaleRecipe(w, r, span, recipe)
+		if !ok {
+			return
+		}
+		if format == formatJSON {
+			span.SetStatus(codes.Ok, "scaled recipe retrieved")
+			respondWithJSON(w, http.StatusOK, scaled)
+			return
+		}
+		recipe = scaled.Recipe
+	}
+
+	switch format {
+	case formatJSONLD:
+		body, err := json.Marshal(h.service.ExportRecipeJSONLD(recipe))
+		if err != nil {
+			recordSpanError(span, err, "encode_error", "server_error", "failed to encode recipe as JSON-LD")
+			respondWithError(w, http.StatusInternalServerError, "Failed to encode recipe")
+			return
+		}
+		span.SetStatus(codes.Ok, "recipe exported as JSON-LD")
+		respondWithText(w, http.StatusOK, "application/ld+json", body)
+	case formatMarkdown, formatHTML:
+		var card bytes.Buffer
+		if err := h.cards.RecipeCard(&card, render.Format(format), recipe); err != nil {
+			recordSpanError(span, err, "render_error", "server_error", "failed to render recipe card")
+			respondWithError(w, http.StatusInternalServerError, "Failed to render recipe card")
+			return
+		}
+		span.SetStatus(codes.Ok, "recipe card rendered")
+		respondWithText(w, http.StatusOK, render.Format(format).ContentType(), card.Bytes())
+	default:
+		span.SetStatus(codes.Ok, "recipe retrieved")
+		respondWithJSON(w, http.StatusOK, recipe)
+	}
 }
 
-func (h *RecipeHandler) GetAllRecipes(w http.ResponseWriter, r *http.Request) {
+// scaleRecipe applies the servings and units query parameters to a recipe
+// already loaded. On failure it has already written the error response.
+func (h *RecipeHandler) scaleRecipe(w http.ResponseWriter, r *http.Request, span trace.Span, recipe models.Recipe) (models.ScaledRecipe, bool) {
+	query := r.URL.Query()
+	servings := 0
+	if raw := query.Get("servings"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			recordSpanError(span, err, "validation_error", "client_error", service.ErrInvalidServings.Error())
+			respondWithError(w, http.StatusBadRequest, service.ErrInvalidServings.Error())
+			return models.ScaledRecipe{}, false
+		}
+		servings = parsed
+	}
+	units := models.UnitSystem(strings.ToLower(query.Get("units")))
+
+	span.SetAttributes(
+		attribute.Int("recipe.servings", servings),
+		attribute.String("recipe.units", string(units)),
+	)
+
+	scaled, err := h.service.ScaleRecipe(recipe, servings, units)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return models.ScaledRecipe{}, false
+	}
+
+	if h.obs != nil {
+		h.obs.EmitDebugLog(r.Context(), "Recipe scaled",
+			logapi.String("recipe_id", recipe.ID),
+			logapi.Int("servings", scaled.Servings),
+			logapi.String("units", string(units)))
+	}
+
+	return scaled, true
+}
+
+func (h *RecipeHandler) GetCookbook(w http.ResponseWriter, r *http.Request) {
 	variety := r.URL.Query().Get("variety")
 
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetCookbook")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.variety", variety))
+
+	format, err := negotiateFormat(w, r, formatMarkdown, formatHTML)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.String("recipe.format", format))
+
+	if variety == "" {
+		recordSpanError(span, nil, "validation_error", "client_error", "missing variety parameter")
+		respondWithError(w, http.StatusBadRequest, "variety parameter is required")
+		return
+	}
+
+	recipes, err := h.service.VarietyRecipes(variety)
+	if err != nil {
+		recordSpanError(span, err, "not_found", "client_error", err.Error())
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	variety = recipes[0].Variety
+	book := render.Book{
+		Title:       variety + " Cookbook",
+		Description: fmt.Sprintf("Recipes featuring %s potatoes, sorted by name.", variety),
+		Recipes:     recipes,
+	}
+	var doc bytes.Buffer
+	if err := h.cards.Cookbook(&doc, render.Format(format), book); err != nil {
+		recordSpanError(span, err, "render_error", "server_error", "failed to render cookbook")
+		respondWithError(w, http.StatusInternalServerError, "Failed to render cookbook")
+		return
+	}
+
+	span.SetAttributes(attribute.Int("recipe.count", len(recipes)))
+	span.SetStatus(codes.Ok, "cookbook rendered")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s-cookbook.%s"`, render.Slug(variety), render.Format(format).Extension()))
+	respondWithText(w, http.StatusOK, render.Format(format).ContentType(), doc.Bytes())
+}
+
+func (h *RecipeHandler) GetAllRecipes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	variety := query.Get("variety")
+
 	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetAllRecipes")
 	defer span.End()
 	if variety != "" {
 		span.SetAttributes(attribute.String("recipe.variety", variety))
 	}
 
-	var recipes []models.Recipe
-	if variety != "" {
-		recipes = h.service.GetRecipesByVariety(variety)
-	} else {
-		recipes = h.service.GetAllRecipes()
+	filter := models.RecipeFilter{
+		Variety:       variety,
+		CookingMethod: models.CookingMethod(query.Get("cooking_method")),
+		Tags:          splitQueryList(query.Get("tags")),
+	}
+	for _, flag := range splitQueryList(query.Get("dietary")) {
+		filter.Dietary = append(filter.Dietary, models.DietaryFlag(flag))
+	}
+	for _, allergen := range splitQueryList(query.Get("exclude_allergens")) {
+		filter.ExcludeAllergens = append(filter.ExcludeAllergens, models.Allergen(allergen))
+	}
+
+	filter, err := h.service.NormalizeFilter(filter)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
+	if filter.CookingMethod != "" {
+		span.SetAttributes(attribute.String("recipe.cooking_method", string(filter.CookingMethod)))
+	}
+
+	recipes := h.service.FilterRecipes(filter)
 
 	span.SetAttributes(attribute.Int("recipe.count", len(recipes)))
 	span.SetStatus(codes.Ok, "recipe list retrieved")
@@ -136,13 +332,27 @@ func (h *RecipeHandler) RecommendRecipe(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	var excluded []models.Allergen
+	for _, allergen := range splitQueryList(r.URL.Query().Get("exclude_allergens")) {
+		excluded = append(excluded, models.Allergen(allergen))
+	}
+	excluded, err := h.service.NormalizeAllergens(excluded)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(excluded) > 0 {
+		span.SetAttributes(attribute.Int("recipe.excluded_allergen_count", len(excluded)))
+	}
+
 	if h.obs != nil {
 		h.obs.EmitDebugLog(r.Context(), "Recommending recipe", 
 			logapi.String("variety", variety),
 			logapi.String("difficulty", difficulty))
 	}
 
-	recipe, err := h.service.RecommendRecipe(variety, difficulty)
+	recipe, err := h.service.RecommendRecipe(variety, difficulty, excluded)
 	if err != nil {
 		errType := "not_found"
 		errCategory := "client_error"
@@ -162,9 +372,360 @@ func (h *RecipeHandler) RecommendRecipe(w http.ResponseWriter, r *http.Request)
 	}
 
 	span.SetAttributes(attribute.String("recipe.id", recipe.ID))
+	h.service.RecordView(recipe.ID)
 	if h.telemetry != nil {
 		h.telemetry.RecordRecipeView(r.Context(), recipe.ID, recipe.Name)
 	}
 	span.SetStatus(codes.Ok, "recipe recommendation ready")
 	respondWithJSON(w, http.StatusOK, recipe)
 }
+
+func (h *RecipeHandler) SearchRecipes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.SearchRecipes")
+	defer span.End()
+	span.SetAttributes(attribute.String("search.query", query))
+
+	limit := 10
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid limit parameter")
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	if h.obs != nil {
+		h.obs.EmitDebugLog(r.Context(), "Searching recipes",
+			logapi.String("query", query),
+			logapi.Int("limit", limit))
+	}
+
+	results, err := h.service.SearchRecipes(query, limit)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(attribute.Int("search.result_count", len(results)))
+	span.SetStatus(codes.Ok, "recipe search completed")
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+func (h *RecipeHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.SubmitReview")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	var review models.Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.SubmitReview(id, review)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Recipe review submitted",
+			logapi.String("recipe_id", id),
+			logapi.Int("rating", created.Rating))
+	}
+
+	span.SetAttributes(attribute.Int("review.rating", created.Rating))
+	span.SetStatus(codes.Ok, "review submitted")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *RecipeHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetReviews")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	reviews, err := h.service.GetReviews(id)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("review.count", len(reviews)))
+	span.SetStatus(codes.Ok, "reviews retrieved")
+	respondWithJSON(w, http.StatusOK, reviews)
+}
+
+func (h *RecipeHandler) GetRecipeStats(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetRecipeStats")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	stats, err := h.service.GetRecipeStats(id)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "recipe stats retrieved")
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
+func (h *RecipeHandler) GetNutrition(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetNutrition")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	report, err := h.service.CalculateNutrition(id)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Bool("nutrition.complete", report.Complete),
+		attribute.Int("nutrition.unrecognized_count", len(report.Unrecognized)),
+	)
+	span.SetStatus(codes.Ok, "recipe nutrition calculated")
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func (h *RecipeHandler) TopRecipes(w http.ResponseWriter, r *http.Request) {
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.TopRecipes")
+	defer span.End()
+
+	limit := 10
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid limit parameter")
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	top := h.service.TopRecipes(limit)
+
+	span.SetAttributes(attribute.Int("recipe.count", len(top)))
+	span.SetStatus(codes.Ok, "top recipes retrieved")
+	respondWithJSON(w, http.StatusOK, top)
+}
+
+func respondWithRecipeError(w http.ResponseWriter, span trace.Span, err error) {
+	status := http.StatusBadRequest
+	msg := err.Error()
+	errType := "validation_error"
+	switch {
+	case errors.Is(err, storage.ErrRecipeNotFound):
+		status = http.StatusNotFound
+		msg = "Recipe not found"
+		errType = "not_found"
+	case errors.Is(err, storage.ErrRevisionNotFound):
+		status = http.StatusNotFound
+		errType = "not_found"
+	case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrRecipeIDTaken):
+		status = http.StatusConflict
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
+
+// CookRecipe takes the potatoes for a recipe out of stock. The body is
+// optional and may set how many potatoes were used.
+func (h *RecipeHandler) CookRecipe(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.CookRecipe")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	var body struct {
+		Potatoes int `json:"potatoes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	event, err := h.service.Cook(id, body.Potatoes)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Recipe cooked",
+			logapi.String("recipe_id", id),
+			logapi.String("variety", event.Variety),
+			logapi.Int("potatoes", event.Potatoes))
+	}
+	span.SetAttributes(
+		attribute.String("potato.variety", event.Variety),
+		attribute.Int("recipe.potatoes_used", event.Potatoes),
+	)
+	span.SetStatus(codes.Ok, "recipe cooked")
+	respondWithJSON(w, http.StatusOK, event)
+}
+
+func (h *RecipeHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.UpdateRecipe")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	var recipe models.Recipe
+	if err := json.NewDecoder(r.Body).Decode(&recipe); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.UpdateRecipe(id, recipe)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Recipe updated successfully",
+			logapi.String("recipe_id", id),
+			logapi.Int("revision", updated.Revision),
+			logapi.String("author", updated.UpdatedBy))
+	}
+
+	span.SetAttributes(attribute.Int("recipe.revision", updated.Revision))
+	span.SetStatus(codes.Ok, "recipe updated")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *RecipeHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetRevisions")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	revisions, err := h.service.GetRevisions(id)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("recipe.revision_count", len(revisions)))
+	span.SetStatus(codes.Ok, "revisions retrieved")
+	respondWithJSON(w, http.StatusOK, revisions)
+}
+
+func (h *RecipeHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.GetRevision")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	revision, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		respondWithRecipeError(w, span, service.ErrInvalidRevision)
+		return
+	}
+
+	found, err := h.service.GetRevision(id, revision)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "revision retrieved")
+	respondWithJSON(w, http.StatusOK, found)
+}
+
+func (h *RecipeHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.DiffRevisions")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		recordSpanError(span, nil, "validation_error", "client_error", "invalid revision range")
+		respondWithError(w, http.StatusBadRequest, "from and to must be revision numbers")
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("recipe.revision_from", from),
+		attribute.Int("recipe.revision_to", to),
+	)
+
+	diff, err := h.service.DiffRevisions(id, from, to)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "revision diff computed")
+	respondWithJSON(w, http.StatusOK, diff)
+}
+
+func (h *RecipeHandler) RevertRecipe(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := recipeTracer.Start(r.Context(), "RecipeHandler.RevertRecipe")
+	defer span.End()
+	span.SetAttributes(attribute.String("recipe.id", id))
+
+	revision, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		respondWithRecipeError(w, span, service.ErrInvalidRevision)
+		return
+	}
+
+	var body struct {
+		Author string `json:"author"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+			respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	reverted, err := h.service.RevertRecipe(id, revision, body.Author)
+	if err != nil {
+		respondWithRecipeError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Recipe reverted",
+			logapi.String("recipe_id", id),
+			logapi.Int("restored_revision", revision),
+			logapi.Int("revision", reverted.Revision))
+	}
+
+	span.SetStatus(codes.Ok, "recipe reverted")
+	respondWithJSON(w, http.StatusOK, reverted)
+}
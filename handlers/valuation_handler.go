@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var valuationTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/valuation")
+
+type ValuationHandler struct {
+	service *service.ValuationService
+	obs     ObservabilityLogger
+}
+
+func NewValuationHandler(service *service.ValuationService, obs ObservabilityLogger) *ValuationHandler {
+	return &ValuationHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *ValuationHandler) GetValuationReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	_, span := valuationTracer.Start(r.Context(), "ValuationHandler.GetValuationReport")
+	defer span.End()
+
+	query := models.ValuationQuery{Variety: params.Get("variety")}
+	for _, raw := range splitQueryList(params.Get("method")) {
+		method, ok := valuationMethod(raw)
+		if !ok {
+			recordSpanError(span, nil, "validation_error", "client_error", "invalid method")
+			respondWithError(w, http.StatusBadRequest, "method must be fifo, weighted_average or specific_identification")
+			return
+		}
+		query.Methods = append(query.Methods, method)
+	}
+	// A bare date covers the whole day for as_of and starts at midnight
+	// for from.
+	for name, date := range map[string]*time.Time{"as_of": &query.AsOf, "from": &query.From} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseTrendTime(raw)
+		if err != nil {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid "+name)
+			respondWithError(w, http.StatusBadRequest, name+" must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+		if name == "as_of" && len(raw) == len("2006-01-02") {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		*date = parsed
+	}
+
+	report, err := h.service.Report(query)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("valuation.variety_count", len(report.Varieties)),
+		attribute.Int("valuation.uncosted", report.Uncosted),
+	)
+	span.SetStatus(codes.Ok, "valuation report generated")
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func valuationMethod(raw string) (models.ValuationMethod, bool) {
+	for _, method := range models.ValuationMethods {
+		if string(method) == raw {
+			return method, true
+		}
+	}
+	return "", false
+}
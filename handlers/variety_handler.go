@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var varietyTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/variety")
+
+type VarietyHandler struct {
+	service *service.VarietyService
+	obs     ObservabilityLogger
+}
+
+func NewVarietyHandler(service *service.VarietyService, obs ObservabilityLogger) *VarietyHandler {
+	return &VarietyHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *VarietyHandler) GetAllVarieties(w http.ResponseWriter, r *http.Request) {
+	_, span := varietyTracer.Start(r.Context(), "VarietyHandler.GetAllVarieties")
+	defer span.End()
+
+	varieties := h.service.GetAllVarieties()
+
+	span.SetAttributes(attribute.Int("variety.count", len(varieties)))
+	span.SetStatus(codes.Ok, "variety catalog retrieved")
+	respondWithJSON(w, http.StatusOK, varieties)
+}
+
+func (h *VarietyHandler) CreateVariety(w http.ResponseWriter, r *http.Request) {
+	_, span := varietyTracer.Start(r.Context(), "VarietyHandler.CreateVariety")
+	defer span.End()
+
+	var variety models.Variety
+	if err := json.NewDecoder(r.Body).Decode(&variety); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateVariety(variety)
+	if err != nil {
+		respondWithVarietyError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Variety added to catalog",
+			logapi.String("variety", created.Name),
+			logapi.Int("alias_count", len(created.Aliases)))
+	}
+
+	span.SetAttributes(attribute.String("variety.name", created.Name))
+	span.SetStatus(codes.Ok, "variety created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *VarietyHandler) GetVariety(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	_, span := varietyTracer.Start(r.Context(), "VarietyHandler.GetVariety")
+	defer span.End()
+	span.SetAttributes(attribute.String("variety.name", name))
+
+	variety, err := h.service.GetVariety(name)
+	if err != nil {
+		respondWithVarietyError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "variety retrieved")
+	respondWithJSON(w, http.StatusOK, variety)
+}
+
+func (h *VarietyHandler) UpdateVariety(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	_, span := varietyTracer.Start(r.Context(), "VarietyHandler.UpdateVariety")
+	defer span.End()
+	span.SetAttributes(attribute.String("variety.name", name))
+
+	var variety models.Variety
+	if err := json.NewDecoder(r.Body).Decode(&variety); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.UpdateVariety(name, variety)
+	if err != nil {
+		respondWithVarietyError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Variety updated",
+			logapi.String("variety", updated.Name))
+	}
+
+	span.SetStatus(codes.Ok, "variety updated")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *VarietyHandler) DeleteVariety(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	_, span := varietyTracer.Start(r.Context(), "VarietyHandler.DeleteVariety")
+	defer span.End()
+	span.SetAttributes(attribute.String("variety.name", name))
+
+	if err := h.service.DeleteVariety(name); err != nil {
+		respondWithVarietyError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Variety deleted successfully",
+			logapi.String("variety", name))
+	}
+
+	span.SetStatus(codes.Ok, "variety deleted")
+	respondWithJSON(w, http.StatusOK, map[string]string{"result": "success"})
+}
+
+func respondWithVarietyError(w http.ResponseWriter, span trace.Span, err error) {
+	status := http.StatusBadRequest
+	msg := err.Error()
+	errType := "validation_error"
+	switch {
+	case errors.Is(err, service.ErrUnknownVariety), errors.Is(err, storage.ErrVarietyNotFound):
+		status = http.StatusNotFound
+		msg = "Variety not found"
+		errType = "not_found"
+	case errors.Is(err, service.ErrVarietyConflict), errors.Is(err, service.ErrVarietyInUse):
+		status = http.StatusConflict
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
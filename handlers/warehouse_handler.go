@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"github.com/williamdumont/potato-demo/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var warehouseTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/warehouse")
+
+type WarehouseHandler struct {
+	service *service.WarehouseService
+	obs     ObservabilityLogger
+}
+
+func NewWarehouseHandler(service *service.WarehouseService, obs ObservabilityLogger) *WarehouseHandler {
+	return &WarehouseHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *WarehouseHandler) GetAllWarehouses(w http.ResponseWriter, r *http.Request) {
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.GetAllWarehouses")
+	defer span.End()
+
+	warehouses := h.service.GetAllWarehouses()
+
+	span.SetAttributes(attribute.Int("warehouse.count", len(warehouses)))
+	span.SetStatus(codes.Ok, "warehouses retrieved")
+	respondWithJSON(w, http.StatusOK, warehouses)
+}
+
+func (h *WarehouseHandler) CreateWarehouse(w http.ResponseWriter, r *http.Request) {
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.CreateWarehouse")
+	defer span.End()
+
+	var warehouse models.Warehouse
+	if err := json.NewDecoder(r.Body).Decode(&warehouse); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateWarehouse(warehouse)
+	if err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Warehouse created",
+			logapi.String("warehouse_id", created.ID))
+	}
+
+	span.SetAttributes(attribute.String("warehouse.id", created.ID))
+	span.SetStatus(codes.Ok, "warehouse created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *WarehouseHandler) GetWarehouse(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.GetWarehouse")
+	defer span.End()
+	span.SetAttributes(attribute.String("warehouse.id", id))
+
+	warehouse, err := h.service.GetWarehouse(id)
+	if err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "warehouse retrieved")
+	respondWithJSON(w, http.StatusOK, warehouse)
+}
+
+func (h *WarehouseHandler) DeleteWarehouse(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.DeleteWarehouse")
+	defer span.End()
+	span.SetAttributes(attribute.String("warehouse.id", id))
+
+	if err := h.service.DeleteWarehouse(id); err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Warehouse deleted",
+			logapi.String("warehouse_id", id))
+	}
+
+	span.SetStatus(codes.Ok, "warehouse deleted")
+	respondWithJSON(w, http.StatusOK, map[string]string{"result": "success"})
+}
+
+func (h *WarehouseHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	warehouseID := mux.Vars(r)["id"]
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.CreateLocation")
+	defer span.End()
+	span.SetAttributes(attribute.String("warehouse.id", warehouseID))
+
+	var location models.Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	created, err := h.service.CreateLocation(warehouseID, location)
+	if err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Location created",
+			logapi.String("warehouse_id", warehouseID),
+			logapi.String("location_id", created.ID),
+			logapi.Float64("capacity_kg", created.CapacityKg))
+	}
+
+	span.SetAttributes(attribute.String("location.id", created.ID))
+	span.SetStatus(codes.Ok, "location created")
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *WarehouseHandler) GetLocation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.GetLocation")
+	defer span.End()
+	span.SetAttributes(attribute.String("location.id", id))
+
+	location, err := h.service.GetLocation(id)
+	if err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "location retrieved")
+	respondWithJSON(w, http.StatusOK, location)
+}
+
+func (h *WarehouseHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.UpdateLocation")
+	defer span.End()
+	span.SetAttributes(attribute.String("location.id", id))
+
+	var location models.Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := h.service.UpdateLocation(id, location)
+	if err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Location updated",
+			logapi.String("location_id", id),
+			logapi.Float64("capacity_kg", updated.CapacityKg))
+	}
+
+	span.SetStatus(codes.Ok, "location updated")
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *WarehouseHandler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.DeleteLocation")
+	defer span.End()
+	span.SetAttributes(attribute.String("location.id", id))
+
+	if err := h.service.DeleteLocation(id); err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Location deleted",
+			logapi.String("location_id", id))
+	}
+
+	span.SetStatus(codes.Ok, "location deleted")
+	respondWithJSON(w, http.StatusOK, map[string]string{"result": "success"})
+}
+
+func (h *WarehouseHandler) TransferPotatoes(w http.ResponseWriter, r *http.Request) {
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.TransferPotatoes")
+	defer span.End()
+
+	var request models.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", "invalid request payload")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	span.SetAttributes(
+		attribute.String("location.id", request.ToLocationID),
+		attribute.Int("transfer.requested", len(request.PotatoIDs)),
+	)
+
+	transfers, err := h.service.Transfer(request)
+	if err != nil {
+		respondWithWarehouseError(w, span, err)
+		return
+	}
+
+	if h.obs != nil {
+		h.obs.EmitInfoLog(r.Context(), "Potatoes transferred",
+			logapi.String("to_location_id", request.ToLocationID),
+			logapi.Int("transferred", len(transfers)))
+	}
+
+	span.SetAttributes(attribute.Int("transfer.count", len(transfers)))
+	span.SetStatus(codes.Ok, "potatoes transferred")
+	respondWithJSON(w, http.StatusOK, transfers)
+}
+
+func (h *WarehouseHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	_, span := warehouseTracer.Start(r.Context(), "WarehouseHandler.GetTransfers")
+	defer span.End()
+
+	filter := models.TransferFilter{
+		PotatoID:   query.Get("potato_id"),
+		LocationID: query.Get("location_id"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid limit")
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	transfers := h.service.GetTransfers(filter)
+
+	span.SetAttributes(attribute.Int("transfer.count", len(transfers)))
+	span.SetStatus(codes.Ok, "transfers retrieved")
+	respondWithJSON(w, http.StatusOK, transfers)
+}
+
+func respondWithWarehouseError(w http.ResponseWriter, span trace.Span, err error) {
+	status := placementStatus(err)
+	msg := err.Error()
+	errType := "validation_error"
+	switch {
+	case errors.Is(err, storage.ErrWarehouseNotFound):
+		status = http.StatusNotFound
+		msg = "Warehouse not found"
+		errType = "not_found"
+	case errors.Is(err, storage.ErrLocationNotFound):
+		status = http.StatusNotFound
+		msg = "Location not found"
+		errType = "not_found"
+	case errors.Is(err, storage.ErrNotFound):
+		status = http.StatusNotFound
+		errType = "not_found"
+	case status == http.StatusConflict:
+		errType = "conflict"
+	}
+	recordSpanError(span, err, errType, "client_error", msg)
+	respondWithError(w, status, msg)
+}
+
+// placementStatus is 409 for stock that does not fit, would orphan what a
+// location holds, belongs to a recalled lot or reuses a potato ID, and 400
+// otherwise.
+func placementStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrLocationFull), errors.Is(err, service.ErrNoCapacity),
+		errors.Is(err, service.ErrDuplicateWarehouse), errors.Is(err, service.ErrDuplicateLocation),
+		errors.Is(err, service.ErrWarehouseInUse), errors.Is(err, service.ErrLocationInUse),
+		errors.Is(err, service.ErrCapacityBelowStock), errors.Is(err, service.ErrLotRecalled),
+		errors.Is(err, service.ErrDuplicatePotato):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	logapi "go.opentelemetry.io/otel/log"
+)
+
+var wasteTracer = otel.Tracer("github.com/williamdumont/potato-demo/handlers/waste")
+
+type WasteHandler struct {
+	service *service.WasteService
+	obs     ObservabilityLogger
+}
+
+func NewWasteHandler(service *service.WasteService, obs ObservabilityLogger) *WasteHandler {
+	return &WasteHandler{
+		service: service,
+		obs:     obs,
+	}
+}
+
+func (h *WasteHandler) GetWasteReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	_, span := wasteTracer.Start(r.Context(), "WasteHandler.GetWasteReport")
+	defer span.End()
+
+	query := models.WasteQuery{
+		Variety: params.Get("variety"),
+		Reason:  models.RemovalReason(params.Get("reason")),
+	}
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid limit")
+			respondWithError(w, http.StatusBadRequest, service.ErrInvalidWasteLimit.Error())
+			return
+		}
+		query.Limit = limit
+	}
+	// A bare date covers the whole day for to and starts at midnight for
+	// from.
+	for name, date := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseTrendTime(raw)
+		if err != nil {
+			recordSpanError(span, err, "validation_error", "client_error", "invalid "+name)
+			respondWithError(w, http.StatusBadRequest, name+" must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+		if name == "to" && len(raw) == len("2006-01-02") {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		*date = parsed
+	}
+
+	report, err := h.service.Report(query)
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("waste.count", report.Total.Count),
+		attribute.Float64("waste.weight_kg", report.Total.WeightKg),
+	)
+	span.SetStatus(codes.Ok, "waste report generated")
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func (h *WasteHandler) GetSpoilagePrediction(w http.ResponseWriter, r *http.Request) {
+	_, span := wasteTracer.Start(r.Context(), "WasteHandler.GetSpoilagePrediction")
+	defer span.End()
+
+	prediction, err := h.service.PredictSpoilage(r.URL.Query().Get("variety"))
+	if err != nil {
+		recordSpanError(span, err, "validation_error", "client_error", err.Error())
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if h.obs != nil && prediction.AtRisk > 0 {
+		h.obs.EmitInfoLog(r.Context(), "Potatoes predicted to spoil before use",
+			logapi.Int("at_risk", prediction.AtRisk))
+	}
+
+	span.SetAttributes(attribute.Int("spoilage.at_risk", prediction.AtRisk))
+	span.SetStatus(codes.Ok, "spoilage predicted")
+	respondWithJSON(w, http.StatusOK, prediction)
+}
@@ -12,7 +12,14 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/williamdumont/potato-demo/background"
+	"github.com/williamdumont/potato-demo/degradation"
+	"github.com/williamdumont/potato-demo/forecast"
+	"github.com/williamdumont/potato-demo/freshness"
 	"github.com/williamdumont/potato-demo/handlers"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/pricing"
+	"github.com/williamdumont/potato-demo/render"
+	"github.com/williamdumont/potato-demo/search"
 	"github.com/williamdumont/potato-demo/seed"
 	"github.com/williamdumont/potato-demo/service"
 	"github.com/williamdumont/potato-demo/storage"
@@ -37,23 +44,88 @@ func main() {
 	}()
 
 	store := storage.NewInMemoryStorage()
+	recipeIndex := search.NewRecipeIndex()
+	store.OnRecipeSaved(recipeIndex.Add)
 	seedData(store)
 
 	telemetry.EmitInfoLog(ctx, "Potato service starting up")
 
-	worker := background.NewWorker(store, telemetry)
+	freshnessRules, err := freshness.NewEngine(getEnv("FRESHNESS_RULES_FILE", ""))
+	if err != nil {
+		log.Fatalf("failed to load freshness rules: %v", err)
+	}
+	freshnessRules.Watch(5*time.Second, func(err error) {
+		if err != nil {
+			log.Printf("keeping previous freshness rules: %v", err)
+			return
+		}
+		telemetry.EmitInfoLog(ctx, "Freshness rules reloaded")
+	})
+
+	warehouseService := service.NewWarehouseService(store, freshnessRules)
+	potatoService := service.NewPotatoService(store, freshnessRules, warehouseService)
+	recipeService := service.NewRecipeService(store, recipeIndex)
+	mealPlanService := service.NewMealPlanService(store)
+
+	degradationPolicies, err := degradation.Load(getEnv("DEGRADATION_CONFIG_FILE", ""), getEnv("DEGRADATION_POLICY", ""))
+	if err != nil {
+		log.Fatalf("failed to load degradation policies: %v", err)
+	}
+	degradationService := service.NewDegradationService(store, potatoService, degradationPolicies)
+
+	priceRules, err := pricing.Load(getEnv("PRICING_RULES_FILE", ""))
+	if err != nil {
+		log.Fatalf("failed to load pricing rules: %v", err)
+	}
+	pricingService := service.NewPricingService(store, potatoService, priceRules)
+
+	inventoryHistory := service.NewInventoryHistoryService(store)
+	valuationService := service.NewValuationService(store, priceRules.Currency())
+
+	forecastSettings, err := forecast.Load(getEnv("FORECAST_SETTINGS_FILE", ""))
+	if err != nil {
+		log.Fatalf("failed to load forecast settings: %v", err)
+	}
+	forecastService := service.NewForecastService(store, forecastSettings)
+	varietyService := service.NewVarietyService(store, freshnessRules, priceRules, degradationPolicies, forecastSettings)
+	telemetry.UseVarietyCatalog(varietyService)
+	wasteService := service.NewWasteService(store, potatoService, forecastService)
+	lotService := service.NewLotService(store)
+	purchasingService := service.NewPurchasingService(store, potatoService, pricingService)
+	store.OnPotatoRemoved(func(removal models.StockMovement) {
+		telemetry.RecordRemoval(context.Background(), removal.Variety, string(removal.Reason),
+			removal.Reason.IsWaste(), removal.WeightKg, removal.PurchaseCost)
+	})
+
+	worker := background.NewWorker(store, degradationService, pricingService, inventoryHistory, forecastService, warehouseService, telemetry)
 	worker.StartPotatoGenerator(3 * time.Second)
 	worker.StartRecipeGenerator(8 * time.Second)
 	worker.StartQualityDegradation(20 * time.Second)
+	worker.StartRepricing(30 * time.Second)
+	worker.StartInventorySnapshots(10 * time.Second)
+	worker.StartAlertCheck(15 * time.Second)
 	worker.StartPotatoRemover(5 * time.Second)
 
 	telemetry.EmitDebugLog(ctx, "Background workers started")
 
-	potatoService := service.NewPotatoService(store)
-	recipeService := service.NewRecipeService(store)
-
 	potatoHandler := handlers.NewPotatoHandler(potatoService, telemetry, telemetry)
-	recipeHandler := handlers.NewRecipeHandler(recipeService, telemetry, telemetry)
+	recipeCards, err := render.NewRecipeRenderer(getEnv("RECIPE_TEMPLATE_DIR", ""))
+	if err != nil {
+		log.Fatalf("failed to load recipe templates: %v", err)
+	}
+
+	recipeHandler := handlers.NewRecipeHandler(recipeService, recipeCards, telemetry, telemetry)
+	mealPlanHandler := handlers.NewMealPlanHandler(mealPlanService, telemetry)
+	varietyHandler := handlers.NewVarietyHandler(varietyService, telemetry)
+	degradationHandler := handlers.NewDegradationHandler(degradationService, telemetry)
+	pricingHandler := handlers.NewPricingHandler(pricingService, telemetry)
+	inventoryHistoryHandler := handlers.NewInventoryHistoryHandler(inventoryHistory, telemetry)
+	valuationHandler := handlers.NewValuationHandler(valuationService, telemetry)
+	forecastHandler := handlers.NewForecastHandler(forecastService, telemetry)
+	wasteHandler := handlers.NewWasteHandler(wasteService, telemetry)
+	warehouseHandler := handlers.NewWarehouseHandler(warehouseService, telemetry)
+	lotHandler := handlers.NewLotHandler(lotService, telemetry)
+	purchasingHandler := handlers.NewPurchasingHandler(purchasingService, telemetry)
 
 	r := mux.NewRouter()
 	api := r.PathPrefix("/api/v1").Subrouter()
@@ -64,14 +136,103 @@ func main() {
 	api.Handle("/potatoes/{id}", telemetry.WrapHandler("PUT /potatoes/{id}", potatoHandler.UpdatePotato)).Methods("PUT")
 	api.Handle("/potatoes/{id}", telemetry.WrapHandler("DELETE /potatoes/{id}", potatoHandler.DeletePotato)).Methods("DELETE")
 	api.Handle("/potatoes/{id}/freshness", telemetry.WrapHandler("GET /potatoes/{id}/freshness", potatoHandler.CheckFreshness)).Methods("GET")
+	api.Handle("/potatoes/{id}/price-quote", telemetry.WrapHandler("GET /potatoes/{id}/price-quote", pricingHandler.GetPriceQuote)).Methods("GET")
+	api.Handle("/potatoes/{id}/price-history", telemetry.WrapHandler("GET /potatoes/{id}/price-history", pricingHandler.GetPriceHistory)).Methods("GET")
+	api.Handle("/freshness/rules", telemetry.WrapHandler("GET /freshness/rules", potatoHandler.GetFreshnessRules)).Methods("GET")
 
 	api.Handle("/inventory", telemetry.WrapHandler("GET /inventory", potatoHandler.GetInventory)).Methods("GET")
+	api.Handle("/inventory/consistency", telemetry.WrapHandler("GET /inventory/consistency", potatoHandler.CheckInventoryConsistency)).Methods("GET")
+	api.Handle("/inventory/history", telemetry.WrapHandler("GET /inventory/history", inventoryHistoryHandler.GetInventoryTrend)).Methods("GET")
 	api.Handle("/analytics", telemetry.WrapHandler("GET /analytics", potatoHandler.GetAnalytics)).Methods("GET")
+	api.Handle("/analytics/prices", telemetry.WrapHandler("GET /analytics/prices", pricingHandler.GetPriceAnalytics)).Methods("GET")
+
+	api.Handle("/reports/valuation", telemetry.WrapHandler("GET /reports/valuation", valuationHandler.GetValuationReport)).Methods("GET")
+	api.Handle("/reports/suppliers", telemetry.WrapHandler("GET /reports/suppliers", purchasingHandler.GetSupplierReport)).Methods("GET")
+
+	api.Handle("/forecasts", telemetry.WrapHandler("GET /forecasts", forecastHandler.GetForecasts)).Methods("GET")
+	api.Handle("/forecasts/{variety}", telemetry.WrapHandler("GET /forecasts/{variety}", forecastHandler.GetForecast)).Methods("GET")
+	api.Handle("/alerts", telemetry.WrapHandler("GET /alerts", forecastHandler.GetAlerts)).Methods("GET")
+	api.Handle("/waste", telemetry.WrapHandler("GET /waste", wasteHandler.GetWasteReport)).Methods("GET")
+	api.Handle("/waste/predicted", telemetry.WrapHandler("GET /waste/predicted", wasteHandler.GetSpoilagePrediction)).Methods("GET")
+
+	api.Handle("/warehouses", telemetry.WrapHandler("GET /warehouses", warehouseHandler.GetAllWarehouses)).Methods("GET")
+	api.Handle("/warehouses", telemetry.WrapHandler("POST /warehouses", warehouseHandler.CreateWarehouse)).Methods("POST")
+	api.Handle("/warehouses/{id}", telemetry.WrapHandler("GET /warehouses/{id}", warehouseHandler.GetWarehouse)).Methods("GET")
+	api.Handle("/warehouses/{id}", telemetry.WrapHandler("DELETE /warehouses/{id}", warehouseHandler.DeleteWarehouse)).Methods("DELETE")
+	api.Handle("/warehouses/{id}/locations", telemetry.WrapHandler("POST /warehouses/{id}/locations", warehouseHandler.CreateLocation)).Methods("POST")
+	api.Handle("/locations/{id}", telemetry.WrapHandler("GET /locations/{id}", warehouseHandler.GetLocation)).Methods("GET")
+	api.Handle("/locations/{id}", telemetry.WrapHandler("PUT /locations/{id}", warehouseHandler.UpdateLocation)).Methods("PUT")
+	api.Handle("/locations/{id}", telemetry.WrapHandler("DELETE /locations/{id}", warehouseHandler.DeleteLocation)).Methods("DELETE")
+	api.Handle("/transfers", telemetry.WrapHandler("GET /transfers", warehouseHandler.GetTransfers)).Methods("GET")
+	api.Handle("/transfers", telemetry.WrapHandler("POST /transfers", warehouseHandler.TransferPotatoes)).Methods("POST")
+
+	api.Handle("/lots", telemetry.WrapHandler("GET /lots", lotHandler.GetAllLots)).Methods("GET")
+	api.Handle("/lots", telemetry.WrapHandler("POST /lots", lotHandler.CreateLot)).Methods("POST")
+	api.Handle("/lots/{id}", telemetry.WrapHandler("GET /lots/{id}", lotHandler.GetLot)).Methods("GET")
+	api.Handle("/lots/{id}/trace", telemetry.WrapHandler("GET /lots/{id}/trace", lotHandler.TraceLot)).Methods("GET")
+	api.Handle("/lots/{id}/recall", telemetry.WrapHandler("POST /lots/{id}/recall", lotHandler.RecallLot)).Methods("POST")
+
+	api.Handle("/suppliers", telemetry.WrapHandler("GET /suppliers", purchasingHandler.GetAllSuppliers)).Methods("GET")
+	api.Handle("/suppliers", telemetry.WrapHandler("POST /suppliers", purchasingHandler.CreateSupplier)).Methods("POST")
+	api.Handle("/suppliers/{id}", telemetry.WrapHandler("GET /suppliers/{id}", purchasingHandler.GetSupplier)).Methods("GET")
+	api.Handle("/suppliers/{id}", telemetry.WrapHandler("PUT /suppliers/{id}", purchasingHandler.UpdateSupplier)).Methods("PUT")
+	api.Handle("/suppliers/{id}/performance", telemetry.WrapHandler("GET /suppliers/{id}/performance", purchasingHandler.GetSupplierPerformance)).Methods("GET")
+	api.Handle("/purchase-orders", telemetry.WrapHandler("GET /purchase-orders", purchasingHandler.GetPurchaseOrders)).Methods("GET")
+	api.Handle("/purchase-orders", telemetry.WrapHandler("POST /purchase-orders", purchasingHandler.CreatePurchaseOrder)).Methods("POST")
+	api.Handle("/purchase-orders/{id}", telemetry.WrapHandler("GET /purchase-orders/{id}", purchasingHandler.GetPurchaseOrder)).Methods("GET")
+	api.Handle("/purchase-orders/{id}/receipts", telemetry.WrapHandler("GET /purchase-orders/{id}/receipts", purchasingHandler.GetReceipts)).Methods("GET")
+	api.Handle("/purchase-orders/{id}/receipts", telemetry.WrapHandler("POST /purchase-orders/{id}/receipts", purchasingHandler.ReceivePurchaseOrder)).Methods("POST")
+	api.Handle("/purchase-orders/{id}/close", telemetry.WrapHandler("POST /purchase-orders/{id}/close", purchasingHandler.ClosePurchaseOrder)).Methods("POST")
+
+	api.Handle("/degradation/policies", telemetry.WrapHandler("GET /degradation/policies", degradationHandler.GetPolicies)).Methods("GET")
+	api.Handle("/degradation/preview", telemetry.WrapHandler("GET /degradation/preview", degradationHandler.PreviewDegradation)).Methods("GET")
+	api.Handle("/audit", telemetry.WrapHandler("GET /audit", degradationHandler.GetAuditLog)).Methods("GET")
+
+	api.Handle("/varieties", telemetry.WrapHandler("GET /varieties", varietyHandler.GetAllVarieties)).Methods("GET")
+	api.Handle("/varieties", telemetry.WrapHandler("POST /varieties", varietyHandler.CreateVariety)).Methods("POST")
+	api.Handle("/varieties/{name}", telemetry.WrapHandler("GET /varieties/{name}", varietyHandler.GetVariety)).Methods("GET")
+	api.Handle("/varieties/{name}", telemetry.WrapHandler("PUT /varieties/{name}", varietyHandler.UpdateVariety)).Methods("PUT")
+	api.Handle("/varieties/{name}", telemetry.WrapHandler("DELETE /varieties/{name}", varietyHandler.DeleteVariety)).Methods("DELETE")
 
 	api.Handle("/recipes", telemetry.WrapHandler("GET /recipes", recipeHandler.GetAllRecipes)).Methods("GET")
 	api.Handle("/recipes", telemetry.WrapHandler("POST /recipes", recipeHandler.CreateRecipe)).Methods("POST")
-	api.Handle("/recipes/{id}", telemetry.WrapHandler("GET /recipes/{id}", recipeHandler.GetRecipe)).Methods("GET")
+	api.Handle("/recipes/import", telemetry.WrapHandler("POST /recipes/import", recipeHandler.ImportRecipes)).Methods("POST")
+	api.Handle("/recipes/cookbook", telemetry.WrapHandler("GET /recipes/cookbook", recipeHandler.GetCookbook)).Methods("GET")
+	api.Handle("/recipes/search", telemetry.WrapHandler("GET /recipes/search", recipeHandler.SearchRecipes)).Methods("GET")
 	api.Handle("/recipes/recommend", telemetry.WrapHandler("GET /recipes/recommend", recipeHandler.RecommendRecipe)).Methods("GET")
+	api.Handle("/recipes/top", telemetry.WrapHandler("GET /recipes/top", recipeHandler.TopRecipes)).Methods("GET")
+	api.Handle("/recipes/{id}", telemetry.WrapHandler("GET /recipes/{id}", recipeHandler.GetRecipe)).Methods("GET")
+	api.Handle("/recipes/{id}", telemetry.WrapHandler("PUT /recipes/{id}", recipeHandler.UpdateRecipe)).Methods("PUT")
+	api.Handle("/recipes/{id}/cook", telemetry.WrapHandler("POST /recipes/{id}/cook", recipeHandler.CookRecipe)).Methods("POST")
+	api.Handle("/recipes/{id}/revisions", telemetry.WrapHandler("GET /recipes/{id}/revisions", recipeHandler.GetRevisions)).Methods("GET")
+	api.Handle("/recipes/{id}/revisions/diff", telemetry.WrapHandler("GET /recipes/{id}/revisions/diff", recipeHandler.DiffRevisions)).Methods("GET")
+	api.Handle("/recipes/{id}/revisions/{revision}", telemetry.WrapHandler("GET /recipes/{id}/revisions/{revision}", recipeHandler.GetRevision)).Methods("GET")
+	api.Handle("/recipes/{id}/revisions/{revision}/revert", telemetry.WrapHandler("POST /recipes/{id}/revisions/{revision}/revert", recipeHandler.RevertRecipe)).Methods("POST")
+	api.Handle("/recipes/{id}/reviews", telemetry.WrapHandler("GET /recipes/{id}/reviews", recipeHandler.GetReviews)).Methods("GET")
+	api.Handle("/recipes/{id}/reviews", telemetry.WrapHandler("POST /recipes/{id}/reviews", recipeHandler.SubmitReview)).Methods("POST")
+	api.Handle("/recipes/{id}/stats", telemetry.WrapHandler("GET /recipes/{id}/stats", recipeHandler.GetRecipeStats)).Methods("GET")
+	api.Handle("/recipes/{id}/nutrition", telemetry.WrapHandler("GET /recipes/{id}/nutrition", recipeHandler.GetNutrition)).Methods("GET")
+
+	api.Handle("/users/{user}/collections", telemetry.WrapHandler("GET /users/{user}/collections", recipeHandler.GetCollections)).Methods("GET")
+	api.Handle("/users/{user}/collections", telemetry.WrapHandler("POST /users/{user}/collections", recipeHandler.CreateCollection)).Methods("POST")
+	api.Handle("/users/{user}/collections/{id}", telemetry.WrapHandler("GET /users/{user}/collections/{id}", recipeHandler.GetCollection)).Methods("GET")
+	api.Handle("/users/{user}/collections/{id}", telemetry.WrapHandler("PUT /users/{user}/collections/{id}", recipeHandler.UpdateCollection)).Methods("PUT")
+	api.Handle("/users/{user}/collections/{id}", telemetry.WrapHandler("DELETE /users/{user}/collections/{id}", recipeHandler.DeleteCollection)).Methods("DELETE")
+	api.Handle("/users/{user}/collections/{id}/recipes", telemetry.WrapHandler("POST /users/{user}/collections/{id}/recipes", recipeHandler.AddRecipeToCollection)).Methods("POST")
+	api.Handle("/users/{user}/collections/{id}/recipes/{recipeId}", telemetry.WrapHandler("DELETE /users/{user}/collections/{id}/recipes/{recipeId}", recipeHandler.RemoveRecipeFromCollection)).Methods("DELETE")
+	api.Handle("/users/{user}/collections/{id}/order", telemetry.WrapHandler("PUT /users/{user}/collections/{id}/order", recipeHandler.ReorderCollection)).Methods("PUT")
+	api.Handle("/users/{user}/collections/{id}/share", telemetry.WrapHandler("POST /users/{user}/collections/{id}/share", recipeHandler.ShareCollection)).Methods("POST")
+	api.Handle("/users/{user}/collections/{id}/share", telemetry.WrapHandler("DELETE /users/{user}/collections/{id}/share", recipeHandler.UnshareCollection)).Methods("DELETE")
+	api.Handle("/users/{user}/collections/{id}/export", telemetry.WrapHandler("GET /users/{user}/collections/{id}/export", recipeHandler.ExportCollection)).Methods("GET")
+	api.Handle("/shared/collections/{token}", telemetry.WrapHandler("GET /shared/collections/{token}", recipeHandler.GetSharedCollection)).Methods("GET")
+	api.Handle("/shared/collections/{token}/export", telemetry.WrapHandler("GET /shared/collections/{token}/export", recipeHandler.ExportSharedCollection)).Methods("GET")
+
+	api.Handle("/meal-plans", telemetry.WrapHandler("GET /meal-plans", mealPlanHandler.GetAllMealPlans)).Methods("GET")
+	api.Handle("/meal-plans", telemetry.WrapHandler("POST /meal-plans", mealPlanHandler.CreateMealPlan)).Methods("POST")
+	api.Handle("/meal-plans/{id}", telemetry.WrapHandler("GET /meal-plans/{id}", mealPlanHandler.GetMealPlan)).Methods("GET")
+	api.Handle("/meal-plans/{id}", telemetry.WrapHandler("PUT /meal-plans/{id}", mealPlanHandler.UpdateMealPlan)).Methods("PUT")
+	api.Handle("/meal-plans/{id}", telemetry.WrapHandler("DELETE /meal-plans/{id}", mealPlanHandler.DeleteMealPlan)).Methods("DELETE")
+	api.Handle("/meal-plans/{id}/shopping-list", telemetry.WrapHandler("GET /meal-plans/{id}/shopping-list", mealPlanHandler.GetShoppingList)).Methods("GET")
 
 	api.Handle("/health", telemetry.WrapHandler("GET /health", healthCheck)).Methods("GET")
 
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// AggregateTotals sums a group of potatoes. Quarantined counts the potatoes
+// of the group held back by a recall, which are included in Count.
+type AggregateTotals struct {
+	Count       int     `json:"count"`
+	Quarantined int     `json:"quarantined"`
+	Weight      float64 `json:"weight"`
+	Value       float64 `json:"value"`
+}
+
+// Available is the number of potatoes that can still be sold or cooked.
+func (t AggregateTotals) Available() int {
+	return t.Count - t.Quarantined
+}
+
+// InventoryAggregates are stock totals kept up to date by storage on every
+// change, so summaries don't need to scan every potato.
+type InventoryAggregates struct {
+	Total      AggregateTotals            `json:"total"`
+	ByVariety  map[string]AggregateTotals `json:"by_variety"`
+	ByQuality  map[string]AggregateTotals `json:"by_quality"`
+	ByLocation map[string]AggregateTotals `json:"by_location"`
+}
+
+// AggregateDrift is one total that differs from a recount. Scope is total,
+// variety, quality or location; Key names the variety, quality or location.
+type AggregateDrift struct {
+	Scope      string  `json:"scope"`
+	Key        string  `json:"key,omitempty"`
+	Field      string  `json:"field"`
+	Maintained float64 `json:"maintained"`
+	Recomputed float64 `json:"recomputed"`
+}
+
+type ConsistencyReport struct {
+	CheckedAt  time.Time        `json:"checked_at"`
+	Consistent bool             `json:"consistent"`
+	Potatoes   int              `json:"potatoes"`
+	Drift      []AggregateDrift `json:"drift"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// Summary describes a numeric field over a set of potatoes. Percentiles are
+// interpolated between the nearest values.
+type Summary struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+}
+
+// HistogramBin counts values in [From, To). The last age bin has no upper
+// bound and leaves To unset.
+type HistogramBin struct {
+	Label string  `json:"label"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to,omitempty"`
+	Count int     `json:"count"`
+}
+
+// AnalyticsMetrics holds the optional metrics requested with ?metrics=.
+type AnalyticsMetrics struct {
+	Weight          *Summary       `json:"weight,omitempty"`
+	Price           *Summary       `json:"price,omitempty"`
+	WeightHistogram []HistogramBin `json:"weight_histogram,omitempty"`
+	PriceHistogram  []HistogramBin `json:"price_histogram,omitempty"`
+	AgeDistribution []HistogramBin `json:"age_distribution,omitempty"`
+}
+
+type AnalyticsGroup struct {
+	Key         map[string]string `json:"key"`
+	Count       int               `json:"count"`
+	TotalWeight float64           `json:"total_weight"`
+	TotalValue  float64           `json:"total_value"`
+	AnalyticsMetrics
+}
+
+type AnalyticsQuery struct {
+	GroupBy []string
+	Metrics []string
+}
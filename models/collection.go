@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Collection is a user's curated, ordered list of recipes, such as
+// "Thanksgiving sides". RecipeIDs holds the recipes in display order.
+type Collection struct {
+	ID          string    `json:"id"`
+	Owner       string    `json:"owner"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	RecipeIDs   []string  `json:"recipe_ids"`
+	ShareToken  string    `json:"share_token,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CollectionDetail struct {
+	Collection
+	Recipes []Recipe `json:"recipes"`
+}
+
+type CollectionShare struct {
+	CollectionID string `json:"collection_id"`
+	Token        string `json:"token"`
+	Path         string `json:"path"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type DegradationAction string
+
+const (
+	KeepPotato      DegradationAction = "keep"
+	DowngradePotato DegradationAction = "downgrade"
+	DiscardPotato   DegradationAction = "discard"
+)
+
+// DegradationDecision is what a degradation policy wants to do with one
+// potato. Probability is set by policies that decide at random.
+type DegradationDecision struct {
+	PotatoID    string            `json:"potato_id"`
+	Variety     string            `json:"variety"`
+	Quality     string            `json:"quality"`
+	Action      DegradationAction `json:"action"`
+	NewQuality  string            `json:"new_quality,omitempty"`
+	Reason      string            `json:"reason"`
+	Probability float64           `json:"probability,omitempty"`
+}
+
+type DegradationPreview struct {
+	Policy      string                `json:"policy"`
+	EvaluatedAt time.Time             `json:"evaluated_at"`
+	Evaluated   int                   `json:"evaluated"`
+	Decisions   []DegradationDecision `json:"decisions"`
+}
+
+// AuditEntry records a change made to stock without a user request, such as
+// a background quality downgrade.
+type AuditEntry struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Actor       string            `json:"actor"`
+	Action      DegradationAction `json:"action"`
+	PotatoID    string            `json:"potato_id"`
+	Variety     string            `json:"variety"`
+	FromQuality string            `json:"from_quality"`
+	ToQuality   string            `json:"to_quality,omitempty"`
+	Policy      string            `json:"policy"`
+	Reason      string            `json:"reason"`
+}
+
+type AuditFilter struct {
+	PotatoID string
+	Action   DegradationAction
+	Limit    int
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// DailyDemand counts the potatoes of a variety sold or cooked on one UTC
+// day.
+type DailyDemand struct {
+	Variety  string
+	Day      time.Time
+	Quantity int
+}
+
+type DemandPoint struct {
+	Date     string  `json:"date"`
+	Quantity float64 `json:"quantity"`
+}
+
+// ForecastPoint gives both forecasts for one day. HoltWinters is missing
+// until there are two weeks of history.
+type ForecastPoint struct {
+	Date          string   `json:"date"`
+	MovingAverage float64  `json:"moving_average"`
+	HoltWinters   *float64 `json:"holt_winters,omitempty"`
+}
+
+// DemandForecast is a variety's daily demand in potatoes, with the reorder
+// point and order quantity derived from the forecast named by Method.
+type DemandForecast struct {
+	Variety                string          `json:"variety"`
+	Method                 string          `json:"method"`
+	AverageDailyDemand     float64         `json:"average_daily_demand"`
+	StdDevDailyDemand      float64         `json:"stddev_daily_demand"`
+	OnHand                 int             `json:"on_hand"`
+	DaysOfCover            *float64        `json:"days_of_cover,omitempty"`
+	LeadTimeDays           int             `json:"lead_time_days"`
+	ReviewDays             int             `json:"review_days"`
+	SafetyStock            float64         `json:"safety_stock"`
+	ReorderPoint           float64         `json:"reorder_point"`
+	SuggestedOrderQuantity int             `json:"suggested_order_quantity"`
+	History                []DemandPoint   `json:"history"`
+	Forecast               []ForecastPoint `json:"forecast"`
+}
+
+type AlertSeverity string
+
+const (
+	AlertWarning  AlertSeverity = "warning"
+	AlertCritical AlertSeverity = "critical"
+)
+
+// StockAlert is raised when a variety's stock falls to its reorder point.
+// It is critical when stock is expected to run out before an order placed
+// now could arrive.
+type StockAlert struct {
+	Variety                string        `json:"variety"`
+	Severity               AlertSeverity `json:"severity"`
+	OnHand                 int           `json:"on_hand"`
+	ReorderPoint           float64       `json:"reorder_point"`
+	SuggestedOrderQuantity int           `json:"suggested_order_quantity"`
+	Message                string        `json:"message"`
+	RaisedAt               time.Time     `json:"raised_at"`
+}
+
+// CookEvent records potatoes taken from stock to cook a recipe. LotIDs
+// lists the harvest lots they came from.
+type CookEvent struct {
+	RecipeID  string    `json:"recipe_id"`
+	Variety   string    `json:"variety"`
+	Potatoes  int       `json:"potatoes"`
+	PotatoIDs []string  `json:"potato_ids"`
+	LotIDs    []string  `json:"lot_ids,omitempty"`
+	CookedAt  time.Time `json:"cooked_at"`
+}
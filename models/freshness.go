@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// FreshnessReport is the outcome of applying the freshness rules to one
+// potato. Rule names the rules that decided the result, most specific last.
+type FreshnessReport struct {
+	ID                     string    `json:"id"`
+	Variety                string    `json:"variety"`
+	Freshness              string    `json:"freshness"`
+	Score                  float64   `json:"score"`
+	StorageCondition       string    `json:"storage_condition"`
+	AgeDays                int       `json:"age_days"`
+	ShelfLifeDays          int       `json:"shelf_life_days"`
+	RemainingShelfLifeDays int       `json:"remaining_shelf_life_days"`
+	ExpiryDate             time.Time `json:"expiry_date"`
+	Rules                  []string  `json:"rules"`
+}
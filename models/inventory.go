@@ -8,16 +8,26 @@ type InventoryItem struct {
 }
 
 type InventorySummary struct {
-	TotalPotatoes int              `json:"total_potatoes"`
-	TotalWeight   float64          `json:"total_weight"`
-	TotalValue    float64          `json:"total_value"`
-	ByVariety     []InventoryItem  `json:"by_variety"`
+	TotalPotatoes int                  `json:"total_potatoes"`
+	TotalWeight   float64              `json:"total_weight"`
+	TotalValue    float64              `json:"total_value"`
+	ByVariety     []InventoryItem      `json:"by_variety"`
+	Warehouse     string               `json:"warehouse,omitempty"`
+	ByWarehouse   []WarehouseInventory `json:"by_warehouse"`
 }
 
+// PotatoAnalytics names every variety tied for most stock in
+// MostPopularVarieties; MostPopularVariety is the first of them
+// alphabetically.
 type PotatoAnalytics struct {
-	MostPopularVariety string  `json:"most_popular_variety"`
-	AverageWeight      float64 `json:"average_weight"`
-	PremiumPercentage  float64 `json:"premium_percentage"`
-	TotalValue         float64 `json:"total_value"`
+	MostPopularVariety   string   `json:"most_popular_variety"`
+	MostPopularVarieties []string `json:"most_popular_varieties,omitempty"`
+	TotalPotatoes        int      `json:"total_potatoes"`
+	AverageWeight        float64  `json:"average_weight"`
+	PremiumPercentage    float64  `json:"premium_percentage"`
+	TotalValue           float64  `json:"total_value"`
+	AnalyticsMetrics
+	GroupBy []string         `json:"group_by,omitempty"`
+	Groups  []AnalyticsGroup `json:"groups,omitempty"`
 }
 
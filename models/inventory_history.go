@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type InventoryTrendQuery struct {
+	Variety    string
+	Quality    string
+	From       time.Time
+	To         time.Time
+	Resolution string
+}
+
+// InventoryTrendPoint averages the snapshots taken in one interval. Count
+// is therefore fractional when stock changed during the interval.
+type InventoryTrendPoint struct {
+	Time      time.Time `json:"time"`
+	Snapshots int       `json:"snapshots"`
+	Count     float64   `json:"count"`
+	WeightKg  float64   `json:"weight_kg"`
+	Value     float64   `json:"value"`
+}
+
+type InventoryTrend struct {
+	Variety    string                `json:"variety,omitempty"`
+	Quality    string                `json:"quality,omitempty"`
+	Resolution string                `json:"resolution"`
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	Points     []InventoryTrendPoint `json:"points"`
+}
+
+// SnapshotTier describes one resolution the inventory history keeps.
+type SnapshotTier struct {
+	Resolution string `json:"resolution"`
+	Step       string `json:"step"`
+	Retention  string `json:"retention"`
+}
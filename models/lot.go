@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type LotStatus string
+
+const (
+	LotActive   LotStatus = "active"
+	LotRecalled LotStatus = "recalled"
+)
+
+// HarvestLot is a batch of potatoes harvested from one field on one day.
+// Potatoes linked to a lot share its harvest date.
+type HarvestLot struct {
+	ID            string     `json:"id"`
+	Farm          string     `json:"farm"`
+	Field         string     `json:"field"`
+	Origin        string     `json:"origin,omitempty"`
+	HarvestDate   time.Time  `json:"harvest_date"`
+	Certification string     `json:"certification,omitempty"`
+	Status        LotStatus  `json:"status"`
+	RecalledAt    *time.Time `json:"recalled_at,omitempty"`
+	RecallReason  string     `json:"recall_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// LotTrace follows a lot downstream: every potato received from it, those
+// still in stock, how the rest left and the recipes they were cooked in.
+type LotTrace struct {
+	Lot         HarvestLot            `json:"lot"`
+	Received    int                   `json:"received"`
+	InStock     []Potato              `json:"in_stock"`
+	Quarantined int                   `json:"quarantined"`
+	Removed     []StockMovement       `json:"removed"`
+	ByReason    map[RemovalReason]int `json:"removed_by_reason"`
+	CookEvents  []CookEvent           `json:"cook_events"`
+	RecipeIDs   []string              `json:"recipe_ids"`
+}
+
+type RecallRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RecallResult lists the potatoes a recall quarantined.
+type RecallResult struct {
+	Lot         HarvestLot `json:"lot"`
+	Quarantined []string   `json:"quarantined"`
+}
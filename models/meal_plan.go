@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type MealType string
+
+const (
+	Breakfast MealType = "Breakfast"
+	Lunch     MealType = "Lunch"
+	Dinner    MealType = "Dinner"
+	Snack     MealType = "Snack"
+)
+
+type PlannedMeal struct {
+	Day      string   `json:"day"`
+	Meal     MealType `json:"meal"`
+	RecipeID string   `json:"recipe_id"`
+	Servings int      `json:"servings"`
+}
+
+type MealPlan struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Meals     []PlannedMeal `json:"meals"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type ShoppingListItem struct {
+	Name     string   `json:"name"`
+	Quantity float64  `json:"quantity,omitempty"`
+	Unit     string   `json:"unit,omitempty"`
+	Text     string   `json:"text"`
+	Recipes  []string `json:"recipes"`
+}
+
+type PotatoRequirement struct {
+	Variety  string  `json:"variety"`
+	Required float64 `json:"required"`
+	InStock  float64 `json:"in_stock"`
+	ToBuy    float64 `json:"to_buy"`
+	Unit     string  `json:"unit"`
+}
+
+type ShoppingList struct {
+	MealPlanID string              `json:"meal_plan_id"`
+	Units      UnitSystem          `json:"units"`
+	Potatoes   []PotatoRequirement `json:"potatoes"`
+	Items      []ShoppingListItem  `json:"items"`
+	ToTaste    []ShoppingListItem  `json:"to_taste"`
+}
@@ -0,0 +1,35 @@
+package models
+
+type NutritionFacts struct {
+	Calories float64 `json:"calories"`
+	Carbs    float64 `json:"carbs_g"`
+	Protein  float64 `json:"protein_g"`
+	Fiber    float64 `json:"fiber_g"`
+}
+
+type NutritionStatus string
+
+const (
+	NutritionComputed     NutritionStatus = "computed"
+	NutritionToTaste      NutritionStatus = "to_taste"
+	NutritionUnmeasured   NutritionStatus = "unmeasured"
+	NutritionUnrecognized NutritionStatus = "unrecognized"
+)
+
+type IngredientNutrition struct {
+	Text    string          `json:"text"`
+	Matched string          `json:"matched,omitempty"`
+	Grams   float64         `json:"grams,omitempty"`
+	Status  NutritionStatus `json:"status"`
+	Facts   NutritionFacts  `json:"facts"`
+}
+
+type NutritionReport struct {
+	RecipeID     string                `json:"recipe_id"`
+	Servings     int                   `json:"servings"`
+	Total        NutritionFacts        `json:"total"`
+	PerServing   NutritionFacts        `json:"per_serving"`
+	Complete     bool                  `json:"complete"`
+	Unrecognized []string              `json:"unrecognized"`
+	Ingredients  []IngredientNutrition `json:"ingredients"`
+}
@@ -3,26 +3,20 @@ package models
 import "time"
 
 type Potato struct {
-	ID          string    `json:"id"`
-	Variety     string    `json:"variety"`
-	Origin      string    `json:"origin"`
-	Weight      float64   `json:"weight"`
-	Quality     string    `json:"quality"`
-	HarvestDate time.Time `json:"harvest_date"`
-	Price       float64   `json:"price"`
+	ID               string    `json:"id"`
+	Variety          string    `json:"variety"`
+	Origin           string    `json:"origin"`
+	Weight           float64   `json:"weight"`
+	Quality          string    `json:"quality"`
+	HarvestDate      time.Time `json:"harvest_date"`
+	Price            float64   `json:"price"`
+	PurchaseCost     float64   `json:"purchase_cost,omitempty"`
+	StorageCondition string    `json:"storage_condition,omitempty"`
+	LocationID       string    `json:"location_id,omitempty"`
+	LotID            string    `json:"lot_id,omitempty"`
+	Quarantined      bool      `json:"quarantined,omitempty"`
 }
 
-type PotatoVariety string
-
-const (
-	Russet       PotatoVariety = "Russet"
-	Yukon        PotatoVariety = "Yukon Gold"
-	RedPotato    PotatoVariety = "Red Potato"
-	Fingerling   PotatoVariety = "Fingerling"
-	SweetPotato  PotatoVariety = "Sweet Potato"
-	PurplePotato PotatoVariety = "Purple Potato"
-)
-
 type Quality string
 
 const (
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// PriceRecord is one price a potato was listed at, from RecordedAt until
+// the next record. The first record for a potato is its price when added.
+type PriceRecord struct {
+	PotatoID   string    `json:"potato_id"`
+	Variety    string    `json:"variety"`
+	Quality    string    `json:"quality"`
+	OldPrice   float64   `json:"old_price,omitempty"`
+	Price      float64   `json:"price"`
+	PricePerKg float64   `json:"price_per_kg"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// DailyPrice aggregates the per-kg prices recorded for a variety on one UTC
+// day, in the order they were recorded.
+type DailyPrice struct {
+	Variety string    `json:"variety"`
+	Day     time.Time `json:"day"`
+	Open    float64   `json:"open"`
+	High    float64   `json:"high"`
+	Low     float64   `json:"low"`
+	Close   float64   `json:"close"`
+	Total   float64   `json:"total"`
+	Count   int       `json:"count"`
+}
+
+type PriceSeriesType string
+
+const (
+	OHLCSeries    PriceSeriesType = "ohlc"
+	AverageSeries PriceSeriesType = "average"
+)
+
+type PriceInterval string
+
+const (
+	DailyInterval   PriceInterval = "day"
+	WeeklyInterval  PriceInterval = "week"
+	MonthlyInterval PriceInterval = "month"
+)
+
+type PriceSeriesQuery struct {
+	Variety  string
+	From     time.Time
+	To       time.Time
+	Interval PriceInterval
+	Type     PriceSeriesType
+}
+
+// PricePoint is one interval of a price series. Open, High, Low and Close
+// are only set for OHLC series.
+type PricePoint struct {
+	Time    time.Time `json:"time"`
+	Label   string    `json:"label"`
+	Open    float64   `json:"open,omitempty"`
+	High    float64   `json:"high,omitempty"`
+	Low     float64   `json:"low,omitempty"`
+	Close   float64   `json:"close,omitempty"`
+	Average float64   `json:"average"`
+	Changes int       `json:"changes"`
+}
+
+type PriceSeries struct {
+	Variety string       `json:"variety"`
+	Points  []PricePoint `json:"points"`
+}
+
+type PriceAnalytics struct {
+	Type     PriceSeriesType `json:"type"`
+	Interval PriceInterval   `json:"interval"`
+	Metric   string          `json:"metric"`
+	Currency string          `json:"currency"`
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Series   []PriceSeries   `json:"series"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PriceStep is one rule applied while pricing a potato. Result is the
+// running value after the step: a price per kg until the weight is applied,
+// the item price afterwards.
+type PriceStep struct {
+	Rule        string  `json:"rule"`
+	Description string  `json:"description"`
+	Result      float64 `json:"result"`
+}
+
+type PriceQuote struct {
+	PotatoID     string      `json:"potato_id"`
+	Variety      string      `json:"variety"`
+	Quality      string      `json:"quality"`
+	WeightKg     float64     `json:"weight_kg"`
+	Freshness    string      `json:"freshness"`
+	Currency     string      `json:"currency"`
+	PricePerKg   float64     `json:"price_per_kg"`
+	Price        float64     `json:"price"`
+	CurrentPrice float64     `json:"current_price"`
+	Promotion    string      `json:"promotion,omitempty"`
+	Steps        []PriceStep `json:"steps"`
+	QuotedAt     time.Time   `json:"quoted_at"`
+}
+
+type PriceChange struct {
+	PotatoID string  `json:"potato_id"`
+	Variety  string  `json:"variety"`
+	OldPrice float64 `json:"old_price"`
+	NewPrice float64 `json:"new_price"`
+}
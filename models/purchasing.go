@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+// Supplier is a farm or co-op we buy potatoes from. LeadTimeDays is how long
+// its deliveries usually take and sets the default expected date of a
+// purchase order.
+type Supplier struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Origin       string    `json:"origin,omitempty"`
+	Contact      string    `json:"contact,omitempty"`
+	LeadTimeDays int       `json:"lead_time_days"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderOpen     PurchaseOrderStatus = "open"
+	PurchaseOrderPartial  PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived PurchaseOrderStatus = "received"
+	PurchaseOrderClosed   PurchaseOrderStatus = "closed"
+)
+
+// PurchaseOrderLine orders a weight of one variety at one quality.
+type PurchaseOrderLine struct {
+	Line          int     `json:"line"`
+	Variety       string  `json:"variety"`
+	Quality       string  `json:"quality"`
+	WeightKg      float64 `json:"weight_kg"`
+	UnitCostPerKg float64 `json:"unit_cost_per_kg"`
+	ReceivedKg    float64 `json:"received_kg"`
+	ReceivedCount int     `json:"received_count"`
+}
+
+type PurchaseOrder struct {
+	ID           string              `json:"id"`
+	SupplierID   string              `json:"supplier_id"`
+	Status       PurchaseOrderStatus `json:"status"`
+	Lines        []PurchaseOrderLine `json:"lines"`
+	OrderedKg    float64             `json:"ordered_kg"`
+	ReceivedKg   float64             `json:"received_kg"`
+	TotalCost    float64             `json:"total_cost"`
+	OrderedAt    time.Time           `json:"ordered_at"`
+	ExpectedDate time.Time           `json:"expected_date"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty"`
+	ClosedAt     *time.Time          `json:"closed_at,omitempty"`
+	Note         string              `json:"note,omitempty"`
+}
+
+type PurchaseOrderFilter struct {
+	SupplierID string
+	Status     PurchaseOrderStatus
+}
+
+// ReceivedPotato is one potato delivered against an order line. Quality
+// defaults to the quality ordered.
+type ReceivedPotato struct {
+	Weight  float64 `json:"weight"`
+	Quality string  `json:"quality,omitempty"`
+}
+
+type ReceiptLineRequest struct {
+	Line     int              `json:"line"`
+	Potatoes []ReceivedPotato `json:"potatoes"`
+}
+
+// ReceiptRequest records a delivery against a purchase order. The potatoes
+// go to LocationID, or to the location with the most free space, and are
+// linked to LotID when it is set.
+type ReceiptRequest struct {
+	Lines       []ReceiptLineRequest `json:"lines"`
+	LotID       string               `json:"lot_id,omitempty"`
+	LocationID  string               `json:"location_id,omitempty"`
+	HarvestDate time.Time            `json:"harvest_date"`
+	ReceivedAt  *time.Time           `json:"received_at,omitempty"`
+}
+
+// QualityShare is how many potatoes, and how much weight, arrived at one
+// quality. Percent is the share of potatoes.
+type QualityShare struct {
+	Quality  string  `json:"quality"`
+	Potatoes int     `json:"potatoes"`
+	WeightKg float64 `json:"weight_kg"`
+	Percent  float64 `json:"percent,omitempty"`
+}
+
+// ReceiptLine is what one delivery brought in for one order line.
+// BelowSpec counts potatoes delivered below the quality ordered.
+type ReceiptLine struct {
+	Line      int            `json:"line"`
+	Variety   string         `json:"variety"`
+	Ordered   string         `json:"ordered_quality"`
+	Potatoes  int            `json:"potatoes"`
+	WeightKg  float64        `json:"weight_kg"`
+	Cost      float64        `json:"cost"`
+	BelowSpec int            `json:"below_spec"`
+	Qualities []QualityShare `json:"qualities"`
+	PotatoIDs []string       `json:"potato_ids"`
+}
+
+// Receipt is one delivery against a purchase order. It is on time when it
+// arrives by the end of the order's expected date.
+type Receipt struct {
+	ID              string        `json:"id"`
+	PurchaseOrderID string        `json:"purchase_order_id"`
+	SupplierID      string        `json:"supplier_id"`
+	ReceivedAt      time.Time     `json:"received_at"`
+	OnTime          bool          `json:"on_time"`
+	LotID           string        `json:"lot_id,omitempty"`
+	Lines           []ReceiptLine `json:"lines"`
+}
+
+// SupplierPerformance rates a supplier on its deliveries. Rates are
+// percentages and are 0 until there is something to rate.
+type SupplierPerformance struct {
+	Supplier            Supplier       `json:"supplier"`
+	Orders              int            `json:"orders"`
+	OpenOrders          int            `json:"open_orders"`
+	Deliveries          int            `json:"deliveries"`
+	OnTimeDeliveries    int            `json:"on_time_deliveries"`
+	OnTimeRate          float64        `json:"on_time_rate"`
+	AverageLeadTimeDays float64        `json:"average_lead_time_days"`
+	OrderedKg           float64        `json:"ordered_kg"`
+	ReceivedKg          float64        `json:"received_kg"`
+	FillRate            float64        `json:"fill_rate"`
+	Potatoes            int            `json:"potatoes"`
+	BelowSpecRate       float64        `json:"below_spec_rate"`
+	Quality             []QualityShare `json:"quality_distribution"`
+}
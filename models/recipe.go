@@ -1,23 +1,106 @@
 package models
 
+import "time"
+
 type Recipe struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Variety      string   `json:"variety"`
-	CookingTime  int      `json:"cooking_time"`
-	Difficulty   string   `json:"difficulty"`
-	Ingredients  []string `json:"ingredients"`
-	Instructions []string `json:"instructions"`
-	Servings     int      `json:"servings"`
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Variety       string        `json:"variety"`
+	CookingTime   int           `json:"cooking_time"`
+	Difficulty    string        `json:"difficulty"`
+	Ingredients   []string      `json:"ingredients"`
+	Instructions  []string      `json:"instructions"`
+	Servings      int           `json:"servings"`
+	CookingMethod CookingMethod `json:"cooking_method,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+	Dietary       []DietaryFlag `json:"dietary,omitempty"`
+	Allergens     []Allergen    `json:"allergens"`
+	Revision      int           `json:"revision"`
+	UpdatedBy     string        `json:"updated_by,omitempty"`
+	UpdatedAt     time.Time     `json:"updated_at"`
 }
 
 type CookingMethod string
 
 const (
-	Baked  CookingMethod = "Baked"
-	Fried  CookingMethod = "Fried"
-	Mashed CookingMethod = "Mashed"
-	Boiled CookingMethod = "Boiled"
+	Baked   CookingMethod = "Baked"
+	Fried   CookingMethod = "Fried"
+	Mashed  CookingMethod = "Mashed"
+	Boiled  CookingMethod = "Boiled"
 	Roasted CookingMethod = "Roasted"
 )
 
+var CookingMethods = []CookingMethod{Baked, Fried, Mashed, Boiled, Roasted}
+
+type DietaryFlag string
+
+const (
+	Vegan      DietaryFlag = "vegan"
+	GlutenFree DietaryFlag = "gluten-free"
+	DairyFree  DietaryFlag = "dairy-free"
+)
+
+var DietaryFlags = []DietaryFlag{Vegan, GlutenFree, DairyFree}
+
+type Allergen string
+
+const (
+	AllergenDairy     Allergen = "dairy"
+	AllergenGluten    Allergen = "gluten"
+	AllergenEggs      Allergen = "eggs"
+	AllergenPeanuts   Allergen = "peanuts"
+	AllergenTreeNuts  Allergen = "tree-nuts"
+	AllergenSoy       Allergen = "soy"
+	AllergenFish      Allergen = "fish"
+	AllergenShellfish Allergen = "shellfish"
+	AllergenSesame    Allergen = "sesame"
+	AllergenMustard   Allergen = "mustard"
+)
+
+var Allergens = []Allergen{
+	AllergenDairy, AllergenGluten, AllergenEggs, AllergenPeanuts, AllergenTreeNuts,
+	AllergenSoy, AllergenFish, AllergenShellfish, AllergenSesame, AllergenMustard,
+}
+
+type RecipeFilter struct {
+	Variety          string
+	CookingMethod    CookingMethod
+	Tags             []string
+	Dietary          []DietaryFlag
+	ExcludeAllergens []Allergen
+}
+
+type UnitSystem string
+
+const (
+	Metric   UnitSystem = "metric"
+	Imperial UnitSystem = "imperial"
+)
+
+type Ingredient struct {
+	Quantity float64 `json:"quantity,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Name     string  `json:"name"`
+	Text     string  `json:"text"`
+}
+
+type ScaledRecipe struct {
+	Recipe
+	Scaling RecipeScaling `json:"scaling"`
+}
+
+type RecipeScaling struct {
+	OriginalServings    int          `json:"original_servings"`
+	Factor              float64      `json:"factor"`
+	Units               UnitSystem   `json:"units,omitempty"`
+	OriginalCookingTime int          `json:"original_cooking_time"`
+	CookingTimeHint     string       `json:"cooking_time_hint,omitempty"`
+	Ingredients         []Ingredient `json:"ingredients"`
+}
+
+type RecipeSearchResult struct {
+	Recipe       Recipe              `json:"recipe"`
+	Score        float64             `json:"score"`
+	MatchedTerms []string            `json:"matched_terms"`
+	Highlights   map[string][]string `json:"highlights"`
+}
@@ -0,0 +1,44 @@
+package models
+
+// RecipeJSONLD is the schema.org Recipe representation used for export.
+// Import accepts a much looser shape; see service.ImportRecipesJSONLD.
+type RecipeJSONLD struct {
+	Context            string                `json:"@context"`
+	Type               string                `json:"@type"`
+	Identifier         string                `json:"identifier"`
+	Name               string                `json:"name"`
+	RecipeIngredient   []string              `json:"recipeIngredient"`
+	RecipeInstructions []HowToStep           `json:"recipeInstructions"`
+	TotalTime          string                `json:"totalTime,omitempty"`
+	RecipeYield        string                `json:"recipeYield,omitempty"`
+	Keywords           string                `json:"keywords,omitempty"`
+	CookingMethod      string                `json:"cookingMethod,omitempty"`
+	EducationalLevel   string                `json:"educationalLevel,omitempty"`
+	SuitableForDiet    []string              `json:"suitableForDiet,omitempty"`
+	Nutrition          *NutritionInformation `json:"nutrition,omitempty"`
+	DateModified       string                `json:"dateModified,omitempty"`
+}
+
+type HowToStep struct {
+	Type string `json:"@type"`
+	Text string `json:"text"`
+}
+
+type NutritionInformation struct {
+	Type                string `json:"@type"`
+	Calories            string `json:"calories"`
+	CarbohydrateContent string `json:"carbohydrateContent"`
+	ProteinContent      string `json:"proteinContent"`
+	FiberContent        string `json:"fiberContent"`
+}
+
+type RecipeImportFailure struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+type RecipeImportResult struct {
+	Imported []Recipe              `json:"imported"`
+	Failed   []RecipeImportFailure `json:"failed"`
+}
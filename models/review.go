@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type Review struct {
+	ID        string    `json:"id"`
+	RecipeID  string    `json:"recipe_id"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RecipeStats struct {
+	RecipeID      string  `json:"recipe_id"`
+	Name          string  `json:"name"`
+	Variety       string  `json:"variety"`
+	AverageRating float64 `json:"average_rating"`
+	RatingCount   int     `json:"rating_count"`
+	ViewCount     int     `json:"view_count"`
+	Score         float64 `json:"score"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type RecipeRevision struct {
+	RecipeID  string    `json:"recipe_id"`
+	Revision  int       `json:"revision"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	Recipe    Recipe    `json:"recipe"`
+}
+
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffAdd    DiffOp = "add"
+	DiffRemove DiffOp = "remove"
+)
+
+type LineDiff struct {
+	Op   DiffOp `json:"op"`
+	Line string `json:"line"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RecipeDiff struct {
+	RecipeID     string        `json:"recipe_id"`
+	From         int           `json:"from"`
+	To           int           `json:"to"`
+	Fields       []FieldChange `json:"fields"`
+	Ingredients  []LineDiff    `json:"ingredients"`
+	Instructions []LineDiff    `json:"instructions"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+type StockMovementType string
+
+const (
+	StockReceipt    StockMovementType = "receipt"
+	StockRemoval    StockMovementType = "removal"
+	StockAdjustment StockMovementType = "adjustment"
+)
+
+// StockMovement records a potato entering or leaving stock. PurchaseCost is
+// what was paid for the whole potato; removals also record its sale price
+// and why it left. An adjustment corrects a potato in stock and carries its
+// new variety, weight and cost.
+type StockMovement struct {
+	Type         StockMovementType `json:"type"`
+	PotatoID     string            `json:"potato_id"`
+	Variety      string            `json:"variety"`
+	Quality      string            `json:"quality,omitempty"`
+	LotID        string            `json:"lot_id,omitempty"`
+	WeightKg     float64           `json:"weight_kg"`
+	PurchaseCost float64           `json:"purchase_cost"`
+	Price        float64           `json:"price,omitempty"`
+	Reason       RemovalReason     `json:"reason,omitempty"`
+	Timestamp    time.Time         `json:"timestamp"`
+}
+
+// CostLayer is the kilograms left of one receipt in a FIFO queue.
+type CostLayer struct {
+	PotatoID  string
+	Kg        float64
+	CostPerKg float64
+}
+
+// OpeningBalance is a variety's valuation state at a checkpoint: its FIFO
+// layers, oldest first, and its weighted-average pool.
+type OpeningBalance struct {
+	Variety  string
+	Layers   []CostLayer
+	PoolKg   float64
+	PoolCost float64
+}
+
+// StockCheckpoint is the state of the stock ledger at Timestamp, kept when
+// older movements are dropped so that valuation can carry on from it.
+// InStock holds each potato on hand as last received or adjusted.
+type StockCheckpoint struct {
+	Timestamp time.Time
+	InStock   []StockMovement
+	Balances  []OpeningBalance
+}
+
+type ValuationMethod string
+
+const (
+	FIFOValuation          ValuationMethod = "fifo"
+	WeightedAverage        ValuationMethod = "weighted_average"
+	SpecificIdentification ValuationMethod = "specific_identification"
+)
+
+var ValuationMethods = []ValuationMethod{FIFOValuation, WeightedAverage, SpecificIdentification}
+
+type MethodValuation struct {
+	Method             ValuationMethod `json:"method"`
+	InventoryValue     float64         `json:"inventory_value"`
+	CostOfGoodsRemoved float64         `json:"cost_of_goods_removed"`
+}
+
+type VarietyValuation struct {
+	Variety      string            `json:"variety"`
+	OnHandCount  int               `json:"on_hand_count"`
+	OnHandKg     float64           `json:"on_hand_kg"`
+	RemovedCount int               `json:"removed_count"`
+	RemovedKg    float64           `json:"removed_kg"`
+	Valuations   []MethodValuation `json:"valuations"`
+}
+
+type ValuationQuery struct {
+	AsOf    time.Time
+	From    time.Time
+	Variety string
+	Methods []ValuationMethod
+}
+
+// ValuationReport values the stock on hand at AsOf and costs the stock
+// removed between From and AsOf. Uncosted counts potatoes on hand without a
+// purchase cost, which are valued at zero. LedgerStart is set once old
+// movements have been dropped: removals before it are no longer costed.
+type ValuationReport struct {
+	AsOf        time.Time          `json:"as_of"`
+	From        *time.Time         `json:"from,omitempty"`
+	LedgerStart *time.Time         `json:"ledger_start,omitempty"`
+	Currency    string             `json:"currency"`
+	Uncosted    int                `json:"uncosted"`
+	Varieties   []VarietyValuation `json:"varieties"`
+	Totals      []MethodValuation  `json:"totals"`
+}
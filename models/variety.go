@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type StarchLevel string
+
+const (
+	LowStarch    StarchLevel = "low"
+	MediumStarch StarchLevel = "medium"
+	HighStarch   StarchLevel = "high"
+)
+
+var StarchLevels = []StarchLevel{LowStarch, MediumStarch, HighStarch}
+
+// Variety is an entry in the managed variety catalog. Potatoes and recipes
+// must name a catalogued variety; aliases are accepted on input and stored as
+// the canonical Name. BasePrice is per kilogram at Standard quality.
+type Variety struct {
+	Name               string          `json:"name"`
+	Aliases            []string        `json:"aliases"`
+	StarchLevel        StarchLevel     `json:"starch_level"`
+	BestCookingMethods []CookingMethod `json:"best_cooking_methods"`
+	ShelfLifeDays      int             `json:"shelf_life_days"`
+	BasePrice          float64         `json:"base_price"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
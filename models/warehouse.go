@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type Warehouse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Location is a bin within a warehouse. Potatoes stored there take its
+// StorageCondition when it has one. CapacityKg caps the weight it holds.
+type Location struct {
+	ID               string    `json:"id"`
+	WarehouseID      string    `json:"warehouse_id"`
+	Name             string    `json:"name"`
+	StorageCondition string    `json:"storage_condition,omitempty"`
+	CapacityKg       float64   `json:"capacity_kg"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// LocationStock is a location with the stock it currently holds.
+type LocationStock struct {
+	Location
+	Potatoes    int     `json:"potatoes"`
+	UsedKg      float64 `json:"used_kg"`
+	FreeKg      float64 `json:"free_kg"`
+	Utilization float64 `json:"utilization"`
+}
+
+type WarehouseStock struct {
+	Warehouse
+	Potatoes    int             `json:"potatoes"`
+	UsedKg      float64         `json:"used_kg"`
+	CapacityKg  float64         `json:"capacity_kg"`
+	Utilization float64         `json:"utilization"`
+	Locations   []LocationStock `json:"locations"`
+}
+
+// Transfer records one potato moved between locations.
+type Transfer struct {
+	ID             string    `json:"id"`
+	Timestamp      time.Time `json:"timestamp"`
+	Actor          string    `json:"actor"`
+	PotatoID       string    `json:"potato_id"`
+	Variety        string    `json:"variety"`
+	WeightKg       float64   `json:"weight_kg"`
+	FromLocationID string    `json:"from_location_id"`
+	ToLocationID   string    `json:"to_location_id"`
+	Reason         string    `json:"reason,omitempty"`
+}
+
+type TransferRequest struct {
+	PotatoIDs    []string `json:"potato_ids"`
+	ToLocationID string   `json:"to_location_id"`
+	Actor        string   `json:"actor"`
+	Reason       string   `json:"reason"`
+}
+
+type TransferFilter struct {
+	PotatoID   string
+	LocationID string
+	Limit      int
+}
+
+// WarehouseInventory is a warehouse's share of the inventory summary.
+type WarehouseInventory struct {
+	WarehouseID   string  `json:"warehouse_id"`
+	Name          string  `json:"name"`
+	TotalPotatoes int     `json:"total_potatoes"`
+	TotalWeight   float64 `json:"total_weight"`
+	TotalValue    float64 `json:"total_value"`
+	CapacityKg    float64 `json:"capacity_kg"`
+	Utilization   float64 `json:"utilization"`
+}
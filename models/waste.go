@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+type RemovalReason string
+
+const (
+	RemovalSold     RemovalReason = "sold"
+	RemovalCooked   RemovalReason = "cooked"
+	RemovalSpoiled  RemovalReason = "spoiled"
+	RemovalDamaged  RemovalReason = "damaged"
+	RemovalRecalled RemovalReason = "recalled"
+)
+
+var RemovalReasons = []RemovalReason{RemovalSold, RemovalCooked, RemovalSpoiled, RemovalDamaged, RemovalRecalled}
+
+// IsWaste reports whether potatoes removed for this reason were lost rather
+// than used.
+func (r RemovalReason) IsWaste() bool {
+	return r == RemovalSpoiled || r == RemovalDamaged || r == RemovalRecalled
+}
+
+// WasteTotals sums wasted potatoes. CostLost is what was paid for them,
+// ValueLost what they were listed at.
+type WasteTotals struct {
+	Count     int     `json:"count"`
+	WeightKg  float64 `json:"weight_kg"`
+	CostLost  float64 `json:"cost_lost"`
+	ValueLost float64 `json:"value_lost"`
+}
+
+type VarietyWaste struct {
+	Variety  string                        `json:"variety"`
+	ByReason map[RemovalReason]WasteTotals `json:"by_reason"`
+	WasteTotals
+}
+
+type WasteQuery struct {
+	Variety string
+	Reason  RemovalReason
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+// WasteReport totals waste per variety and lists the most recent entries,
+// newest first.
+type WasteReport struct {
+	From      *time.Time      `json:"from,omitempty"`
+	To        time.Time       `json:"to"`
+	Total     WasteTotals     `json:"total"`
+	Varieties []VarietyWaste  `json:"varieties"`
+	Entries   []StockMovement `json:"entries"`
+}
+
+// SpoilageRisk is a potato expected to go Old before it is used up.
+// ExpectedUseDate is missing when its variety has no recent demand.
+type SpoilageRisk struct {
+	PotatoID        string     `json:"potato_id"`
+	Variety         string     `json:"variety"`
+	Quality         string     `json:"quality"`
+	WeightKg        float64    `json:"weight_kg"`
+	Freshness       string     `json:"freshness"`
+	ExpiryDate      time.Time  `json:"expiry_date"`
+	ExpectedUseDate *time.Time `json:"expected_use_date,omitempty"`
+	PurchaseCost    float64    `json:"purchase_cost"`
+	Price           float64    `json:"price"`
+}
+
+type SpoilagePrediction struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	AtRisk      int            `json:"at_risk"`
+	WeightKg    float64        `json:"weight_kg"`
+	CostAtRisk  float64        `json:"cost_at_risk"`
+	ValueAtRisk float64        `json:"value_at_risk"`
+	Potatoes    []SpoilageRisk `json:"potatoes"`
+}
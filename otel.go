@@ -8,6 +8,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -59,10 +60,20 @@ type Observability struct {
 	inventoryLevel  metric.Int64Gauge
 	potatoFreshness metric.Float64Histogram
 	recipeViews     metric.Int64Counter
+	stockAlerts     metric.Int64Counter
+	removals        metric.Int64Counter
+	wasteWeight     metric.Float64Counter
+	wasteCost       metric.Float64Counter
 
 	logger      logapi.Logger
 	serviceName string
 
+	// Catalog used to bound the potato.variety metric label, and the
+	// catalog names labelled so far
+	varieties     VarietyCatalog
+	varietyMu     sync.Mutex
+	varietyLabels map[string]bool
+
 	// Common attributes to attach to all metrics
 	commonAttrs []attribute.KeyValue
 }
@@ -179,6 +190,39 @@ func initOpenTelemetry(ctx context.Context) (*Observability, error) {
 		return nil, fmt.Errorf("create recipe views counter: %w", err)
 	}
 
+	stockAlerts, err := meter.Int64Counter(
+		"potato.stock.alerts",
+		metric.WithDescription("Number of low-stock alerts raised by variety and severity"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create stock alerts counter: %w", err)
+	}
+
+	removals, err := meter.Int64Counter(
+		"potato.removals",
+		metric.WithDescription("Number of potatoes removed from stock by variety and reason"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create removals counter: %w", err)
+	}
+
+	wasteWeight, err := meter.Float64Counter(
+		"potato.waste.weight",
+		metric.WithDescription("Weight of potatoes spoiled, damaged or recalled"),
+		metric.WithUnit("kg"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create waste weight counter: %w", err)
+	}
+
+	wasteCost, err := meter.Float64Counter(
+		"potato.waste.cost",
+		metric.WithDescription("Purchase cost of potatoes spoiled, damaged or recalled"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create waste cost counter: %w", err)
+	}
+
 	// Get hostname for service instance id
 	hostname, _ := os.Hostname()
 	if hostname == "" {
@@ -204,6 +248,10 @@ func initOpenTelemetry(ctx context.Context) (*Observability, error) {
 		inventoryLevel:  inventoryLevel,
 		potatoFreshness: potatoFreshness,
 		recipeViews:     recipeViews,
+		stockAlerts:     stockAlerts,
+		removals:        removals,
+		wasteWeight:     wasteWeight,
+		wasteCost:       wasteCost,
 		logger:          loggerProvider.Logger(instrumentationName),
 		serviceName:     cfg.ServiceName,
 		commonAttrs:     commonAttrs,
@@ -363,7 +411,7 @@ func (o *Observability) RecordInventory(ctx context.Context, variety string, cou
 		return
 	}
 	o.inventoryLevel.Record(ctx, int64(count),
-		metric.WithAttributes(attribute.String("potato.variety", sanitizeVariety(variety))))
+		metric.WithAttributes(attribute.String("potato.variety", o.sanitizeVariety(variety))))
 }
 
 func (o *Observability) RecordFreshness(ctx context.Context, variety string, freshness float64) {
@@ -371,28 +419,79 @@ func (o *Observability) RecordFreshness(ctx context.Context, variety string, fre
 		return
 	}
 	o.potatoFreshness.Record(ctx, freshness,
-		metric.WithAttributes(attribute.String("potato.variety", sanitizeVariety(variety))))
+		metric.WithAttributes(attribute.String("potato.variety", o.sanitizeVariety(variety))))
+}
+
+func (o *Observability) RecordStockAlert(ctx context.Context, variety, severity string) {
+	if o == nil || o.stockAlerts == nil {
+		return
+	}
+	o.stockAlerts.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("potato.variety", o.sanitizeVariety(variety)),
+			attribute.String("alert.severity", severity),
+		))
 }
 
+// RecordRemoval counts a potato leaving stock and, when it was wasted, the
+// weight and purchase cost lost.
+func (o *Observability) RecordRemoval(ctx context.Context, variety, reason string, waste bool, weightKg, cost float64) {
+	if o == nil || o.removals == nil {
+		return
+	}
+	attrs := metric.WithAttributes(
+		attribute.String("potato.variety", o.sanitizeVariety(variety)),
+		attribute.String("removal.reason", reason),
+	)
+	o.removals.Add(ctx, 1, attrs)
+	if waste {
+		o.wasteWeight.Add(ctx, weightKg, attrs)
+		o.wasteCost.Add(ctx, cost, attrs)
+	}
+}
+
+// VarietyCatalog resolves a variety name or alias to its catalog name.
+type VarietyCatalog interface {
+	CanonicalVariety(name string) (string, bool)
+}
+
+// UseVarietyCatalog makes metric labels follow the managed variety catalog.
+// Until it is called every variety is reported as "other".
+func (o *Observability) UseVarietyCatalog(catalog VarietyCatalog) {
+	if o == nil {
+		return
+	}
+	o.varieties = catalog
+}
+
+// maxVarietyLabels bounds the distinct potato.variety label values.
+const maxVarietyLabels = 50
+
 // sanitizeVariety ensures variety labels have bounded cardinality
-func sanitizeVariety(variety string) string {
+func (o *Observability) sanitizeVariety(variety string) string {
 	// Limit length to prevent label explosion
 	if len(variety) > 50 {
 		return "invalid_variety"
 	}
 
-	// Known valid varieties
-	validVarieties := map[string]bool{
-		"Russet":        true,
-		"Yukon Gold":    true,
-		"Red Potato":    true,
-		"Fingerling":    true,
-		"Purple Potato": true,
-		"Sweet Potato":  true,
-	}
-
-	if validVarieties[variety] {
-		return variety
+	// Only catalogued varieties get their own label, under their canonical
+	// name, and only the first maxVarietyLabels of them: the catalog can grow
+	// through the API
+	if o.varieties != nil {
+		if name, ok := o.varieties.CanonicalVariety(variety); ok {
+			o.varietyMu.Lock()
+			defer o.varietyMu.Unlock()
+			if o.varietyLabels[name] {
+				return name
+			}
+			if len(o.varietyLabels) < maxVarietyLabels {
+				if o.varietyLabels == nil {
+					o.varietyLabels = make(map[string]bool)
+				}
+				o.varietyLabels[name] = true
+				return name
+			}
+		}
 	}
 	return "other"
 }
@@ -0,0 +1,251 @@
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+const dateLayout = "2006-01-02"
+
+// Promotion overrides the price per kg of matching potatoes between two
+// dates, inclusive, either with a fixed PricePerKg or a DiscountPct off.
+// Empty Variety or Quality match every potato.
+type Promotion struct {
+	Name        string  `json:"name"`
+	Variety     string  `json:"variety,omitempty"`
+	Quality     string  `json:"quality,omitempty"`
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	DiscountPct float64 `json:"discount_pct,omitempty"`
+	PricePerKg  float64 `json:"price_per_kg,omitempty"`
+
+	from, until time.Time
+}
+
+type Rules struct {
+	Currency              string             `json:"currency"`
+	DefaultBasePricePerKg float64            `json:"default_base_price_per_kg"`
+	BasePricePerKg        map[string]float64 `json:"base_price_per_kg"`
+	QualityFactors        map[string]float64 `json:"quality_factors"`
+	FreshnessMarkdowns    map[string]float64 `json:"freshness_markdowns"`
+	MinimumPrice          float64            `json:"minimum_price"`
+	Promotions            []Promotion        `json:"promotions"`
+}
+
+// Input is a potato plus the facts about it that live elsewhere: its
+// freshness grade and its variety's catalog base price per kg.
+type Input struct {
+	Potato                models.Potato
+	Freshness             string
+	CatalogBasePricePerKg float64
+	At                    time.Time
+}
+
+// Engine prices potatoes from a rule set. Prices are built per kg from the
+// variety's base price and the quality factor, then any promotion, then
+// multiplied by weight and marked down by freshness.
+type Engine struct {
+	rules Rules
+}
+
+// Load reads the pricing rules from path, or the built-in rules when path
+// is empty.
+func Load(path string) (*Engine, error) {
+	data := defaultRules
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("pricing rules: %w", err)
+		}
+	}
+	rules, err := parseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("pricing rules: %w", err)
+	}
+	return &Engine{rules: rules}, nil
+}
+
+func (e *Engine) Currency() string {
+	return e.rules.Currency
+}
+
+func (e *Engine) Quote(in Input) models.PriceQuote {
+	potato := in.Potato
+	at := in.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	quote := models.PriceQuote{
+		PotatoID:     potato.ID,
+		Variety:      potato.Variety,
+		Quality:      potato.Quality,
+		WeightKg:     potato.Weight,
+		Freshness:    in.Freshness,
+		Currency:     e.rules.Currency,
+		CurrentPrice: potato.Price,
+		QuotedAt:     at,
+	}
+	step := func(rule, description string, result float64) {
+		quote.Steps = append(quote.Steps, models.PriceStep{Rule: rule, Description: description, Result: round(result)})
+	}
+
+	perKg, source := e.basePrice(potato.Variety, in.CatalogBasePricePerKg)
+	step("base_price", fmt.Sprintf("%s base price per kg from the %s", potato.Variety, source), perKg)
+
+	if factor, ok := e.rules.QualityFactors[potato.Quality]; ok {
+		perKg *= factor
+		step("quality", fmt.Sprintf("%s quality × %.2f", potato.Quality, factor), perKg)
+	}
+
+	if promo, promoPerKg, ok := e.promotion(potato, perKg, at); ok {
+		perKg = promoPerKg
+		quote.Promotion = promo.Name
+		if promo.PricePerKg > 0 {
+			step("promotion", fmt.Sprintf("%s: fixed price per kg until %s", promo.Name, promo.To), perKg)
+		} else {
+			step("promotion", fmt.Sprintf("%s: %.0f%% off until %s", promo.Name, promo.DiscountPct, promo.To), perKg)
+		}
+	}
+	perKg = round(perKg)
+	quote.PricePerKg = perKg
+
+	price := perKg * potato.Weight
+	step("weight", fmt.Sprintf("%.3f kg × %.2f per kg", potato.Weight, perKg), price)
+
+	if pct := e.rules.FreshnessMarkdowns[in.Freshness]; pct > 0 {
+		price *= 1 - pct/100
+		step("freshness", fmt.Sprintf("%s: %.0f%% markdown", in.Freshness, pct), price)
+	}
+
+	if price < e.rules.MinimumPrice {
+		price = e.rules.MinimumPrice
+		step("minimum", fmt.Sprintf("raised to the minimum price of %.2f", e.rules.MinimumPrice), price)
+	}
+
+	quote.Price = round(price)
+	return quote
+}
+
+// UsesVariety reports whether a base price or promotion names a variety.
+func (e *Engine) UsesVariety(variety string) bool {
+	for name := range e.rules.BasePricePerKg {
+		if strings.EqualFold(name, variety) {
+			return true
+		}
+	}
+	for _, promo := range e.rules.Promotions {
+		if strings.EqualFold(promo.Variety, variety) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) basePrice(variety string, catalog float64) (float64, string) {
+	for name, price := range e.rules.BasePricePerKg {
+		if strings.EqualFold(name, variety) {
+			return price, "pricing rules"
+		}
+	}
+	if catalog > 0 {
+		return catalog, "variety catalog"
+	}
+	return e.rules.DefaultBasePricePerKg, "default"
+}
+
+// promotion picks the active promotion giving the lowest price per kg.
+func (e *Engine) promotion(potato models.Potato, perKg float64, at time.Time) (Promotion, float64, bool) {
+	var best Promotion
+	bestPrice := perKg
+	found := false
+	for _, promo := range e.rules.Promotions {
+		if at.Before(promo.from) || !at.Before(promo.until) {
+			continue
+		}
+		if promo.Variety != "" && !strings.EqualFold(promo.Variety, potato.Variety) {
+			continue
+		}
+		if promo.Quality != "" && !strings.EqualFold(promo.Quality, potato.Quality) {
+			continue
+		}
+		price := promo.PricePerKg
+		if price == 0 {
+			price = perKg * (1 - promo.DiscountPct/100)
+		}
+		if price < bestPrice {
+			best, bestPrice, found = promo, price, true
+		}
+	}
+	return best, bestPrice, found
+}
+
+func parseRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, err
+	}
+	if rules.DefaultBasePricePerKg <= 0 {
+		return Rules{}, errors.New("default_base_price_per_kg must be positive")
+	}
+	if rules.MinimumPrice < 0 {
+		return Rules{}, errors.New("minimum_price must be non-negative")
+	}
+	for variety, price := range rules.BasePricePerKg {
+		if price <= 0 {
+			return Rules{}, fmt.Errorf("base price for %s must be positive", variety)
+		}
+	}
+	for quality, factor := range rules.QualityFactors {
+		if factor <= 0 {
+			return Rules{}, fmt.Errorf("quality factor for %s must be positive", quality)
+		}
+	}
+	for status, pct := range rules.FreshnessMarkdowns {
+		if pct < 0 || pct >= 100 {
+			return Rules{}, fmt.Errorf("markdown for %s must be between 0 and 100", status)
+		}
+	}
+
+	for i := range rules.Promotions {
+		promo := &rules.Promotions[i]
+		if promo.Name == "" {
+			return Rules{}, fmt.Errorf("promotion %d has no name", i+1)
+		}
+		from, err := time.Parse(dateLayout, promo.From)
+		if err != nil {
+			return Rules{}, fmt.Errorf("promotion %q: from must be YYYY-MM-DD", promo.Name)
+		}
+		to, err := time.Parse(dateLayout, promo.To)
+		if err != nil {
+			return Rules{}, fmt.Errorf("promotion %q: to must be YYYY-MM-DD", promo.Name)
+		}
+		if to.Before(from) {
+			return Rules{}, fmt.Errorf("promotion %q ends before it starts", promo.Name)
+		}
+		if (promo.PricePerKg > 0) == (promo.DiscountPct > 0) {
+			return Rules{}, fmt.Errorf("promotion %q needs exactly one of price_per_kg or discount_pct", promo.Name)
+		}
+		if promo.DiscountPct >= 100 {
+			return Rules{}, fmt.Errorf("promotion %q: discount_pct must be below 100", promo.Name)
+		}
+		promo.from = from
+		promo.until = to.AddDate(0, 0, 1)
+	}
+
+	return rules, nil
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
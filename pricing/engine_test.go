@@ -0,0 +1,81 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+const testRules = `{
+  "currency": "USD",
+  "default_base_price_per_kg": 5.0,
+  "base_price_per_kg": { "Russet": 4.0 },
+  "quality_factors": { "Premium": 1.5, "Standard": 1.0 },
+  "freshness_markdowns": { "Fresh": 0, "Old": 50 },
+  "minimum_price": 0.25,
+  "promotions": [
+    { "name": "Russet Week", "variety": "russet", "from": "2026-10-01", "to": "2026-10-07", "discount_pct": 10 },
+    { "name": "Standard Russet Deal", "variety": "Russet", "quality": "Standard", "from": "2026-10-01", "to": "2026-10-07", "price_per_kg": 3.0 },
+    { "name": "Storewide", "from": "2026-10-05", "to": "2026-10-05", "discount_pct": 50 }
+  ]
+}`
+
+func TestQuotePrecedence(t *testing.T) {
+	rules, err := parseRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("parse rules: %v", err)
+	}
+	engine := &Engine{rules: rules}
+	outside := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	during := time.Date(2026, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		variety       string
+		quality       string
+		freshness     string
+		weightKg      float64
+		catalog       float64
+		at            time.Time
+		wantPerKg     float64
+		wantPrice     float64
+		wantPromotion string
+	}{
+		{name: "pricing rules beat the catalog", variety: "Russet", quality: "Standard", weightKg: 1, catalog: 9, at: outside, wantPerKg: 4, wantPrice: 4},
+		{name: "catalog beats the default", variety: "Yukon Gold", quality: "Standard", weightKg: 1, catalog: 6, at: outside, wantPerKg: 6, wantPrice: 6},
+		{name: "default without either", variety: "Yukon Gold", quality: "Standard", weightKg: 1, at: outside, wantPerKg: 5, wantPrice: 5},
+		{name: "quality factor applies before weight", variety: "Yukon Gold", quality: "Premium", weightKg: 0.5, at: outside, wantPerKg: 7.5, wantPrice: 3.75},
+		{name: "unknown quality has no factor", variety: "Yukon Gold", quality: "Heirloom", weightKg: 1, at: outside, wantPerKg: 5, wantPrice: 5},
+		{name: "lowest promotion wins", variety: "Russet", quality: "Standard", weightKg: 1, at: during, wantPerKg: 3, wantPrice: 3, wantPromotion: "Standard Russet Deal"},
+		{name: "promotion limited to a quality", variety: "Russet", quality: "Premium", weightKg: 1, at: during, wantPerKg: 5.4, wantPrice: 5.4, wantPromotion: "Russet Week"},
+		{name: "promotion covers its last day", variety: "Russet", quality: "Premium", weightKg: 1, at: time.Date(2026, 10, 7, 23, 59, 0, 0, time.UTC), wantPerKg: 5.4, wantPrice: 5.4, wantPromotion: "Russet Week"},
+		{name: "promotion over after its last day", variety: "Russet", quality: "Premium", weightKg: 1, at: time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC), wantPerKg: 6, wantPrice: 6},
+		{name: "storewide beats variety promotions", variety: "Russet", quality: "Standard", weightKg: 1, at: time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC), wantPerKg: 2, wantPrice: 2, wantPromotion: "Storewide"},
+		{name: "freshness markdown after weight", variety: "Yukon Gold", quality: "Standard", freshness: "Old", weightKg: 2, at: outside, wantPerKg: 5, wantPrice: 5},
+		{name: "minimum price floor", variety: "Yukon Gold", quality: "Standard", freshness: "Old", weightKg: 0.05, at: outside, wantPerKg: 5, wantPrice: 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := engine.Quote(Input{
+				Potato:                models.Potato{ID: "p1", Variety: tt.variety, Quality: tt.quality, Weight: tt.weightKg},
+				Freshness:             tt.freshness,
+				CatalogBasePricePerKg: tt.catalog,
+				At:                    tt.at,
+			})
+			if quote.PricePerKg != tt.wantPerKg || quote.Price != tt.wantPrice || quote.Promotion != tt.wantPromotion {
+				t.Errorf("got %.2f/kg, %.2f, promotion %q; want %.2f/kg, %.2f, promotion %q",
+					quote.PricePerKg, quote.Price, quote.Promotion, tt.wantPerKg, tt.wantPrice, tt.wantPromotion)
+			}
+		})
+	}
+}
+
+func TestParseRulesRejectsAmbiguousPromotion(t *testing.T) {
+	rules := `{"default_base_price_per_kg": 5, "promotions": [
+	  {"name": "Both", "from": "2026-10-01", "to": "2026-10-02", "discount_pct": 10, "price_per_kg": 3}
+	]}`
+	if _, err := parseRules([]byte(rules)); err == nil {
+		t.Error("accepted a promotion with both a discount and a fixed price")
+	}
+}
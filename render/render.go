@@ -0,0 +1,169 @@
+package render
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"unicode"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+)
+
+func (f Format) ContentType() string {
+	if f == HTML {
+		return "text/html; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	if f == HTML {
+		return "html"
+	}
+	return "md"
+}
+
+// Book is a titled collection of recipes rendered as a single document, such
+// as all recipes of one variety.
+type Book struct {
+	Title       string
+	Description string
+	Recipes     []models.Recipe
+}
+
+// recipeView is what the recipe templates see: the recipe itself plus
+// pre-formatted values, so overriding templates stay free of logic.
+type recipeView struct {
+	models.Recipe
+	Heading      string
+	Time         string
+	TagList      string
+	AllergenList string
+}
+
+type bookView struct {
+	Title       string
+	Description string
+	Recipes     []recipeView
+}
+
+var funcs = map[string]interface{}{
+	"inc":    func(i int) int { return i + 1 },
+	"anchor": Slug,
+}
+
+// RecipeRenderer turns recipes into printable Markdown and HTML documents.
+// It starts from the templates embedded in the binary; any file with the
+// same name in the override directory replaces the built-in one.
+type RecipeRenderer struct {
+	markdown *texttemplate.Template
+	html     *htmltemplate.Template
+}
+
+func NewRecipeRenderer(overrideDir string) (*RecipeRenderer, error) {
+	markdown, err := texttemplate.New("markdown").Funcs(funcs).ParseFS(defaultTemplates, "templates/*.md.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New("html").Funcs(funcs).ParseFS(defaultTemplates, "templates/*.html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	if overrideDir != "" {
+		if info, err := os.Stat(overrideDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("template directory %q is not a readable directory", overrideDir)
+		}
+		if files, _ := filepath.Glob(filepath.Join(overrideDir, "*.md.tmpl")); len(files) > 0 {
+			if markdown, err = markdown.ParseFiles(files...); err != nil {
+				return nil, err
+			}
+		}
+		if files, _ := filepath.Glob(filepath.Join(overrideDir, "*.html.tmpl")); len(files) > 0 {
+			if html, err = html.ParseFiles(files...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &RecipeRenderer{markdown: markdown, html: html}, nil
+}
+
+func (r *RecipeRenderer) RecipeCard(w io.Writer, format Format, recipe models.Recipe) error {
+	return r.execute(w, format, "card", newRecipeView(recipe, "#"))
+}
+
+func (r *RecipeRenderer) Cookbook(w io.Writer, format Format, book Book) error {
+	view := bookView{Title: book.Title, Description: book.Description}
+	for _, recipe := range book.Recipes {
+		view.Recipes = append(view.Recipes, newRecipeView(recipe, "##"))
+	}
+	return r.execute(w, format, "cookbook", view)
+}
+
+func (r *RecipeRenderer) execute(w io.Writer, format Format, name string, data interface{}) error {
+	switch format {
+	case Markdown:
+		return r.markdown.ExecuteTemplate(w, name+".md.tmpl", data)
+	case HTML:
+		return r.html.ExecuteTemplate(w, name+".html.tmpl", data)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+func newRecipeView(recipe models.Recipe, heading string) recipeView {
+	allergens := make([]string, len(recipe.Allergens))
+	for i, allergen := range recipe.Allergens {
+		allergens[i] = string(allergen)
+	}
+	return recipeView{
+		Recipe:       recipe,
+		Heading:      heading,
+		Time:         formatMinutes(recipe.CookingTime),
+		TagList:      strings.Join(recipe.Tags, ", "),
+		AllergenList: strings.Join(allergens, ", "),
+	}
+}
+
+func formatMinutes(minutes int) string {
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d min", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%d h", hours)
+	default:
+		return fmt.Sprintf("%d h %d min", hours, minutes)
+	}
+}
+
+// Slug builds the same fragment identifier GitHub-style Markdown renderers
+// generate for a heading, so cookbook tables of contents link correctly. It
+// is also safe to use in file names.
+func Slug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
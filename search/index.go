@@ -0,0 +1,249 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+const (
+	fieldName         = "name"
+	fieldTags         = "tags"
+	fieldIngredients  = "ingredients"
+	fieldInstructions = "instructions"
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+// Matches in the name count more than in the ingredient list, which in turn
+// count more than a passing mention in the instructions.
+var fieldBoosts = map[string]float64{
+	fieldName:         3.0,
+	fieldTags:         2.0,
+	fieldIngredients:  2.0,
+	fieldInstructions: 1.0,
+}
+
+type Hit struct {
+	ID           string
+	Score        float64
+	MatchedTerms []string
+	Highlights   map[string][]string
+}
+
+type document struct {
+	fields map[string][]string
+	terms  map[string]float64
+	length int
+}
+
+// RecipeIndex is an in-memory inverted index over recipe name, tags,
+// ingredients and instructions. It is safe for concurrent use and is kept up
+// to date by calling Add whenever a recipe is stored.
+type RecipeIndex struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]float64
+	docs     map[string]document
+	totalLen int
+}
+
+func NewRecipeIndex() *RecipeIndex {
+	return &RecipeIndex{
+		postings: make(map[string]map[string]float64),
+		docs:     make(map[string]document),
+	}
+}
+
+// Add indexes the recipe, replacing any previous version with the same ID.
+func (idx *RecipeIndex) Add(recipe models.Recipe) {
+	doc := document{
+		fields: map[string][]string{
+			fieldName:         {recipe.Name},
+			fieldTags:         recipe.Tags,
+			fieldIngredients:  recipe.Ingredients,
+			fieldInstructions: recipe.Instructions,
+		},
+		terms: make(map[string]float64),
+	}
+	for field, lines := range doc.fields {
+		for _, line := range lines {
+			for _, term := range terms(line) {
+				doc.terms[term] += fieldBoosts[field]
+				doc.length++
+			}
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(recipe.ID)
+	idx.docs[recipe.ID] = doc
+	idx.totalLen += doc.length
+	for term, freq := range doc.terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]float64)
+		}
+		idx.postings[term][recipe.ID] = freq
+	}
+}
+
+func (idx *RecipeIndex) remove(id string) {
+	doc, exists := idx.docs[id]
+	if !exists {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= doc.length
+	delete(idx.docs, id)
+}
+
+func (idx *RecipeIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search ranks recipes against the query with BM25 over boosted field
+// frequencies. Query terms missing from the vocabulary are expanded to
+// indexed terms within a small edit distance, at a reduced weight.
+func (idx *RecipeIndex) Search(query string, limit int) []Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.docs) == 0 {
+		return nil
+	}
+
+	scores := make(map[string]float64)
+	matched := make(map[string]map[string]bool)
+	avgLen := float64(idx.totalLen) / float64(len(idx.docs))
+
+	for _, queryTerm := range uniqueTerms(query) {
+		for term, weight := range idx.expand(queryTerm) {
+			postings := idx.postings[term]
+			idf := math.Log(1 + (float64(len(idx.docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+			for id, freq := range postings {
+				norm := bm25K1 * (1 - bm25B + bm25B*float64(idx.docs[id].length)/avgLen)
+				scores[id] += weight * idf * freq * (bm25K1 + 1) / (freq + norm)
+				if matched[id] == nil {
+					matched[id] = make(map[string]bool)
+				}
+				matched[id][term] = true
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{
+			ID:           id,
+			Score:        math.Round(score*1000) / 1000,
+			MatchedTerms: sortedKeys(matched[id]),
+			Highlights:   idx.docs[id].highlight(matched[id]),
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// expand maps a query term to the indexed terms it should match and the
+// weight of each: exact matches count fully, typo matches are discounted by
+// their distance.
+func (idx *RecipeIndex) expand(queryTerm string) map[string]float64 {
+	if _, exists := idx.postings[queryTerm]; exists {
+		return map[string]float64{queryTerm: 1}
+	}
+
+	expanded := make(map[string]float64)
+	limit := maxTypos(queryTerm)
+	if limit == 0 {
+		return expanded
+	}
+	for term := range idx.postings {
+		if d := editDistance(queryTerm, term); d <= limit {
+			expanded[term] = 1 / float64(d+1)
+		}
+	}
+	return expanded
+}
+
+func (doc document) highlight(matched map[string]bool) map[string][]string {
+	highlights := make(map[string][]string)
+	for field, lines := range doc.fields {
+		for _, line := range lines {
+			if marked, ok := highlightLine(line, matched); ok {
+				highlights[field] = append(highlights[field], marked)
+			}
+		}
+	}
+	return highlights
+}
+
+// highlightLine wraps the matched words of line in <mark> tags. The recipe
+// text around and inside the tags is HTML-escaped, so the result is safe to
+// render as HTML whatever the recipe contains.
+func highlightLine(line string, matched map[string]bool) (string, bool) {
+	var b strings.Builder
+	last := 0
+	found := false
+	for _, t := range tokenize(line) {
+		if !matched[t.term] {
+			continue
+		}
+		b.WriteString(html.EscapeString(line[last:t.start]))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(line[t.start:t.end]))
+		b.WriteString(highlightClose)
+		last = t.end
+		found = true
+	}
+	if !found {
+		return "", false
+	}
+	b.WriteString(html.EscapeString(line[last:]))
+	return b.String(), true
+}
+
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, term := range terms(text) {
+		if !seen[term] {
+			seen[term] = true
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func newTestIndex(recipes ...models.Recipe) *RecipeIndex {
+	idx := NewRecipeIndex()
+	for _, recipe := range recipes {
+		idx.Add(recipe)
+	}
+	return idx
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestSearchRanksByField(t *testing.T) {
+	idx := newTestIndex(
+		models.Recipe{ID: "instructions", Name: "Potato Salad", Ingredients: []string{"1 kg potatoes"}, Instructions: []string{"Rub the bowl with garlic"}},
+		models.Recipe{ID: "name", Name: "Garlic Potatoes", Ingredients: []string{"1 kg potatoes"}},
+		models.Recipe{ID: "ingredients", Name: "Roast Potatoes", Ingredients: []string{"1 kg potatoes", "4 cloves garlic"}},
+		models.Recipe{ID: "none", Name: "Plain Potatoes", Ingredients: []string{"1 kg potatoes"}},
+	)
+
+	got := hitIDs(idx.Search("garlic", 0))
+	want := []string{"name", "ingredients", "instructions"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Search(garlic) = %q, want %q", got, want)
+	}
+
+	if got := hitIDs(idx.Search("garlic", 2)); !slices.Equal(got, want[:2]) {
+		t.Errorf("Search(garlic, limit 2) = %q, want %q", got, want[:2])
+	}
+}
+
+func TestSearchRareTermsWeighMore(t *testing.T) {
+	idx := newTestIndex(
+		models.Recipe{ID: "common", Name: "Potato Bake", Ingredients: []string{"potatoes", "cream"}},
+		models.Recipe{ID: "rare", Name: "Potato Rosti", Ingredients: []string{"potatoes", "chives"}},
+		models.Recipe{ID: "other", Name: "Potato Mash", Ingredients: []string{"potatoes", "butter"}},
+	)
+	hits := idx.Search("potato chives", 0)
+	if len(hits) != 3 || hits[0].ID != "rare" {
+		t.Fatalf("Search(potato chives) = %q, want rare first", hitIDs(hits))
+	}
+	if hits[0].Score <= hits[1].Score {
+		t.Errorf("rare score %v is not above %v", hits[0].Score, hits[1].Score)
+	}
+	if !slices.Equal(hits[0].MatchedTerms, []string{"chiv", "potato"}) {
+		t.Errorf("MatchedTerms = %q", hits[0].MatchedTerms)
+	}
+}
+
+func TestSearchToleratesTypos(t *testing.T) {
+	idx := newTestIndex(
+		models.Recipe{ID: "garlic", Name: "Garlic Mash"},
+		models.Recipe{ID: "yukon", Name: "Yukon Gold Gratin"},
+		models.Recipe{ID: "ham", Name: "Ham Hash"},
+	)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"garlik", []string{"garlic"}},
+		{"yukno", []string{"yukon"}},
+		{"garlic", []string{"garlic"}},
+		// Terms of three letters or fewer must match exactly.
+		{"yam", []string{}},
+		{"gxrlxk", []string{}},
+	}
+	for _, tt := range tests {
+		if got := hitIDs(idx.Search(tt.query, 0)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	exact := idx.Search("garlic", 0)[0].Score
+	typo := idx.Search("garlik", 0)[0].Score
+	if typo >= exact {
+		t.Errorf("typo score %v is not below exact score %v", typo, exact)
+	}
+}
+
+func TestHighlightsEscapeRecipeText(t *testing.T) {
+	idx := newTestIndex(models.Recipe{
+		ID:          "xss",
+		Name:        `<img src=x onerror="alert(1)"> Garlic Mash`,
+		Ingredients: []string{"salt & <b>garlic</b>"},
+	})
+	hits := idx.Search("garlic", 0)
+	if len(hits) != 1 {
+		t.Fatalf("Search(garlic) = %q, want one hit", hitIDs(hits))
+	}
+
+	want := map[string][]string{
+		fieldName:        {`&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>Garlic</mark> Mash`},
+		fieldIngredients: {`salt &amp; &lt;b&gt;<mark>garlic</mark>&lt;/b&gt;`},
+	}
+	for field, lines := range want {
+		if !slices.Equal(hits[0].Highlights[field], lines) {
+			t.Errorf("%s highlights = %q, want %q", field, hits[0].Highlights[field], lines)
+		}
+	}
+	if len(hits[0].Highlights) != len(want) {
+		t.Errorf("highlights = %q, want only %d fields", hits[0].Highlights, len(want))
+	}
+}
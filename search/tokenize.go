@@ -0,0 +1,143 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "or": true, "the": true, "of": true,
+	"to": true, "in": true, "on": true, "for": true, "with": true, "into": true,
+	"until": true, "at": true, "by": true, "from": true, "is": true, "it": true,
+}
+
+// token is a normalized term together with its byte offsets in the source
+// text, which is what highlighting needs to wrap the original word.
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.ToLower(text[start:end])
+		if !stopWords[word] {
+			tokens = append(tokens, token{term: stem(word), start: start, end: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+func terms(text string) []string {
+	tokens := tokenize(text)
+	out := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, t.term)
+	}
+	return out
+}
+
+// stem is a light suffix stripper in the spirit of Porter step 1: it folds
+// plurals and the common verb forms recipes use ("baked", "baking", "bakes")
+// onto one term without trying to be linguistically complete.
+func stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		word = word[:len(word)-1]
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		word = undouble(word[:len(word)-3])
+	case strings.HasSuffix(word, "ed") && len(word) > 4:
+		word = undouble(word[:len(word)-2])
+	case strings.HasSuffix(word, "ly") && len(word) > 4:
+		word = word[:len(word)-2]
+	}
+
+	if strings.HasSuffix(word, "e") && len(word) > 4 {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+func undouble(word string) string {
+	n := len(word)
+	if n >= 2 && word[n-1] == word[n-2] && !strings.ContainsRune("aeiouls", rune(word[n-1])) {
+		return word[:n-1]
+	}
+	return word
+}
+
+// editDistance is the optimal string alignment distance, so a swapped pair
+// of letters ("yukno" for "yukon") counts as a single typo.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+// maxTypos scales the tolerated edit distance with the term length; very
+// short terms must match exactly or every query would match everything.
+func maxTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,62 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		{"potatoes", "potato"},
+		{"berries", "berry"},
+		{"dishes", "dish"},
+		{"glasses", "glass"},
+		{"baked", "bak"},
+		{"baking", "bak"},
+		{"chopped", "chop"},
+		{"boiling", "boil"},
+		{"quickly", "quick"},
+		{"rice", "rice"},
+		{"cup", "cup"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"same", "same", 0},
+		{"garlic", "garlik", 1},
+		{"yukon", "yukno", 1},
+		{"purée", "puree", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	text := "Mash the Potatoes, then serve"
+	tokens := tokenize(text)
+	want := []string{"mash", "potato", "then", "serv"}
+	if len(tokens) != len(want) {
+		t.Fatalf("tokenize(%q) = %+v, want terms %q", text, tokens, want)
+	}
+	for i, tok := range tokens {
+		if tok.term != want[i] {
+			t.Errorf("token %d: term %q, want %q", i, tok.term, want[i])
+		}
+	}
+	if got := text[tokens[1].start:tokens[1].end]; got != "Potatoes" {
+		t.Errorf("offsets of potato cover %q, want Potatoes", got)
+	}
+}
@@ -8,78 +8,247 @@ import (
 )
 
 func LoadSampleData(store storage.Storage) {
+	varieties := []models.Variety{
+		{
+			Name:               "Russet",
+			Aliases:            []string{"Idaho", "Russet Burbank"},
+			StarchLevel:        models.HighStarch,
+			BestCookingMethods: []models.CookingMethod{models.Baked, models.Fried, models.Mashed},
+			ShelfLifeDays:      120,
+			BasePrice:          5.00,
+		},
+		{
+			Name:               "Yukon Gold",
+			Aliases:            []string{"Yukon"},
+			StarchLevel:        models.MediumStarch,
+			BestCookingMethods: []models.CookingMethod{models.Mashed, models.Roasted, models.Boiled},
+			ShelfLifeDays:      90,
+			BasePrice:          6.00,
+		},
+		{
+			Name:               "Red Potato",
+			Aliases:            []string{"Red", "Red Bliss"},
+			StarchLevel:        models.LowStarch,
+			BestCookingMethods: []models.CookingMethod{models.Boiled, models.Roasted},
+			ShelfLifeDays:      60,
+			BasePrice:          5.50,
+		},
+		{
+			Name:               "Fingerling",
+			Aliases:            []string{"Fingerlings"},
+			StarchLevel:        models.LowStarch,
+			BestCookingMethods: []models.CookingMethod{models.Roasted, models.Boiled},
+			ShelfLifeDays:      45,
+			BasePrice:          9.00,
+		},
+		{
+			Name:               "Sweet Potato",
+			Aliases:            []string{"Yam", "Sweet"},
+			StarchLevel:        models.MediumStarch,
+			BestCookingMethods: []models.CookingMethod{models.Baked, models.Fried, models.Mashed},
+			ShelfLifeDays:      30,
+			BasePrice:          6.50,
+		},
+		{
+			Name:               "Purple Potato",
+			Aliases:            []string{"Purple", "Purple Majesty"},
+			StarchLevel:        models.MediumStarch,
+			BestCookingMethods: []models.CookingMethod{models.Boiled, models.Roasted, models.Mashed},
+			ShelfLifeDays:      60,
+			BasePrice:          8.00,
+		},
+	}
+
+	for _, variety := range varieties {
+		variety.CreatedAt = time.Now()
+		variety.UpdatedAt = variety.CreatedAt
+		store.AddVariety(variety)
+	}
+
+	warehouses := []models.Warehouse{
+		{ID: "boise", Name: "Boise Distribution Center", Address: "4200 W Franklin Rd, Boise, ID"},
+		{ID: "montreal", Name: "Montreal Cold Store", Address: "1500 Rue Notre-Dame E, Montreal, QC"},
+	}
+	for _, warehouse := range warehouses {
+		warehouse.CreatedAt = time.Now()
+		store.AddWarehouse(warehouse)
+	}
+
+	locations := []models.Location{
+		{ID: "boise-a1", WarehouseID: "boise", Name: "Aisle A, Bay 1", StorageCondition: "pantry", CapacityKg: 25},
+		{ID: "boise-a2", WarehouseID: "boise", Name: "Aisle A, Bay 2", StorageCondition: "pantry", CapacityKg: 25},
+		{ID: "boise-c1", WarehouseID: "boise", Name: "Cellar 1", StorageCondition: "cellar", CapacityKg: 40},
+		{ID: "montreal-c1", WarehouseID: "montreal", Name: "Cellar 1", StorageCondition: "cellar", CapacityKg: 40},
+		{ID: "montreal-c2", WarehouseID: "montreal", Name: "Cellar 2", StorageCondition: "cellar", CapacityKg: 40},
+	}
+	for _, location := range locations {
+		location.CreatedAt = time.Now()
+		store.AddLocation(location)
+	}
+
+	lots := []models.HarvestLot{
+		{
+			ID:            "idaho-snake-river-101",
+			Farm:          "Snake River Farms",
+			Field:         "North 40",
+			Origin:        "Idaho",
+			HarvestDate:   time.Now().AddDate(0, 0, -5),
+			Certification: "GlobalG.A.P.",
+		},
+		{
+			ID:            "peru-huancayo-007",
+			Farm:          "Cooperativa Agraria Huancayo",
+			Field:         "Parcela 7",
+			Origin:        "Peru",
+			HarvestDate:   time.Now().AddDate(0, 0, -4),
+			Certification: "Organic",
+		},
+	}
+	for _, lot := range lots {
+		lot.Status = models.LotActive
+		lot.CreatedAt = time.Now()
+		store.AddLot(lot)
+	}
+
+	suppliers := []models.Supplier{
+		{ID: "snake-river-farms", Name: "Snake River Farms", Origin: "Idaho", Contact: "orders@snakeriverfarms.example", LeadTimeDays: 3},
+		{ID: "huancayo-coop", Name: "Cooperativa Agraria Huancayo", Origin: "Peru", Contact: "ventas@huancayo.example", LeadTimeDays: 14},
+		{ID: "ferme-belanger", Name: "Ferme Bélanger", Origin: "Quebec", Contact: "commandes@fermebelanger.example", LeadTimeDays: 2},
+	}
+	for _, supplier := range suppliers {
+		supplier.CreatedAt = time.Now()
+		store.AddSupplier(supplier)
+	}
+
+	purchaseOrders := []models.PurchaseOrder{
+		{
+			ID:         "po-1001",
+			SupplierID: "snake-river-farms",
+			Lines: []models.PurchaseOrderLine{
+				{Line: 1, Variety: "Russet", Quality: string(models.Premium), WeightKg: 10, UnitCostPerKg: 1.4},
+				{Line: 2, Variety: "Yukon Gold", Quality: string(models.Standard), WeightKg: 6, UnitCostPerKg: 1.6},
+			},
+			OrderedAt:    time.Now().AddDate(0, 0, -1),
+			ExpectedDate: time.Now().AddDate(0, 0, 2),
+		},
+		{
+			ID:         "po-1002",
+			SupplierID: "huancayo-coop",
+			Lines: []models.PurchaseOrderLine{
+				{Line: 1, Variety: "Purple Potato", Quality: string(models.Premium), WeightKg: 5, UnitCostPerKg: 3.2},
+			},
+			OrderedAt:    time.Now().AddDate(0, 0, -2),
+			ExpectedDate: time.Now().AddDate(0, 0, 12),
+			Note:         "Organic only",
+		},
+	}
+	for _, order := range purchaseOrders {
+		order.Status = models.PurchaseOrderOpen
+		for _, line := range order.Lines {
+			order.OrderedKg += line.WeightKg
+			order.TotalCost += line.WeightKg * line.UnitCostPerKg
+		}
+		store.AddPurchaseOrder(order)
+	}
+
 	potatoes := []models.Potato{
 		{
-			ID:          "p001",
-			Variety:     "Russet",
-			Origin:      "Idaho",
-			Weight:      0.45,
-			Quality:     string(models.Premium),
-			HarvestDate: time.Now().AddDate(0, 0, -5),
-			Price:       2.99,
+			ID:               "p001",
+			Variety:          "Russet",
+			Origin:           "Idaho",
+			Weight:           0.45,
+			Quality:          string(models.Premium),
+			HarvestDate:      lots[0].HarvestDate,
+			Price:            2.99,
+			PurchaseCost:     1.64,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a1",
+			LotID:            lots[0].ID,
 		},
 		{
-			ID:          "p002",
-			Variety:     "Yukon Gold",
-			Origin:      "Canada",
-			Weight:      0.38,
-			Quality:     string(models.Premium),
-			HarvestDate: time.Now().AddDate(0, 0, -3),
-			Price:       3.49,
+			ID:               "p002",
+			Variety:          "Yukon Gold",
+			Origin:           "Canada",
+			Weight:           0.38,
+			Quality:          string(models.Premium),
+			HarvestDate:      time.Now().AddDate(0, 0, -3),
+			Price:            3.49,
+			PurchaseCost:     1.92,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a1",
 		},
 		{
-			ID:          "p003",
-			Variety:     "Red Potato",
-			Origin:      "Maine",
-			Weight:      0.32,
-			Quality:     string(models.Standard),
-			HarvestDate: time.Now().AddDate(0, 0, -10),
-			Price:       2.49,
+			ID:               "p003",
+			Variety:          "Red Potato",
+			Origin:           "Maine",
+			Weight:           0.32,
+			Quality:          string(models.Standard),
+			HarvestDate:      time.Now().AddDate(0, 0, -10),
+			Price:            2.49,
+			PurchaseCost:     1.37,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a1",
 		},
 		{
-			ID:          "p004",
-			Variety:     "Fingerling",
-			Origin:      "California",
-			Weight:      0.25,
-			Quality:     string(models.Premium),
-			HarvestDate: time.Now().AddDate(0, 0, -2),
-			Price:       4.99,
+			ID:               "p004",
+			Variety:          "Fingerling",
+			Origin:           "California",
+			Weight:           0.25,
+			Quality:          string(models.Premium),
+			HarvestDate:      time.Now().AddDate(0, 0, -2),
+			Price:            4.99,
+			PurchaseCost:     2.74,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a1",
 		},
 		{
-			ID:          "p005",
-			Variety:     "Sweet Potato",
-			Origin:      "North Carolina",
-			Weight:      0.50,
-			Quality:     string(models.Standard),
-			HarvestDate: time.Now().AddDate(0, 0, -7),
-			Price:       3.29,
+			ID:               "p005",
+			Variety:          "Sweet Potato",
+			Origin:           "North Carolina",
+			Weight:           0.50,
+			Quality:          string(models.Standard),
+			HarvestDate:      time.Now().AddDate(0, 0, -7),
+			Price:            3.29,
+			PurchaseCost:     1.81,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a2",
 		},
 		{
-			ID:          "p006",
-			Variety:     "Purple Potato",
-			Origin:      "Peru",
-			Weight:      0.28,
-			Quality:     string(models.Premium),
-			HarvestDate: time.Now().AddDate(0, 0, -4),
-			Price:       5.49,
+			ID:               "p006",
+			Variety:          "Purple Potato",
+			Origin:           "Peru",
+			Weight:           0.28,
+			Quality:          string(models.Premium),
+			HarvestDate:      lots[1].HarvestDate,
+			Price:            5.49,
+			PurchaseCost:     3.02,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a2",
+			LotID:            lots[1].ID,
 		},
 		{
-			ID:          "p007",
-			Variety:     "Russet",
-			Origin:      "Washington",
-			Weight:      0.52,
-			Quality:     string(models.Standard),
-			HarvestDate: time.Now().AddDate(0, 0, -15),
-			Price:       2.79,
+			ID:               "p007",
+			Variety:          "Russet",
+			Origin:           "Washington",
+			Weight:           0.52,
+			Quality:          string(models.Standard),
+			HarvestDate:      time.Now().AddDate(0, 0, -15),
+			Price:            2.79,
+			PurchaseCost:     1.53,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a2",
 		},
 		{
-			ID:          "p008",
-			Variety:     "Yukon Gold",
-			Origin:      "Quebec",
-			Weight:      0.41,
-			Quality:     string(models.Economy),
-			HarvestDate: time.Now().AddDate(0, 0, -20),
-			Price:       1.99,
+			ID:               "p008",
+			Variety:          "Yukon Gold",
+			Origin:           "Quebec",
+			Weight:           0.41,
+			Quality:          string(models.Economy),
+			HarvestDate:      time.Now().AddDate(0, 0, -20),
+			Price:            1.99,
+			PurchaseCost:     1.09,
+			StorageCondition: "pantry",
+			LocationID:       "boise-a2",
 		},
 	}
 
@@ -109,7 +278,11 @@ func LoadSampleData(store storage.Storage) {
 				"Bake for 50-60 minutes until tender",
 				"Cut open and add butter, salt, and toppings",
 			},
-			Servings: 1,
+			Servings:      1,
+			CookingMethod: models.Baked,
+			Tags:          []string{"classic", "side"},
+			Dietary:       []models.DietaryFlag{models.GlutenFree},
+			Allergens:     []models.Allergen{models.AllergenDairy},
 		},
 		{
 			ID:          "r002",
@@ -132,7 +305,11 @@ func LoadSampleData(store storage.Storage) {
 				"Mash until smooth",
 				"Season with salt and pepper",
 			},
-			Servings: 4,
+			Servings:      4,
+			CookingMethod: models.Mashed,
+			Tags:          []string{"comfort", "side"},
+			Dietary:       []models.DietaryFlag{models.GlutenFree},
+			Allergens:     []models.Allergen{models.AllergenDairy},
 		},
 		{
 			ID:          "r003",
@@ -155,7 +332,11 @@ func LoadSampleData(store storage.Storage) {
 				"Roast for 40-45 minutes, turning once",
 				"Serve hot",
 			},
-			Servings: 6,
+			Servings:      6,
+			CookingMethod: models.Roasted,
+			Tags:          []string{"side", "herbs"},
+			Dietary:       []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree},
+			Allergens:     []models.Allergen{},
 		},
 		{
 			ID:          "r004",
@@ -179,7 +360,11 @@ func LoadSampleData(store storage.Storage) {
 				"Add thyme and lemon zest",
 				"Cook until golden brown",
 			},
-			Servings: 4,
+			Servings:      4,
+			CookingMethod: models.Boiled,
+			Tags:          []string{"elegant", "side"},
+			Dietary:       []models.DietaryFlag{models.GlutenFree},
+			Allergens:     []models.Allergen{models.AllergenDairy},
 		},
 		{
 			ID:          "r005",
@@ -202,7 +387,11 @@ func LoadSampleData(store storage.Storage) {
 				"Bake for 25-30 minutes, flipping halfway",
 				"Serve immediately",
 			},
-			Servings: 3,
+			Servings:      3,
+			CookingMethod: models.Baked,
+			Tags:          []string{"snack", "kid-friendly"},
+			Dietary:       []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree},
+			Allergens:     []models.Allergen{},
 		},
 		{
 			ID:          "r006",
@@ -226,12 +415,16 @@ func LoadSampleData(store storage.Storage) {
 				"Add chopped onion and dill",
 				"Refrigerate for 1 hour before serving",
 			},
-			Servings: 6,
+			Servings:      6,
+			CookingMethod: models.Boiled,
+			Tags:          []string{"salad", "make-ahead"},
+			Dietary:       []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree},
+			Allergens:     []models.Allergen{models.AllergenMustard},
 		},
 	}
 
 	for _, recipe := range recipes {
+		recipe.UpdatedBy = "seed"
 		store.AddRecipe(recipe)
 	}
 }
-
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/williamdumont/potato-demo/degradation"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+const defaultAuditLimit = 100
+
+var auditCounter atomic.Int64
+
+type DegradationService struct {
+	storage  storage.Storage
+	potatoes *PotatoService
+	policies *degradation.Registry
+}
+
+func NewDegradationService(storage storage.Storage, potatoes *PotatoService, policies *degradation.Registry) *DegradationService {
+	return &DegradationService{
+		storage:  storage,
+		potatoes: potatoes,
+		policies: policies,
+	}
+}
+
+func (s *DegradationService) ActivePolicy() string {
+	return s.policies.Active().Name()
+}
+
+func (s *DegradationService) PolicyNames() []string {
+	return s.policies.Names()
+}
+
+// Preview runs a policy, the active one unless named, over current stock
+// without changing anything. Only potatoes the policy would touch are
+// listed. Random policies draw afresh on every call.
+func (s *DegradationService) Preview(policyName string) (models.DegradationPreview, error) {
+	policy, err := s.policies.Policy(policyName)
+	if err != nil {
+		return models.DegradationPreview{}, err
+	}
+
+	now := time.Now()
+	potatoes := s.storage.GetAllPotatoes()
+	preview := models.DegradationPreview{
+		Policy:      policy.Name(),
+		EvaluatedAt: now,
+		Evaluated:   len(potatoes),
+		Decisions:   []models.DegradationDecision{},
+	}
+	for _, potato := range potatoes {
+		decision := s.evaluate(policy, potato, now)
+		if decision.Action != models.KeepPotato {
+			preview.Decisions = append(preview.Decisions, decision)
+		}
+	}
+	return preview, nil
+}
+
+// Apply runs the active policy and carries out its decisions, recording an
+// audit entry for every potato downgraded or discarded.
+func (s *DegradationService) Apply(actor string) []models.AuditEntry {
+	policy := s.policies.Active()
+	now := time.Now()
+
+	var applied []models.AuditEntry
+	for _, potato := range s.storage.GetAllPotatoes() {
+		decision := s.evaluate(policy, potato, now)
+
+		var err error
+		switch decision.Action {
+		case models.DowngradePotato:
+			err = s.storage.SetPotatoQuality(potato.ID, decision.NewQuality)
+		case models.DiscardPotato:
+			err = s.storage.DeletePotato(potato.ID, models.RemovalSpoiled)
+		default:
+			continue
+		}
+		if err != nil {
+			// Removed by someone else since we listed it.
+			continue
+		}
+
+		entry := models.AuditEntry{
+			ID:          fmt.Sprintf("a%d", auditCounter.Add(1)),
+			Timestamp:   now,
+			Actor:       actor,
+			Action:      decision.Action,
+			PotatoID:    decision.PotatoID,
+			Variety:     decision.Variety,
+			FromQuality: decision.Quality,
+			ToQuality:   decision.NewQuality,
+			Policy:      policy.Name(),
+			Reason:      decision.Reason,
+		}
+		s.storage.AddAuditEntry(entry)
+		applied = append(applied, entry)
+	}
+	return applied
+}
+
+// GetAuditLog returns matching entries newest first.
+func (s *DegradationService) GetAuditLog(filter models.AuditFilter) []models.AuditEntry {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	entries := s.storage.GetAuditEntries()
+	matching := []models.AuditEntry{}
+	for i := len(entries) - 1; i >= 0 && len(matching) < filter.Limit; i-- {
+		entry := entries[i]
+		if filter.PotatoID != "" && entry.PotatoID != filter.PotatoID {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+	return matching
+}
+
+func (s *DegradationService) evaluate(policy degradation.Policy, potato models.Potato, now time.Time) models.DegradationDecision {
+	report, err := s.potatoes.CalculateFreshness(potato, "")
+	if err != nil {
+		// The potato's storage condition was dropped from the freshness
+		// rules; assess it as if kept in the default condition.
+		stored := potato
+		stored.StorageCondition = ""
+		report, _ = s.potatoes.CalculateFreshness(stored, "")
+	}
+	return policy.Evaluate(degradation.Subject{
+		Potato:    potato,
+		Freshness: report,
+		Now:       now,
+	})
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/williamdumont/potato-demo/forecast"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+const (
+	defaultForecastHorizon = 7
+	maxForecastHorizon     = 90
+	dateLayout             = "2006-01-02"
+)
+
+var ErrInvalidHorizon = fmt.Errorf("horizon must be between 1 and %d days", maxForecastHorizon)
+
+type ForecastService struct {
+	storage  storage.Storage
+	settings forecast.Settings
+
+	mu     sync.Mutex
+	alerts map[string]models.StockAlert
+}
+
+func NewForecastService(storage storage.Storage, settings forecast.Settings) *ForecastService {
+	return &ForecastService{
+		storage:  storage,
+		settings: settings,
+		alerts:   make(map[string]models.StockAlert),
+	}
+}
+
+// Forecast predicts a variety's daily demand over horizon days, 7 by
+// default, from the potatoes sold or cooked each day. Wasted potatoes are
+// not demand, and quarantined potatoes are not on hand since they can never
+// be sold or cooked.
+func (s *ForecastService) Forecast(variety string, horizon int) (models.DemandForecast, error) {
+	if horizon == 0 {
+		horizon = defaultForecastHorizon
+	}
+	if horizon < 0 || horizon > maxForecastHorizon {
+		return models.DemandForecast{}, ErrInvalidHorizon
+	}
+	name, err := canonicalVariety(s.storage, variety)
+	if err != nil {
+		return models.DemandForecast{}, err
+	}
+
+	now := time.Now().UTC()
+	demand, start := s.dailyDemand(now)
+	aggregates := s.storage.GetInventoryAggregates()
+	return s.forecast(name, demand[name], start, aggregates.ByVariety[name].Available(), horizon, now), nil
+}
+
+// Forecasts covers every catalogued variety, sorted by name.
+func (s *ForecastService) Forecasts(horizon int) ([]models.DemandForecast, error) {
+	if horizon == 0 {
+		horizon = defaultForecastHorizon
+	}
+	if horizon < 0 || horizon > maxForecastHorizon {
+		return nil, ErrInvalidHorizon
+	}
+
+	now := time.Now().UTC()
+	demand, start := s.dailyDemand(now)
+	aggregates := s.storage.GetInventoryAggregates()
+
+	varieties := s.storage.GetAllVarieties()
+	sort.Slice(varieties, func(i, j int) bool {
+		return varieties[i].Name < varieties[j].Name
+	})
+	forecasts := make([]models.DemandForecast, 0, len(varieties))
+	for _, variety := range varieties {
+		forecasts = append(forecasts, s.forecast(variety.Name, demand[variety.Name], start, aggregates.ByVariety[variety.Name].Available(), horizon, now))
+	}
+	return forecasts, nil
+}
+
+// Alerts returns the low-stock alerts found by the last check, most severe
+// first. It only reads: the background check is the one place alerts are
+// raised, so none is lost to a request.
+func (s *ForecastService) Alerts() []models.StockAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alerts := make([]models.StockAlert, 0, len(s.alerts))
+	for _, alert := range s.alerts {
+		alerts = append(alerts, alert)
+	}
+	sortAlerts(alerts)
+	return alerts
+}
+
+// CheckAlerts re-evaluates every variety and returns the alerts in force
+// along with those raised or escalated since the last check. Alerts clear
+// once stock is back above the reorder point. Only the background worker
+// calls it.
+func (s *ForecastService) CheckAlerts() (active, raised []models.StockAlert) {
+	forecasts, _ := s.Forecasts(0)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := make(map[string]models.StockAlert)
+	for _, f := range forecasts {
+		if f.AverageDailyDemand == 0 || float64(f.OnHand) > f.ReorderPoint {
+			continue
+		}
+		alert := models.StockAlert{
+			Variety:                f.Variety,
+			Severity:               models.AlertWarning,
+			OnHand:                 f.OnHand,
+			ReorderPoint:           f.ReorderPoint,
+			SuggestedOrderQuantity: f.SuggestedOrderQuantity,
+			RaisedAt:               now,
+		}
+		leadTimeDemand := f.ReorderPoint - f.SafetyStock
+		if f.OnHand == 0 || float64(f.OnHand) < leadTimeDemand {
+			alert.Severity = models.AlertCritical
+			alert.Message = fmt.Sprintf("%s will run out before an order placed now arrives in %d days: %d on hand, reorder point %.1f, order %d",
+				f.Variety, f.LeadTimeDays, f.OnHand, f.ReorderPoint, f.SuggestedOrderQuantity)
+		} else {
+			alert.Message = fmt.Sprintf("%s is at its reorder point: %d on hand, reorder point %.1f, order %d",
+				f.Variety, f.OnHand, f.ReorderPoint, f.SuggestedOrderQuantity)
+		}
+
+		previous, ok := s.alerts[f.Variety]
+		switch {
+		case !ok, previous.Severity == models.AlertWarning && alert.Severity == models.AlertCritical:
+			raised = append(raised, alert)
+		default:
+			alert.RaisedAt = previous.RaisedAt
+		}
+		current[f.Variety] = alert
+		active = append(active, alert)
+	}
+	s.alerts = current
+
+	sortAlerts(active)
+	if active == nil {
+		active = []models.StockAlert{}
+	}
+	return active, raised
+}
+
+// sortAlerts puts critical alerts first, then orders by variety.
+func sortAlerts(alerts []models.StockAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Severity != alerts[j].Severity {
+			return alerts[i].Severity == models.AlertCritical
+		}
+		return alerts[i].Variety < alerts[j].Variety
+	})
+}
+
+func (s *ForecastService) forecast(variety string, demand map[string]float64, start time.Time, onHand, horizon int, now time.Time) models.DemandForecast {
+	leadTime, review := s.settings.Replenishment(variety)
+	result := models.DemandForecast{
+		Variety:      variety,
+		Method:       forecast.MovingAverageMethod,
+		OnHand:       onHand,
+		LeadTimeDays: leadTime,
+		ReviewDays:   review,
+		History:      []models.DemandPoint{},
+	}
+
+	var history []float64
+	for day := start; !day.After(now); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		history = append(history, demand[date])
+		result.History = append(result.History, models.DemandPoint{Date: date, Quantity: demand[date]})
+	}
+
+	// Plan over at least the lead and review time, whatever is shown.
+	planning := max(horizon, leadTime+review)
+	moving := forecast.MovingAverage(history, s.settings.MovingAverageDays, planning)
+	holtWinters, seasonal := forecast.HoltWinters(history, s.settings.SeasonDays,
+		s.settings.Alpha, s.settings.Beta, s.settings.Gamma, planning)
+	planned := moving
+	if seasonal {
+		planned = holtWinters
+		result.Method = forecast.HoltWintersMethod
+	}
+
+	for h := 0; h < horizon; h++ {
+		point := models.ForecastPoint{
+			Date:          now.AddDate(0, 0, h+1).Format(dateLayout),
+			MovingAverage: round2(moving[h]),
+		}
+		if seasonal {
+			value := round2(holtWinters[h])
+			point.HoltWinters = &value
+		}
+		result.Forecast = append(result.Forecast, point)
+	}
+
+	leadTimeDemand := sum(planned[:leadTime])
+	result.AverageDailyDemand = round2(sum(planned) / float64(planning))
+	result.StdDevDailyDemand = round2(forecast.StdDev(history))
+	result.SafetyStock = round2(s.settings.ServiceLevelZ * forecast.StdDev(history) * math.Sqrt(float64(leadTime)))
+	result.ReorderPoint = round2(leadTimeDemand + result.SafetyStock)
+	if result.AverageDailyDemand > 0 {
+		cover := round2(float64(onHand) / result.AverageDailyDemand)
+		result.DaysOfCover = &cover
+	}
+	if float64(onHand) <= result.ReorderPoint {
+		orderUpTo := sum(planned[:leadTime+review]) + result.SafetyStock
+		result.SuggestedOrderQuantity = int(math.Ceil(math.Max(orderUpTo-float64(onHand), 0)))
+	}
+	return result
+}
+
+// dailyDemand counts potatoes sold or cooked per variety and UTC day,
+// and returns the first day of history: history_days ago, or the day stock
+// was first recorded if later, so days before the service held any stock
+// don't count as days without demand.
+func (s *ForecastService) dailyDemand(now time.Time) (map[string]map[string]float64, time.Time) {
+	start := now.Truncate(24*time.Hour).AddDate(0, 0, 1-s.settings.HistoryDays)
+	counts, since := s.storage.GetDailyDemand()
+	if !since.IsZero() {
+		if first := since.UTC().Truncate(24 * time.Hour); first.After(start) {
+			start = first
+		}
+	}
+
+	demand := make(map[string]map[string]float64)
+	for _, count := range counts {
+		if count.Day.Before(start) {
+			continue
+		}
+		days, ok := demand[count.Variety]
+		if !ok {
+			days = make(map[string]float64)
+			demand[count.Variety] = days
+		}
+		days[count.Day.Format(dateLayout)] += float64(count.Quantity)
+	}
+	return demand, start
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/williamdumont/potato-demo/forecast"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+func TestForecastExcludesRecalledStock(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	if err := store.AddVariety(models.Variety{Name: "Russet", ShelfLifeDays: 60}); err != nil {
+		t.Fatalf("add variety: %v", err)
+	}
+	lots := NewLotService(store)
+	harvest := time.Now().AddDate(0, 0, -3)
+	for _, id := range []string{"lot-good", "lot-bad"} {
+		if _, err := lots.CreateLot(models.HarvestLot{ID: id, Farm: "Farm", Field: "North", HarvestDate: harvest}); err != nil {
+			t.Fatalf("create lot %s: %v", id, err)
+		}
+	}
+
+	// 4 potatoes from a good lot, 8 from one that will be recalled, and
+	// 3 sold today so the variety has demand.
+	add := func(id, lot string) {
+		t.Helper()
+		potato := models.Potato{ID: id, Variety: "Russet", Weight: 0.3, Price: 1, HarvestDate: harvest, LotID: lot}
+		if err := store.AddPotato(potato); err != nil {
+			t.Fatalf("add potato %s: %v", id, err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		add(fmt.Sprintf("good-%d", i), "lot-good")
+	}
+	for i := 0; i < 8; i++ {
+		add(fmt.Sprintf("bad-%d", i), "lot-bad")
+	}
+	for i := 0; i < 3; i++ {
+		add(fmt.Sprintf("sold-%d", i), "lot-good")
+		if err := store.DeletePotato(fmt.Sprintf("sold-%d", i), models.RemovalSold); err != nil {
+			t.Fatalf("sell: %v", err)
+		}
+	}
+
+	settings, err := forecast.Load("")
+	if err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	forecasts := NewForecastService(store, settings)
+
+	before, err := forecasts.Forecast("russet", 0)
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if before.OnHand != 12 {
+		t.Fatalf("OnHand before the recall = %d, want 12", before.OnHand)
+	}
+	if float64(before.OnHand) <= before.ReorderPoint {
+		t.Fatalf("reorder point %.2f already reached before the recall", before.ReorderPoint)
+	}
+
+	if _, err := lots.Recall("lot-bad", "contamination"); err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+
+	after, err := forecasts.Forecast("russet", 0)
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if after.OnHand != 4 {
+		t.Errorf("OnHand after the recall = %d, want 4", after.OnHand)
+	}
+	if after.SuggestedOrderQuantity == 0 {
+		t.Errorf("no order suggested with %d on hand and reorder point %.2f", after.OnHand, after.ReorderPoint)
+	}
+
+	active, raised := forecasts.CheckAlerts()
+	if len(active) != 1 || len(raised) != 1 || active[0].Variety != "Russet" || active[0].OnHand != 4 {
+		t.Errorf("alerts after the recall: active %+v, raised %+v, want one Russet alert with 4 on hand", active, raised)
+	}
+
+	aggregates := store.GetInventoryAggregates()
+	if got := aggregates.ByVariety["Russet"]; got.Count != 12 || got.Quarantined != 8 {
+		t.Errorf("Russet aggregates = %+v, want 12 potatoes with 8 quarantined", got)
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+type unitDimension string
+
+const (
+	dimensionVolume unitDimension = "volume"
+	dimensionMass   unitDimension = "mass"
+	dimensionCount  unitDimension = "count"
+)
+
+// unitDef describes a measuring unit. Volume units are expressed in
+// millilitres and mass units in grams so conversions go through one base.
+type unitDef struct {
+	name      string
+	plural    string
+	dimension unitDimension
+	system    models.UnitSystem
+	base      float64
+}
+
+var (
+	unitTsp   = unitDef{name: "tsp", plural: "tsp", dimension: dimensionVolume, system: models.Imperial, base: 4.92892}
+	unitTbsp  = unitDef{name: "tbsp", plural: "tbsp", dimension: dimensionVolume, system: models.Imperial, base: 14.7868}
+	unitCup   = unitDef{name: "cup", plural: "cups", dimension: dimensionVolume, system: models.Imperial, base: 236.588}
+	unitMl    = unitDef{name: "ml", plural: "ml", dimension: dimensionVolume, system: models.Metric, base: 1}
+	unitL     = unitDef{name: "l", plural: "l", dimension: dimensionVolume, system: models.Metric, base: 1000}
+	unitOz    = unitDef{name: "oz", plural: "oz", dimension: dimensionMass, system: models.Imperial, base: 28.3495}
+	unitLb    = unitDef{name: "lb", plural: "lbs", dimension: dimensionMass, system: models.Imperial, base: 453.592}
+	unitG     = unitDef{name: "g", plural: "g", dimension: dimensionMass, system: models.Metric, base: 1}
+	unitKg    = unitDef{name: "kg", plural: "kg", dimension: dimensionMass, system: models.Metric, base: 1000}
+	unitClove = unitDef{name: "clove", plural: "cloves", dimension: dimensionCount}
+	unitCan   = unitDef{name: "can", plural: "cans", dimension: dimensionCount}
+	unitPinch = unitDef{name: "pinch", plural: "pinches", dimension: dimensionCount}
+)
+
+var unitAliases = map[string]unitDef{
+	"tsp": unitTsp, "tsps": unitTsp, "teaspoon": unitTsp, "teaspoons": unitTsp,
+	"tbsp": unitTbsp, "tbsps": unitTbsp, "tbs": unitTbsp, "tablespoon": unitTbsp, "tablespoons": unitTbsp,
+	"cup": unitCup, "cups": unitCup,
+	"ml": unitMl, "milliliter": unitMl, "milliliters": unitMl, "millilitre": unitMl, "millilitres": unitMl,
+	"l": unitL, "liter": unitL, "liters": unitL, "litre": unitL, "litres": unitL,
+	"oz": unitOz, "ounce": unitOz, "ounces": unitOz,
+	"lb": unitLb, "lbs": unitLb, "pound": unitLb, "pounds": unitLb,
+	"g": unitG, "gram": unitG, "grams": unitG,
+	"kg": unitKg, "kilogram": unitKg, "kilograms": unitKg,
+	"clove": unitClove, "cloves": unitClove,
+	"can": unitCan, "cans": unitCan,
+	"pinch": unitPinch, "pinches": unitPinch,
+}
+
+var unicodeFractions = map[string]string{
+	"½": "1/2", "⅓": "1/3", "⅔": "2/3", "¼": "1/4", "¾": "3/4", "⅛": "1/8",
+}
+
+// parseIngredient splits a free-text ingredient line such as "1 1/2 cups milk"
+// into quantity, unit and name. Lines without a leading quantity ("Salt and
+// pepper") come back with a zero quantity and are left untouched by scaling.
+func parseIngredient(text string) models.Ingredient {
+	ingredient := models.Ingredient{Name: strings.TrimSpace(text), Text: text}
+
+	normalized := text
+	for symbol, fraction := range unicodeFractions {
+		normalized = strings.ReplaceAll(normalized, symbol, " "+fraction)
+	}
+	fields := strings.Fields(normalized)
+	if len(fields) == 0 {
+		return ingredient
+	}
+
+	quantity, ok := parseQuantity(fields[0])
+	if !ok {
+		return ingredient
+	}
+	consumed := 1
+	if len(fields) > 1 && strings.Contains(fields[1], "/") {
+		if fraction, ok := parseQuantity(fields[1]); ok && fraction < 1 {
+			quantity += fraction
+			consumed = 2
+		}
+	}
+
+	ingredient.Quantity = quantity
+	rest := fields[consumed:]
+	if len(rest) > 0 {
+		if unit, ok := unitAliases[strings.ToLower(strings.TrimSuffix(rest[0], "."))]; ok {
+			ingredient.Unit = unit.name
+			rest = rest[1:]
+		}
+	}
+	ingredient.Name = strings.Join(rest, " ")
+
+	return ingredient
+}
+
+// parseQuantity reads a positive amount such as "2", "1.5" or "3/4".
+func parseQuantity(field string) (float64, bool) {
+	var value float64
+	if num, den, found := strings.Cut(field, "/"); found {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, false
+		}
+		value = n / d
+	} else {
+		var err error
+		if value, err = strconv.ParseFloat(field, 64); err != nil {
+			return 0, false
+		}
+	}
+	if !(value > 0) || math.IsInf(value, 1) {
+		return 0, false
+	}
+	return value, true
+}
+
+// scaleIngredient multiplies the quantity by factor, converts it to the
+// requested unit system (if any) and promotes it to the most readable unit.
+func scaleIngredient(ingredient models.Ingredient, factor float64, system models.UnitSystem) models.Ingredient {
+	if ingredient.Quantity == 0 {
+		return ingredient
+	}
+
+	scaled := ingredient
+	scaled.Quantity = ingredient.Quantity * factor
+
+	unit, known := unitAliases[ingredient.Unit]
+	if !known || unit.dimension == dimensionCount {
+		scaled.Quantity = roundCount(scaled.Quantity)
+		scaled.Text = formatIngredient(scaled, unit, models.Imperial)
+		return scaled
+	}
+
+	if system == "" {
+		system = unit.system
+	}
+	target := bestUnit(scaled.Quantity*unit.base, unit.dimension, system)
+	scaled.Quantity = roundForUnit(scaled.Quantity*unit.base/target.base, target)
+	scaled.Unit = target.name
+	scaled.Text = formatIngredient(scaled, target, system)
+
+	return scaled
+}
+
+// bestUnit picks the largest unit of the system that keeps the amount at or
+// above a sensible threshold (tsp→tbsp→cup, oz→lb, g→kg, ml→l).
+func bestUnit(baseAmount float64, dimension unitDimension, system models.UnitSystem) unitDef {
+	switch {
+	case dimension == dimensionVolume && system == models.Metric:
+		if baseAmount >= unitL.base {
+			return unitL
+		}
+		return unitMl
+	case dimension == dimensionVolume:
+		if baseAmount >= unitCup.base/4 {
+			return unitCup
+		}
+		if baseAmount >= unitTbsp.base {
+			return unitTbsp
+		}
+		return unitTsp
+	case system == models.Metric:
+		if baseAmount >= unitKg.base {
+			return unitKg
+		}
+		return unitG
+	default:
+		if baseAmount >= unitLb.base {
+			return unitLb
+		}
+		return unitOz
+	}
+}
+
+func roundForUnit(quantity float64, unit unitDef) float64 {
+	switch unit {
+	case unitG, unitMl:
+		switch {
+		case quantity < 10:
+			return math.Max(1, math.Round(quantity))
+		case quantity < 100:
+			return math.Round(quantity/5) * 5
+		default:
+			return math.Round(quantity/10) * 10
+		}
+	case unitKg, unitL:
+		return math.Round(quantity*20) / 20
+	case unitTsp:
+		return math.Max(0.125, math.Round(quantity*8)/8)
+	default:
+		return math.Max(0.25, math.Round(quantity*4)/4)
+	}
+}
+
+func roundCount(quantity float64) float64 {
+	if quantity < 5 {
+		return math.Max(0.5, math.Round(quantity*2)/2)
+	}
+	return math.Round(quantity)
+}
+
+func formatIngredient(ingredient models.Ingredient, unit unitDef, system models.UnitSystem) string {
+	amount := formatQuantity(ingredient.Quantity, system)
+	parts := []string{amount}
+	if unit.name != "" {
+		if ingredient.Quantity > 1 {
+			parts = append(parts, unit.plural)
+		} else {
+			parts = append(parts, unit.name)
+		}
+	}
+	if ingredient.Name != "" {
+		parts = append(parts, ingredient.Name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatQuantity renders metric amounts as decimals and imperial amounts as
+// kitchen fractions ("1 1/2").
+func formatQuantity(quantity float64, system models.UnitSystem) string {
+	if system == models.Metric {
+		return strconv.FormatFloat(quantity, 'f', -1, 64)
+	}
+
+	whole := math.Floor(quantity)
+	eighths := int(math.Round((quantity - whole) * 8))
+	if eighths == 8 {
+		whole++
+		eighths = 0
+	}
+	if eighths == 0 {
+		return strconv.Itoa(int(whole))
+	}
+
+	num, den := eighths, 8
+	for num%2 == 0 {
+		num /= 2
+		den /= 2
+	}
+	if whole == 0 {
+		return fmt.Sprintf("%d/%d", num, den)
+	}
+	return fmt.Sprintf("%d %d/%d", int(whole), num, den)
+}
@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		field string
+		want  float64
+		ok    bool
+	}{
+		{"2", 2, true},
+		{"1.5", 1.5, true},
+		{"3/4", 0.75, true},
+		{"0", 0, false},
+		{"-2", 0, false},
+		{"0/4", 0, false},
+		{"-1/2", 0, false},
+		{"1/-2", 0, false},
+		{"1/0", 0, false},
+		{"1/2/3", 0, false},
+		{"inf", 0, false},
+		{"NaN", 0, false},
+		{"NaN/2", 0, false},
+		{"a/b", 0, false},
+		{"cups", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseQuantity(tt.field)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseQuantity(%q) = %v, %v, want %v, %v", tt.field, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseIngredientIgnoresNonPositiveFraction(t *testing.T) {
+	ingredient := parseIngredient("0/2 cups milk")
+	if ingredient.Quantity != 0 {
+		t.Errorf("Quantity = %v, want 0 for a line without a positive amount", ingredient.Quantity)
+	}
+
+	ingredient = parseIngredient("1 1/2 cups milk")
+	if ingredient.Quantity != 1.5 || ingredient.Unit != "cup" || ingredient.Name != "milk" {
+		t.Errorf("parseIngredient(1 1/2 cups milk) = %+v", ingredient)
+	}
+}
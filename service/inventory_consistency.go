@@ -0,0 +1,77 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// aggregateTolerance absorbs the rounding error that builds up from adding
+// and subtracting float weights and prices.
+const aggregateTolerance = 1e-6
+
+// CheckInventoryConsistency recounts stock from every potato and reports
+// each maintained total that has drifted from the recount.
+func (s *PotatoService) CheckInventoryConsistency() models.ConsistencyReport {
+	maintained, recounted, potatoes := s.storage.RecountInventoryAggregates()
+
+	report := models.ConsistencyReport{
+		CheckedAt: time.Now(),
+		Potatoes:  potatoes,
+		Drift:     []models.AggregateDrift{},
+	}
+	report.Drift = append(report.Drift, compareTotals("total", "", maintained.Total, recounted.Total)...)
+	report.Drift = append(report.Drift, compareGroups("variety", maintained.ByVariety, recounted.ByVariety)...)
+	report.Drift = append(report.Drift, compareGroups("quality", maintained.ByQuality, recounted.ByQuality)...)
+	report.Drift = append(report.Drift, compareGroups("location", maintained.ByLocation, recounted.ByLocation)...)
+	report.Consistent = len(report.Drift) == 0
+	return report
+}
+
+func compareGroups(scope string, maintained, recounted map[string]models.AggregateTotals) []models.AggregateDrift {
+	keys := make(map[string]bool, len(recounted))
+	for key := range maintained {
+		keys[key] = true
+	}
+	for key := range recounted {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var drift []models.AggregateDrift
+	for _, key := range sorted {
+		drift = append(drift, compareTotals(scope, key, maintained[key], recounted[key])...)
+	}
+	return drift
+}
+
+func compareTotals(scope, key string, maintained, recounted models.AggregateTotals) []models.AggregateDrift {
+	var drift []models.AggregateDrift
+	fields := []struct {
+		name                  string
+		maintained, recounted float64
+	}{
+		{"count", float64(maintained.Count), float64(recounted.Count)},
+		{"quarantined", float64(maintained.Quarantined), float64(recounted.Quarantined)},
+		{"weight", maintained.Weight, recounted.Weight},
+		{"value", maintained.Value, recounted.Value},
+	}
+	for _, field := range fields {
+		if math.Abs(field.maintained-field.recounted) > aggregateTolerance {
+			drift = append(drift, models.AggregateDrift{
+				Scope:      scope,
+				Key:        key,
+				Field:      field.name,
+				Maintained: field.maintained,
+				Recomputed: field.recounted,
+			})
+		}
+	}
+	return drift
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+	"github.com/williamdumont/potato-demo/timeseries"
+)
+
+const (
+	defaultTrendWindow     = 24 * time.Hour
+	defaultTrendResolution = "hour"
+)
+
+// Columns of an inventory snapshot row.
+const (
+	snapshotCount = iota
+	snapshotWeight
+	snapshotValue
+	snapshotWidth
+)
+
+var ErrInvalidQuality = errors.New("quality must be Premium, Standard or Economy")
+
+// inventorySnapshotTiers keep a day of minute-level history, a month of
+// hourly history and a year of daily history.
+var inventorySnapshotTiers = []timeseries.Tier{
+	{Name: "minute", Step: time.Minute, Retention: 24 * time.Hour},
+	{Name: "hour", Step: time.Hour, Retention: 31 * 24 * time.Hour},
+	{Name: "day", Step: 24 * time.Hour, Retention: 366 * 24 * time.Hour},
+}
+
+type InventoryHistoryService struct {
+	storage storage.Storage
+	series  *timeseries.Store
+}
+
+func NewInventoryHistoryService(storage storage.Storage) *InventoryHistoryService {
+	return &InventoryHistoryService{
+		storage: storage,
+		series:  timeseries.New(snapshotWidth, inventorySnapshotTiers...),
+	}
+}
+
+// Snapshot records the current count, weight and value of stock for every
+// variety and quality.
+func (s *InventoryHistoryService) Snapshot() {
+	rows := make(map[string][]float64)
+	for _, potato := range s.storage.GetAllPotatoes() {
+		key := snapshotKey(potato.Variety, potato.Quality)
+		row, ok := rows[key]
+		if !ok {
+			row = make([]float64, snapshotWidth)
+			rows[key] = row
+		}
+		row[snapshotCount]++
+		row[snapshotWeight] += potato.Weight
+		row[snapshotValue] += potato.Price
+	}
+	s.series.Add(time.Now(), rows)
+}
+
+func (s *InventoryHistoryService) Tiers() []models.SnapshotTier {
+	var tiers []models.SnapshotTier
+	for _, tier := range s.series.Tiers() {
+		tiers = append(tiers, models.SnapshotTier{
+			Resolution: tier.Name,
+			Step:       tier.Step.String(),
+			Retention:  tier.Retention.String(),
+		})
+	}
+	return tiers
+}
+
+// Trend returns stock over [From, To] at the requested resolution, by
+// default the last 24 hours by the hour. Variety and Quality narrow it to
+// part of the stock.
+func (s *InventoryHistoryService) Trend(query models.InventoryTrendQuery) (models.InventoryTrend, error) {
+	if query.Resolution == "" {
+		query.Resolution = defaultTrendResolution
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultTrendWindow)
+	}
+	if query.To.Before(query.From) {
+		return models.InventoryTrend{}, ErrInvalidDateRange
+	}
+	if query.Variety != "" {
+		variety, err := canonicalVariety(s.storage, query.Variety)
+		if err != nil {
+			return models.InventoryTrend{}, err
+		}
+		query.Variety = variety
+	}
+	if query.Quality != "" {
+		quality, ok := canonicalQuality(query.Quality)
+		if !ok {
+			return models.InventoryTrend{}, ErrInvalidQuality
+		}
+		query.Quality = quality
+	}
+
+	points, err := s.series.Query(query.Resolution, query.From, query.To, func(key string) bool {
+		variety, quality, _ := strings.Cut(key, "|")
+		return (query.Variety == "" || variety == query.Variety) &&
+			(query.Quality == "" || quality == query.Quality)
+	})
+	if err != nil {
+		return models.InventoryTrend{}, err
+	}
+
+	trend := models.InventoryTrend{
+		Variety:    query.Variety,
+		Quality:    query.Quality,
+		Resolution: query.Resolution,
+		From:       query.From.UTC(),
+		To:         query.To.UTC(),
+		Points:     make([]models.InventoryTrendPoint, 0, len(points)),
+	}
+	for _, point := range points {
+		trend.Points = append(trend.Points, models.InventoryTrendPoint{
+			Time:      point.Time,
+			Snapshots: point.Samples,
+			Count:     math.Round(point.Values[snapshotCount]*100) / 100,
+			WeightKg:  roundWeight(point.Values[snapshotWeight]),
+			Value:     roundPrice(point.Values[snapshotValue]),
+		})
+	}
+	return trend, nil
+}
+
+func snapshotKey(variety, quality string) string {
+	return variety + "|" + quality
+}
+
+func canonicalQuality(quality string) (string, bool) {
+	for _, q := range []models.Quality{models.Premium, models.Standard, models.Economy} {
+		if strings.EqualFold(string(q), quality) {
+			return string(q), true
+		}
+	}
+	return "", false
+}
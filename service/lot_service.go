@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var (
+	ErrInvalidLot        = errors.New("lot needs an id of 1-64 characters without spaces or slashes, a farm, a field and a harvest_date")
+	ErrDuplicateLot      = errors.New("a lot with this id already exists")
+	ErrUnknownLot        = errors.New("lot does not exist")
+	ErrLotRecalled       = errors.New("lot has been recalled")
+	ErrLotChange         = errors.New("lot_id cannot be changed")
+	ErrInvalidRecall     = errors.New("recall reason must be 1-500 characters")
+	ErrPotatoQuarantined = storage.ErrPotatoQuarantined
+)
+
+const (
+	maxLotField     = 100
+	maxRecallReason = 500
+)
+
+type LotService struct {
+	storage storage.Storage
+
+	// mu keeps a recall from interleaving with another recall of the
+	// same lot.
+	mu sync.Mutex
+}
+
+func NewLotService(storage storage.Storage) *LotService {
+	return &LotService{
+		storage: storage,
+	}
+}
+
+func (s *LotService) CreateLot(lot models.HarvestLot) (models.HarvestLot, error) {
+	lot.ID = strings.TrimSpace(lot.ID)
+	lot.Farm = strings.TrimSpace(lot.Farm)
+	lot.Field = strings.TrimSpace(lot.Field)
+	lot.Origin = strings.TrimSpace(lot.Origin)
+	lot.Certification = strings.TrimSpace(lot.Certification)
+	if !validID(lot.ID) || lot.Farm == "" || lot.Field == "" || lot.HarvestDate.IsZero() {
+		return models.HarvestLot{}, ErrInvalidLot
+	}
+	for _, field := range []string{lot.Farm, lot.Field, lot.Origin, lot.Certification} {
+		if len(field) > maxLotField {
+			return models.HarvestLot{}, ErrInvalidLot
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetLot(lot.ID); err == nil {
+		return models.HarvestLot{}, ErrDuplicateLot
+	}
+	lot.Status = models.LotActive
+	lot.RecalledAt = nil
+	lot.RecallReason = ""
+	lot.CreatedAt = time.Now()
+	if err := s.storage.AddLot(lot); err != nil {
+		return models.HarvestLot{}, err
+	}
+	return lot, nil
+}
+
+func (s *LotService) GetLot(id string) (models.HarvestLot, error) {
+	return s.storage.GetLot(id)
+}
+
+// GetAllLots lists lots most recently harvested first.
+func (s *LotService) GetAllLots() []models.HarvestLot {
+	lots := s.storage.GetAllLots()
+	sort.Slice(lots, func(i, j int) bool {
+		if !lots[i].HarvestDate.Equal(lots[j].HarvestDate) {
+			return lots[i].HarvestDate.After(lots[j].HarvestDate)
+		}
+		return lots[i].ID < lots[j].ID
+	})
+	return lots
+}
+
+// Trace follows a lot through the stock ledger and the cook log.
+func (s *LotService) Trace(id string) (models.LotTrace, error) {
+	lot, err := s.storage.GetLot(id)
+	if err != nil {
+		return models.LotTrace{}, err
+	}
+
+	trace := models.LotTrace{
+		Lot:        lot,
+		InStock:    []models.Potato{},
+		Removed:    []models.StockMovement{},
+		ByReason:   make(map[models.RemovalReason]int),
+		CookEvents: []models.CookEvent{},
+		RecipeIDs:  []string{},
+	}
+	for _, movement := range s.storage.GetStockMovements() {
+		if movement.LotID != id {
+			continue
+		}
+		switch movement.Type {
+		case models.StockReceipt:
+			trace.Received++
+		case models.StockRemoval:
+			trace.Removed = append(trace.Removed, movement)
+			trace.ByReason[movement.Reason]++
+		}
+	}
+	for _, potato := range s.storage.GetAllPotatoes() {
+		if potato.LotID != id {
+			continue
+		}
+		trace.InStock = append(trace.InStock, potato)
+		if potato.Quarantined {
+			trace.Quarantined++
+		}
+	}
+	sort.Slice(trace.InStock, func(i, j int) bool {
+		return trace.InStock[i].ID < trace.InStock[j].ID
+	})
+	for _, event := range s.storage.GetCookEvents() {
+		if !slices.Contains(event.LotIDs, id) {
+			continue
+		}
+		trace.CookEvents = append(trace.CookEvents, event)
+		if !slices.Contains(trace.RecipeIDs, event.RecipeID) {
+			trace.RecipeIDs = append(trace.RecipeIDs, event.RecipeID)
+		}
+	}
+	sort.Strings(trace.RecipeIDs)
+	return trace, nil
+}
+
+// Recall marks a lot recalled and quarantines its remaining stock.
+// Quarantined potatoes stay in stock, so they can be moved and counted,
+// but can no longer be sold or cooked. Recalling a lot again quarantines
+// anything missed and keeps the original reason and time.
+func (s *LotService) Recall(id, reason string) (models.RecallResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxRecallReason {
+		return models.RecallResult{}, ErrInvalidRecall
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lot, err := s.storage.GetLot(id)
+	if err != nil {
+		return models.RecallResult{}, err
+	}
+	if lot.Status != models.LotRecalled {
+		now := time.Now()
+		lot.Status = models.LotRecalled
+		lot.RecalledAt = &now
+		lot.RecallReason = reason
+		if err := s.storage.UpdateLot(id, lot); err != nil {
+			return models.RecallResult{}, err
+		}
+	}
+
+	result := models.RecallResult{Lot: lot, Quarantined: []string{}}
+	for _, potato := range s.storage.GetAllPotatoes() {
+		if potato.LotID != id || potato.Quarantined {
+			continue
+		}
+		if err := s.storage.SetPotatoQuarantined(potato.ID, true); err != nil {
+			// Removed by someone else since we listed it.
+			continue
+		}
+		result.Quarantined = append(result.Quarantined, potato.ID)
+	}
+	sort.Strings(result.Quarantined)
+	return result, nil
+}
+
+// applyLot links potato to its lot, which must exist, giving it the lot's
+// harvest date and, when it has none, origin. A new potato cannot join a
+// recalled lot. An existing potato keeps its lot. Clients cannot set the
+// quarantine flag; storage keeps it on every write, so it is only copied
+// here to report it.
+func applyLot(store storage.Storage, potato models.Potato, current *models.Potato) (models.Potato, error) {
+	if current != nil {
+		potato.Quarantined = current.Quarantined
+		if potato.LotID == "" {
+			potato.LotID = current.LotID
+		} else if potato.LotID != current.LotID {
+			return models.Potato{}, ErrLotChange
+		}
+	} else {
+		potato.Quarantined = false
+	}
+	if potato.LotID == "" {
+		return potato, nil
+	}
+
+	lot, err := store.GetLot(potato.LotID)
+	if err != nil {
+		return models.Potato{}, ErrUnknownLot
+	}
+	if current == nil && lot.Status == models.LotRecalled {
+		return models.Potato{}, ErrLotRecalled
+	}
+	potato.HarvestDate = lot.HarvestDate
+	if potato.Origin == "" {
+		potato.Origin = lot.Origin
+	}
+	return potato, nil
+}
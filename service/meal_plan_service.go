@@ -0,0 +1,313 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var (
+	ErrInvalidMealPlan   = errors.New("invalid meal plan data")
+	ErrInvalidMeal       = errors.New("each meal needs a valid day, meal type and recipe")
+	ErrDuplicateMealPlan = errors.New("a meal plan with this id already exists")
+)
+
+// defaultPotatoWeightKg is used to turn "4 Yukon Gold potatoes" into a weight
+// when there is no stock of that variety to take an average from.
+const defaultPotatoWeightKg = 0.3
+
+var weekdays = map[string]string{
+	"monday": "Monday", "tuesday": "Tuesday", "wednesday": "Wednesday", "thursday": "Thursday",
+	"friday": "Friday", "saturday": "Saturday", "sunday": "Sunday",
+}
+
+var mealTypes = map[string]models.MealType{
+	"breakfast": models.Breakfast,
+	"lunch":     models.Lunch,
+	"dinner":    models.Dinner,
+	"snack":     models.Snack,
+}
+
+type MealPlanService struct {
+	storage storage.Storage
+}
+
+func NewMealPlanService(storage storage.Storage) *MealPlanService {
+	return &MealPlanService{
+		storage: storage,
+	}
+}
+
+func (s *MealPlanService) CreateMealPlan(plan models.MealPlan) (models.MealPlan, error) {
+	plan, err := s.normalizeMealPlan(plan)
+	if err != nil {
+		return models.MealPlan{}, err
+	}
+
+	plan.CreatedAt = time.Now()
+	plan.UpdatedAt = plan.CreatedAt
+	if err := s.storage.AddMealPlan(plan); err != nil {
+		if errors.Is(err, storage.ErrMealPlanExists) {
+			return models.MealPlan{}, ErrDuplicateMealPlan
+		}
+		return models.MealPlan{}, err
+	}
+
+	return plan, nil
+}
+
+func (s *MealPlanService) GetMealPlan(id string) (models.MealPlan, error) {
+	return s.storage.GetMealPlan(id)
+}
+
+func (s *MealPlanService) GetAllMealPlans() []models.MealPlan {
+	return s.storage.GetAllMealPlans()
+}
+
+func (s *MealPlanService) UpdateMealPlan(id string, plan models.MealPlan) (models.MealPlan, error) {
+	existing, err := s.storage.GetMealPlan(id)
+	if err != nil {
+		return models.MealPlan{}, err
+	}
+
+	plan.ID = id
+	plan, err = s.normalizeMealPlan(plan)
+	if err != nil {
+		return models.MealPlan{}, err
+	}
+
+	plan.CreatedAt = existing.CreatedAt
+	plan.UpdatedAt = time.Now()
+	if err := s.storage.UpdateMealPlan(id, plan); err != nil {
+		return models.MealPlan{}, err
+	}
+
+	return plan, nil
+}
+
+func (s *MealPlanService) DeleteMealPlan(id string) error {
+	return s.storage.DeleteMealPlan(id)
+}
+
+// normalizeMealPlan validates the plan and canonicalizes day and meal names.
+// Meals without servings default to the recipe's own yield.
+func (s *MealPlanService) normalizeMealPlan(plan models.MealPlan) (models.MealPlan, error) {
+	if plan.ID == "" || plan.Name == "" {
+		return models.MealPlan{}, ErrInvalidMealPlan
+	}
+
+	meals := make([]models.PlannedMeal, 0, len(plan.Meals))
+	for _, meal := range plan.Meals {
+		day, okDay := weekdays[strings.ToLower(meal.Day)]
+		mealType, okMeal := mealTypes[strings.ToLower(string(meal.Meal))]
+		if !okDay || !okMeal || meal.RecipeID == "" || meal.Servings < 0 {
+			return models.MealPlan{}, ErrInvalidMeal
+		}
+
+		recipe, err := s.storage.GetRecipe(meal.RecipeID)
+		if err != nil {
+			return models.MealPlan{}, fmt.Errorf("%w: %s", err, meal.RecipeID)
+		}
+		if meal.Servings == 0 {
+			meal.Servings = recipe.Servings
+		}
+
+		meal.Day = day
+		meal.Meal = mealType
+		meals = append(meals, meal)
+	}
+	plan.Meals = meals
+
+	return plan, nil
+}
+
+type shoppingKey struct {
+	name      string
+	unit      string
+	dimension unitDimension
+}
+
+type shoppingEntry struct {
+	name    string
+	amount  float64
+	recipes map[string]bool
+}
+
+// GenerateShoppingList scales every planned recipe to its servings, sums the
+// ingredient quantities across the plan and nets the potatoes off against
+// what is already in stock for each variety. Quantities are added up
+// unrounded and rounded once for the list, so small amounts spread over
+// many meals come out right.
+func (s *MealPlanService) GenerateShoppingList(id string, units models.UnitSystem) (models.ShoppingList, error) {
+	if units == "" {
+		units = models.Metric
+	}
+	if units != models.Metric && units != models.Imperial {
+		return models.ShoppingList{}, ErrInvalidUnits
+	}
+
+	plan, err := s.storage.GetMealPlan(id)
+	if err != nil {
+		return models.ShoppingList{}, err
+	}
+
+	entries := make(map[shoppingKey]*shoppingEntry)
+	potatoKg := make(map[string]float64)
+	var order []shoppingKey
+
+	for _, meal := range plan.Meals {
+		recipe, err := s.storage.GetRecipe(meal.RecipeID)
+		if err != nil {
+			return models.ShoppingList{}, fmt.Errorf("%w: %s", err, meal.RecipeID)
+		}
+		factor := scalingFactor(recipe.Servings, meal.Servings)
+
+		for _, line := range recipe.Ingredients {
+			ingredient := parseIngredient(line)
+			ingredient.Quantity *= factor
+			unit, known := unitAliases[ingredient.Unit]
+
+			if isPotatoIngredient(ingredient, recipe.Variety) && ingredient.Quantity > 0 {
+				if kg, ok := s.potatoKg(ingredient, recipe.Variety); ok {
+					potatoKg[recipe.Variety] += kg
+					continue
+				}
+			}
+
+			key := shoppingKey{name: strings.ToLower(ingredient.Name), unit: ingredient.Unit}
+			amount := ingredient.Quantity
+			if known && unit.dimension != dimensionCount {
+				key.unit = ""
+				key.dimension = unit.dimension
+				amount *= unit.base
+			}
+
+			entry, exists := entries[key]
+			if !exists {
+				entry = &shoppingEntry{name: ingredient.Name, recipes: make(map[string]bool)}
+				entries[key] = entry
+				order = append(order, key)
+			}
+			entry.amount += amount
+			entry.recipes[recipe.Name] = true
+		}
+	}
+
+	list := models.ShoppingList{
+		MealPlanID: plan.ID,
+		Units:      units,
+		Potatoes:   s.potatoRequirements(potatoKg, units),
+		Items:      []models.ShoppingListItem{},
+		ToTaste:    []models.ShoppingListItem{},
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i].name < order[j].name })
+	for _, key := range order {
+		entry := entries[key]
+		item := models.ShoppingListItem{Name: entry.name, Text: entry.name, Recipes: sortedNames(entry.recipes)}
+		if entry.amount == 0 {
+			list.ToTaste = append(list.ToTaste, item)
+			continue
+		}
+
+		var ingredient models.Ingredient
+		if key.dimension != "" {
+			unit := bestUnit(entry.amount, key.dimension, units)
+			ingredient = models.Ingredient{Quantity: roundForUnit(entry.amount/unit.base, unit), Unit: unit.name, Name: entry.name}
+			ingredient.Text = formatIngredient(ingredient, unit, units)
+		} else {
+			unit := unitAliases[key.unit]
+			ingredient = models.Ingredient{Quantity: roundCount(entry.amount), Unit: key.unit, Name: entry.name}
+			ingredient.Text = formatIngredient(ingredient, unit, models.Imperial)
+		}
+		item.Quantity = ingredient.Quantity
+		item.Unit = ingredient.Unit
+		item.Text = ingredient.Text
+		list.Items = append(list.Items, item)
+	}
+
+	return list, nil
+}
+
+func (s *MealPlanService) potatoRequirements(requiredKg map[string]float64, units models.UnitSystem) []models.PotatoRequirement {
+	unitName, perKg := "kg", 1.0
+	if units == models.Imperial {
+		unitName, perKg = "lb", 1000/unitLb.base
+	}
+	round := func(v float64) float64 { return math.Round(v*perKg*100) / 100 }
+
+	requirements := make([]models.PotatoRequirement, 0, len(requiredKg))
+	for variety, required := range requiredKg {
+		inStock := 0.0
+		for _, potato := range s.storage.GetPotatoesByVariety(variety) {
+			if !potato.Quarantined {
+				inStock += potato.Weight
+			}
+		}
+		requirements = append(requirements, models.PotatoRequirement{
+			Variety:  variety,
+			Required: round(required),
+			InStock:  round(inStock),
+			ToBuy:    round(math.Max(0, required-inStock)),
+			Unit:     unitName,
+		})
+	}
+	sort.Slice(requirements, func(i, j int) bool { return requirements[i].Variety < requirements[j].Variety })
+
+	return requirements
+}
+
+// potatoKg converts a potato ingredient to kilograms: weights directly,
+// volumes ("2 cups diced potatoes") by the density in the nutrition table
+// and plain counts by the average weight of the variety in stock. Other
+// units, such as cans, cannot be converted and are left for the caller to
+// list as an ordinary item.
+func (s *MealPlanService) potatoKg(ingredient models.Ingredient, variety string) (float64, bool) {
+	unit, known := unitAliases[ingredient.Unit]
+	switch {
+	case !known:
+		return ingredient.Quantity * s.averagePotatoWeight(variety), true
+	case unit.dimension == dimensionMass:
+		return ingredient.Quantity * unit.base / 1000, true
+	case unit.dimension == dimensionVolume:
+		if entry := lookupNutrition(ingredient.Name); entry != nil && entry.GramsPerMl > 0 {
+			return ingredient.Quantity * unit.base * entry.GramsPerMl / 1000, true
+		}
+	}
+	return 0, false
+}
+
+func (s *MealPlanService) averagePotatoWeight(variety string) float64 {
+	potatoes := s.storage.GetPotatoesByVariety(variety)
+	if len(potatoes) == 0 {
+		return defaultPotatoWeightKg
+	}
+	total := 0.0
+	for _, potato := range potatoes {
+		total += potato.Weight
+	}
+	return total / float64(len(potatoes))
+}
+
+func isPotatoIngredient(ingredient models.Ingredient, variety string) bool {
+	name := strings.ToLower(ingredient.Name)
+	if strings.Contains(name, "powder") || strings.Contains(name, "starch") {
+		return false
+	}
+	return strings.Contains(name, "potato") || (variety != "" && strings.Contains(name, strings.ToLower(variety)))
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
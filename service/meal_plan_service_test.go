@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+func newTestMealPlanService(t *testing.T) *MealPlanService {
+	t.Helper()
+	store := storage.NewInMemoryStorage()
+	recipe := models.Recipe{ID: "r-hash", Name: "Breakfast Hash", Servings: 8, Ingredients: []string{"2 eggs", "1 tsp paprika"}}
+	if err := store.AddRecipe(recipe); err != nil {
+		t.Fatalf("add recipe: %v", err)
+	}
+	return NewMealPlanService(store)
+}
+
+func TestShoppingListRoundsTotalsOnce(t *testing.T) {
+	s := newTestMealPlanService(t)
+	plan := models.MealPlan{ID: "week", Name: "Week"}
+	for _, day := range []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"} {
+		plan.Meals = append(plan.Meals, models.PlannedMeal{Day: day, Meal: models.Breakfast, RecipeID: "r-hash", Servings: 1})
+	}
+	if _, err := s.CreateMealPlan(plan); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	list, err := s.GenerateShoppingList("week", models.Metric)
+	if err != nil {
+		t.Fatalf("shopping list: %v", err)
+	}
+	// Each meal needs a quarter of an egg and 1/8 tsp (0.6 ml) of paprika;
+	// rounding per meal would ask for 3.5 eggs.
+	want := map[string]float64{"eggs": 2, "paprika": 4}
+	for _, item := range list.Items {
+		if item.Quantity != want[item.Name] {
+			t.Errorf("%s: quantity %v %s, want %v", item.Name, item.Quantity, item.Unit, want[item.Name])
+		}
+	}
+	if len(list.Items) != len(want) {
+		t.Errorf("items %+v, want %d", list.Items, len(want))
+	}
+}
+
+func TestCreateMealPlanRejectsDuplicateID(t *testing.T) {
+	s := newTestMealPlanService(t)
+	first, err := s.CreateMealPlan(models.MealPlan{ID: "week", Name: "Week"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := s.CreateMealPlan(models.MealPlan{ID: "week", Name: "Other week"}); !errors.Is(err, ErrDuplicateMealPlan) {
+		t.Fatalf("second create: err = %v, want %v", err, ErrDuplicateMealPlan)
+	}
+	stored, err := s.GetMealPlan("week")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if stored.Name != "Week" || !stored.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("stored plan %+v was replaced", stored)
+	}
+}
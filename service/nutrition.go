@@ -0,0 +1,198 @@
+package service
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+//go:embed data/nutrition.json
+var nutritionData []byte
+
+// nutritionEntry is one row of the reference table. Facts are per 100 g;
+// GramsPerMl converts volume measures and GramsPerPiece converts counted
+// items ("2 large sweet potatoes", "4 cloves garlic"). ToTaste marks
+// seasonings that contribute nothing when no amount is given.
+type nutritionEntry struct {
+	Name          string                `json:"name"`
+	Aliases       []string              `json:"aliases"`
+	Per100g       models.NutritionFacts `json:"per_100g"`
+	GramsPerMl    float64               `json:"grams_per_ml"`
+	GramsPerPiece float64               `json:"grams_per_piece"`
+	ToTaste       bool                  `json:"to_taste"`
+}
+
+type nutritionAlias struct {
+	alias string
+	entry *nutritionEntry
+}
+
+// nutritionAliases is sorted longest first so "sour cream" wins over "cream"
+// and "sweet potato" over "potato".
+var nutritionAliases = mustLoadNutritionTable(nutritionData)
+
+// Size words adjust the typical weight of counted items.
+var pieceSizes = map[string]float64{"small": 0.7, "medium": 1, "large": 1.4}
+
+const gramsPerPinch = 0.3
+
+func mustLoadNutritionTable(data []byte) []nutritionAlias {
+	var entries []nutritionEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		panic(fmt.Sprintf("invalid nutrition table: %v", err))
+	}
+
+	var aliases []nutritionAlias
+	for i := range entries {
+		for _, alias := range entries[i].Aliases {
+			aliases = append(aliases, nutritionAlias{alias: strings.ToLower(alias), entry: &entries[i]})
+		}
+	}
+	sort.SliceStable(aliases, func(i, j int) bool { return len(aliases[i].alias) > len(aliases[j].alias) })
+	return aliases
+}
+
+// CalculateNutrition totals the nutrition facts of a recipe from its
+// ingredient quantities. Ingredients that are not in the reference table, or
+// whose amount cannot be turned into a weight, are reported rather than
+// guessed, and mark the report as incomplete.
+func (s *RecipeService) CalculateNutrition(id string) (models.NutritionReport, error) {
+	recipe, err := s.storage.GetRecipe(id)
+	if err != nil {
+		return models.NutritionReport{}, err
+	}
+	return calculateNutrition(recipe), nil
+}
+
+func calculateNutrition(recipe models.Recipe) models.NutritionReport {
+	report := models.NutritionReport{
+		RecipeID:     recipe.ID,
+		Servings:     recipe.Servings,
+		Complete:     true,
+		Unrecognized: []string{},
+		Ingredients:  make([]models.IngredientNutrition, 0, len(recipe.Ingredients)),
+	}
+
+	for _, text := range recipe.Ingredients {
+		line, facts := ingredientNutrition(parseIngredient(text))
+		switch line.Status {
+		case models.NutritionUnrecognized:
+			report.Unrecognized = append(report.Unrecognized, text)
+			report.Complete = false
+		case models.NutritionUnmeasured:
+			report.Complete = false
+		}
+		report.Total = addFacts(report.Total, facts)
+		report.Ingredients = append(report.Ingredients, line)
+	}
+
+	// Totals add up the unrounded facts and are rounded once, so rounding
+	// errors do not pile up over a long ingredient list.
+	servings := max(recipe.Servings, 1)
+	report.PerServing = roundFacts(scaleFacts(report.Total, 1/float64(servings)))
+	report.Total = roundFacts(report.Total)
+	return report
+}
+
+// ingredientNutrition describes one ingredient line, with its facts rounded
+// for display, and also returns the unrounded facts for the recipe totals.
+func ingredientNutrition(ingredient models.Ingredient) (models.IngredientNutrition, models.NutritionFacts) {
+	line := models.IngredientNutrition{Text: ingredient.Text}
+
+	entry := lookupNutrition(ingredient.Name)
+	if entry == nil {
+		line.Status = models.NutritionUnrecognized
+		return line, models.NutritionFacts{}
+	}
+	line.Matched = entry.Name
+
+	var facts models.NutritionFacts
+	grams, ok := ingredientGrams(ingredient, entry)
+	switch {
+	case ok:
+		facts = scaleFacts(entry.Per100g, grams/100)
+		line.Status = models.NutritionComputed
+		line.Grams = math.Round(grams)
+		line.Facts = roundFacts(facts)
+	case ingredient.Quantity == 0 && entry.ToTaste:
+		line.Status = models.NutritionToTaste
+	default:
+		line.Status = models.NutritionUnmeasured
+	}
+	return line, facts
+}
+
+// lookupNutrition matches aliases at word starts, so "chive" finds "Chives"
+// but "oil" does not find "boil".
+func lookupNutrition(name string) *nutritionEntry {
+	padded := " " + strings.ToLower(name)
+	for _, a := range nutritionAliases {
+		if strings.Contains(padded, " "+a.alias) {
+			return a.entry
+		}
+	}
+	return nil
+}
+
+func ingredientGrams(ingredient models.Ingredient, entry *nutritionEntry) (float64, bool) {
+	if ingredient.Quantity == 0 {
+		return 0, false
+	}
+
+	unit, known := unitAliases[ingredient.Unit]
+	switch {
+	case !known || unit == unitClove || unit == unitCan:
+		if entry.GramsPerPiece == 0 {
+			return 0, false
+		}
+		return ingredient.Quantity * entry.GramsPerPiece * pieceSize(ingredient.Name), true
+	case unit == unitPinch:
+		return ingredient.Quantity * gramsPerPinch, true
+	case unit.dimension == dimensionMass:
+		return ingredient.Quantity * unit.base, true
+	case unit.dimension == dimensionVolume && entry.GramsPerMl > 0:
+		return ingredient.Quantity * unit.base * entry.GramsPerMl, true
+	}
+	return 0, false
+}
+
+func pieceSize(name string) float64 {
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		if size, ok := pieceSizes[word]; ok {
+			return size
+		}
+	}
+	return 1
+}
+
+func addFacts(a, b models.NutritionFacts) models.NutritionFacts {
+	return models.NutritionFacts{
+		Calories: a.Calories + b.Calories,
+		Carbs:    a.Carbs + b.Carbs,
+		Protein:  a.Protein + b.Protein,
+		Fiber:    a.Fiber + b.Fiber,
+	}
+}
+
+func scaleFacts(facts models.NutritionFacts, factor float64) models.NutritionFacts {
+	return models.NutritionFacts{
+		Calories: facts.Calories * factor,
+		Carbs:    facts.Carbs * factor,
+		Protein:  facts.Protein * factor,
+		Fiber:    facts.Fiber * factor,
+	}
+}
+
+func roundFacts(facts models.NutritionFacts) models.NutritionFacts {
+	return models.NutritionFacts{
+		Calories: math.Round(facts.Calories),
+		Carbs:    math.Round(facts.Carbs*10) / 10,
+		Protein:  math.Round(facts.Protein*10) / 10,
+		Fiber:    math.Round(facts.Fiber*10) / 10,
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func TestCalculateNutritionRoundsTotalsOnce(t *testing.T) {
+	ingredients := []string{"7 g butter", "7 g butter", "7 g butter", "7 g butter", "7 g butter", "7 g butter"}
+	entry := lookupNutrition("butter")
+	if entry == nil {
+		t.Fatal("no nutrition entry for butter")
+	}
+
+	report := calculateNutrition(models.Recipe{Ingredients: ingredients, Servings: 4})
+
+	raw := scaleFacts(entry.Per100g, 42.0/100)
+	if want := roundFacts(raw); report.Total != want {
+		t.Errorf("Total = %+v, want %+v", report.Total, want)
+	}
+	if want := roundFacts(scaleFacts(raw, 0.25)); report.PerServing != want {
+		t.Errorf("PerServing = %+v, want %+v", report.PerServing, want)
+	}
+	if want := roundFacts(scaleFacts(entry.Per100g, 7.0/100)); report.Ingredients[0].Facts != want {
+		t.Errorf("line Facts = %+v, want %+v", report.Ingredients[0].Facts, want)
+	}
+}
@@ -2,30 +2,45 @@ package service
 
 import (
 	"errors"
+	"sort"
 	"time"
 
+	"github.com/williamdumont/potato-demo/analytics"
+	"github.com/williamdumont/potato-demo/freshness"
 	"github.com/williamdumont/potato-demo/models"
 	"github.com/williamdumont/potato-demo/storage"
 )
 
 var (
-	ErrInvalidPotato = errors.New("invalid potato data")
-	ErrInvalidWeight = errors.New("weight must be positive")
-	ErrInvalidPrice  = errors.New("price must be non-negative")
+	ErrInvalidPotato   = errors.New("invalid potato data")
+	ErrInvalidWeight   = errors.New("weight must be positive")
+	ErrInvalidPrice    = errors.New("price must be non-negative")
+	ErrInvalidCost     = errors.New("purchase cost must be non-negative")
+	ErrDuplicatePotato = errors.New("a potato with this id already exists")
 )
 
 type PotatoService struct {
-	storage storage.Storage
+	storage    storage.Storage
+	freshness  *freshness.Engine
+	warehouses *WarehouseService
 }
 
-func NewPotatoService(storage storage.Storage) *PotatoService {
+func NewPotatoService(storage storage.Storage, freshness *freshness.Engine, warehouses *WarehouseService) *PotatoService {
 	return &PotatoService{
-		storage: storage,
+		storage:    storage,
+		freshness:  freshness,
+		warehouses: warehouses,
 	}
 }
 
 func (s *PotatoService) CreatePotato(potato models.Potato) (models.Potato, error) {
-	if err := s.validatePotato(potato); err != nil {
+	potato, err := s.normalizePotato(potato)
+	if err != nil {
+		return models.Potato{}, err
+	}
+
+	potato, err = applyLot(s.storage, potato, s.currentPotato(potato.ID))
+	if err != nil {
 		return models.Potato{}, err
 	}
 
@@ -33,11 +48,29 @@ func (s *PotatoService) CreatePotato(potato models.Potato) (models.Potato, error
 		potato.HarvestDate = time.Now()
 	}
 
-	if err := s.storage.AddPotato(potato); err != nil {
-		return models.Potato{}, err
+	return s.warehouses.Place(potato, s.storage.AddPotato)
+}
+
+// ReceivePotatoes adds a delivery of new potatoes to stock. They are
+// validated and placed together, so either all are stored or none is.
+func (s *PotatoService) ReceivePotatoes(potatoes []models.Potato) ([]models.Potato, error) {
+	received := make([]models.Potato, 0, len(potatoes))
+	for _, potato := range potatoes {
+		potato, err := s.normalizePotato(potato)
+		if err != nil {
+			return nil, err
+		}
+		potato, err = applyLot(s.storage, potato, nil)
+		if err != nil {
+			return nil, err
+		}
+		if potato.HarvestDate.IsZero() {
+			potato.HarvestDate = time.Now()
+		}
+		received = append(received, potato)
 	}
 
-	return potato, nil
+	return s.warehouses.PlaceAll(received, s.storage.AddPotato)
 }
 
 func (s *PotatoService) GetPotato(id string) (models.Potato, error) {
@@ -48,129 +81,195 @@ func (s *PotatoService) GetAllPotatoes() []models.Potato {
 	return s.storage.GetAllPotatoes()
 }
 
+// UpdatePotato replaces a potato. An omitted purchase_cost keeps the one
+// recorded, so a PUT that does not know the cost cannot wipe it out.
 func (s *PotatoService) UpdatePotato(id string, potato models.Potato) (models.Potato, error) {
-	if err := s.validatePotato(potato); err != nil {
+	potato.ID = id
+	current := s.currentPotato(id)
+	if current != nil && potato.PurchaseCost == 0 {
+		potato.PurchaseCost = current.PurchaseCost
+	}
+	potato, err := s.normalizePotato(potato)
+	if err != nil {
 		return models.Potato{}, err
 	}
-
-	if err := s.storage.UpdatePotato(id, potato); err != nil {
+	potato, err = applyLot(s.storage, potato, current)
+	if err != nil {
 		return models.Potato{}, err
 	}
 
-	return potato, nil
+	return s.warehouses.Place(potato, func(potato models.Potato) error {
+		return s.storage.UpdatePotato(id, potato)
+	})
+}
+
+// DeletePotato removes a potato from stock for the given reason, which
+// defaults to sold. A quarantined potato can only leave as waste.
+func (s *PotatoService) DeletePotato(id, reason string) error {
+	removal, err := parseRemovalReason(reason)
+	if err != nil {
+		return err
+	}
+	return s.storage.DeletePotato(id, removal)
 }
 
-func (s *PotatoService) DeletePotato(id string) error {
-	return s.storage.DeletePotato(id)
+// currentPotato returns the stored potato with this ID, or nil.
+func (s *PotatoService) currentPotato(id string) *models.Potato {
+	potato, err := s.storage.GetPotato(id)
+	if err != nil {
+		return nil
+	}
+	return &potato
 }
 
+// GetPotatoesByVariety accepts any catalogued name or alias; a variety the
+// catalog does not know simply has no stock.
 func (s *PotatoService) GetPotatoesByVariety(variety string) []models.Potato {
+	if name, err := canonicalVariety(s.storage, variety); err == nil {
+		variety = name
+	}
 	return s.storage.GetPotatoesByVariety(variety)
 }
 
-func (s *PotatoService) GetInventorySummary() models.InventorySummary {
-	potatoes := s.storage.GetAllPotatoes()
-
-	varietyMap := make(map[string]*models.InventoryItem)
-	totalWeight := 0.0
-	totalValue := 0.0
-
-	for _, potato := range potatoes {
-		totalWeight += potato.Weight
-		totalValue += potato.Price
-
-		if item, exists := varietyMap[potato.Variety]; exists {
-			item.TotalQuantity++
-			item.TotalWeight += potato.Weight
-			item.AveragePrice = (item.AveragePrice*float64(item.TotalQuantity-1) + potato.Price) / float64(item.TotalQuantity)
-		} else {
-			varietyMap[potato.Variety] = &models.InventoryItem{
-				Variety:       potato.Variety,
-				TotalQuantity: 1,
-				TotalWeight:   potato.Weight,
-				AveragePrice:  potato.Price,
-			}
+// GetInventorySummary reads the totals storage maintains, so its cost
+// depends on the number of varieties rather than potatoes. Figures are
+// rounded to hide the residue of adding and removing float values. Limiting
+// the summary to one warehouse recounts the potatoes stored there.
+func (s *PotatoService) GetInventorySummary(warehouse string) (models.InventorySummary, error) {
+	if warehouse != "" {
+		if _, err := s.storage.GetWarehouse(warehouse); err != nil {
+			return models.InventorySummary{}, ErrUnknownWarehouse
 		}
 	}
+	aggregates := s.storage.GetInventoryAggregates()
+	byWarehouse := warehouseInventory(s.storage, aggregates, warehouse)
+	if warehouse != "" {
+		aggregates = s.warehouseAggregates(warehouse)
+	}
 
-	byVariety := make([]models.InventoryItem, 0, len(varietyMap))
-	for _, item := range varietyMap {
-		byVariety = append(byVariety, *item)
+	byVariety := make([]models.InventoryItem, 0, len(aggregates.ByVariety))
+	for variety, totals := range aggregates.ByVariety {
+		byVariety = append(byVariety, models.InventoryItem{
+			Variety:       variety,
+			TotalQuantity: totals.Count,
+			TotalWeight:   roundWeight(totals.Weight),
+			AveragePrice:  roundPrice(totals.Value / float64(totals.Count)),
+		})
 	}
+	sort.Slice(byVariety, func(i, j int) bool {
+		return byVariety[i].Variety < byVariety[j].Variety
+	})
 
 	return models.InventorySummary{
-		TotalPotatoes: len(potatoes),
-		TotalWeight:   totalWeight,
-		TotalValue:    totalValue,
+		TotalPotatoes: aggregates.Total.Count,
+		TotalWeight:   roundWeight(aggregates.Total.Weight),
+		TotalValue:    roundPrice(aggregates.Total.Value),
 		ByVariety:     byVariety,
-	}
+		Warehouse:     warehouse,
+		ByWarehouse:   byWarehouse,
+	}, nil
 }
 
-func (s *PotatoService) GetAnalytics() models.PotatoAnalytics {
-	potatoes := s.storage.GetAllPotatoes()
-
-	if len(potatoes) == 0 {
-		return models.PotatoAnalytics{}
-	}
-
-	varietyCount := make(map[string]int)
-	totalWeight := 0.0
-	premiumCount := 0
-	totalValue := 0.0
-
-	for _, potato := range potatoes {
-		varietyCount[potato.Variety]++
-		totalWeight += potato.Weight
-		totalValue += potato.Price
-		if potato.Quality == string(models.Premium) {
-			premiumCount++
+// warehouseAggregates totals the potatoes in one warehouse's locations.
+func (s *PotatoService) warehouseAggregates(warehouse string) models.InventoryAggregates {
+	locations := make(map[string]bool)
+	for _, location := range s.storage.GetAllLocations() {
+		if location.WarehouseID == warehouse {
+			locations[location.ID] = true
 		}
 	}
 
-	mostPopular := ""
-	maxCount := 0
-	for variety, count := range varietyCount {
-		if count > maxCount {
-			maxCount = count
-			mostPopular = variety
+	aggregates := models.InventoryAggregates{ByVariety: make(map[string]models.AggregateTotals)}
+	for _, potato := range s.storage.GetAllPotatoes() {
+		if !locations[potato.LocationID] {
+			continue
+		}
+		variety := aggregates.ByVariety[potato.Variety]
+		for _, totals := range []*models.AggregateTotals{&aggregates.Total, &variety} {
+			totals.Count++
+			totals.Weight += potato.Weight
+			totals.Value += potato.Price
 		}
+		aggregates.ByVariety[potato.Variety] = variety
 	}
+	return aggregates
+}
 
-	return models.PotatoAnalytics{
-		MostPopularVariety: mostPopular,
-		AverageWeight:      totalWeight / float64(len(potatoes)),
-		PremiumPercentage:  float64(premiumCount) / float64(len(potatoes)) * 100,
-		TotalValue:         totalValue,
+// GetAnalytics describes current stock. The basic figures come from the
+// maintained totals; only metrics and breakdowns need a scan of every potato.
+func (s *PotatoService) GetAnalytics(query models.AnalyticsQuery) (models.PotatoAnalytics, error) {
+	query, err := analytics.Normalize(query)
+	if err != nil {
+		return models.PotatoAnalytics{}, err
+	}
+	if len(query.Metrics) == 0 && len(query.GroupBy) == 0 {
+		return analytics.FromAggregates(s.storage.GetInventoryAggregates()), nil
 	}
+	return analytics.Compute(s.storage.GetAllPotatoes(), query, time.Now()), nil
+}
+
+func (s *PotatoService) FreshnessRules() freshness.Rules {
+	return s.freshness.Rules()
 }
 
-func (s *PotatoService) CalculateFreshness(potato models.Potato) string {
-	daysSinceHarvest := int(time.Since(potato.HarvestDate).Hours() / 24)
+// CalculateFreshness applies the freshness rules for the potato's variety
+// and storage condition. A non-empty storageCondition overrides the one
+// recorded on the potato, to answer "what if it were moved to the cellar".
+func (s *PotatoService) CalculateFreshness(potato models.Potato, storageCondition string) (models.FreshnessReport, error) {
+	if storageCondition == "" {
+		storageCondition = potato.StorageCondition
+	}
 
-	switch {
-	case daysSinceHarvest <= 7:
-		return "Fresh"
-	case daysSinceHarvest <= 30:
-		return "Good"
-	case daysSinceHarvest <= 90:
-		return "Fair"
-	default:
-		return "Old"
+	input := freshness.Input{
+		Variety:     potato.Variety,
+		Storage:     storageCondition,
+		HarvestDate: potato.HarvestDate,
+	}
+	if variety, err := resolveVariety(s.storage, potato.Variety); err == nil {
+		input.CatalogShelfLifeDays = variety.ShelfLifeDays
 	}
+
+	report, err := s.freshness.Evaluate(input)
+	if err != nil {
+		return models.FreshnessReport{}, err
+	}
+	report.ID = potato.ID
+	report.Variety = potato.Variety
+	return report, nil
 }
 
-func (s *PotatoService) validatePotato(potato models.Potato) error {
+// normalizePotato validates the potato and replaces its variety with the
+// catalog's canonical name, so "yukon" and "Yukon Gold" are stocked together.
+func (s *PotatoService) normalizePotato(potato models.Potato) (models.Potato, error) {
 	if potato.ID == "" || potato.Variety == "" {
-		return ErrInvalidPotato
+		return models.Potato{}, ErrInvalidPotato
 	}
 
 	if potato.Weight <= 0 {
-		return ErrInvalidWeight
+		return models.Potato{}, ErrInvalidWeight
 	}
 
 	if potato.Price < 0 {
-		return ErrInvalidPrice
+		return models.Potato{}, ErrInvalidPrice
+	}
+
+	if potato.PurchaseCost < 0 {
+		return models.Potato{}, ErrInvalidCost
 	}
 
-	return nil
+	variety, err := canonicalVariety(s.storage, potato.Variety)
+	if err != nil {
+		return models.Potato{}, err
+	}
+	potato.Variety = variety
+
+	if potato.StorageCondition != "" {
+		condition, err := s.freshness.StorageCondition(potato.StorageCondition)
+		if err != nil {
+			return models.Potato{}, err
+		}
+		potato.StorageCondition = condition
+	}
+
+	return potato, nil
 }
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+const defaultPriceSeriesDays = 30
+
+var ErrInvalidDateRange = errors.New("from must not be after to")
+
+// PriceHistory returns every price a potato has been listed at, oldest
+// first, including potatoes since deleted.
+func (s *PricingService) PriceHistory(id string) ([]models.PriceRecord, error) {
+	history := s.storage.GetPriceHistory(id)
+	if len(history) == 0 {
+		if _, err := s.storage.GetPotato(id); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// PriceSeries buckets the daily per-kg price aggregates of one variety, or
+// of every priced variety, into the query's interval. From and To are whole
+// UTC days, inclusive; they default to the last 30 days.
+func (s *PricingService) PriceSeries(query models.PriceSeriesQuery) (models.PriceAnalytics, error) {
+	if query.Interval == "" {
+		query.Interval = models.DailyInterval
+	}
+	if query.Type == "" {
+		query.Type = models.OHLCSeries
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, 1-defaultPriceSeriesDays)
+	}
+	if query.To.Before(query.From) {
+		return models.PriceAnalytics{}, ErrInvalidDateRange
+	}
+
+	var varieties []string
+	if query.Variety != "" {
+		variety, err := canonicalVariety(s.storage, query.Variety)
+		if err != nil {
+			return models.PriceAnalytics{}, err
+		}
+		varieties = []string{variety}
+	} else {
+		varieties = s.storage.GetPricedVarieties()
+		sort.Strings(varieties)
+	}
+
+	analytics := models.PriceAnalytics{
+		Type:     query.Type,
+		Interval: query.Interval,
+		Metric:   "price_per_kg",
+		Currency: s.engine.Currency(),
+		From:     query.From.Format("2006-01-02"),
+		To:       query.To.Format("2006-01-02"),
+		Series:   []models.PriceSeries{},
+	}
+	for _, variety := range varieties {
+		points := pricePoints(s.storage.GetDailyPrices(variety), query)
+		if len(points) == 0 && query.Variety == "" {
+			continue
+		}
+		analytics.Series = append(analytics.Series, models.PriceSeries{
+			Variety: variety,
+			Points:  points,
+		})
+	}
+	return analytics, nil
+}
+
+func pricePoints(days []models.DailyPrice, query models.PriceSeriesQuery) []models.PricePoint {
+	points := []models.PricePoint{}
+	var total float64
+	for _, day := range days {
+		if day.Day.Before(query.From) || day.Day.After(query.To) {
+			continue
+		}
+		start, label := priceBucket(day.Day, query.Interval)
+
+		n := len(points)
+		if n == 0 || !points[n-1].Time.Equal(start) {
+			if n > 0 {
+				points[n-1].Average = roundPrice(total / float64(points[n-1].Changes))
+			}
+			points = append(points, models.PricePoint{
+				Time:  start,
+				Label: label,
+				Open:  day.Open,
+				High:  day.High,
+				Low:   day.Low,
+			})
+			total = 0
+			n++
+		}
+		point := &points[n-1]
+		point.High = max(point.High, day.High)
+		point.Low = min(point.Low, day.Low)
+		point.Close = day.Close
+		point.Changes += day.Count
+		total += day.Total
+	}
+	if n := len(points); n > 0 {
+		points[n-1].Average = roundPrice(total / float64(points[n-1].Changes))
+	}
+
+	if query.Type == models.AverageSeries {
+		for i := range points {
+			points[i].Open, points[i].High, points[i].Low, points[i].Close = 0, 0, 0, 0
+		}
+	}
+	return points
+}
+
+// priceBucket returns the start and label of the interval containing day.
+// Weeks start on Monday and are labelled with their ISO week.
+func priceBucket(day time.Time, interval models.PriceInterval) (time.Time, string) {
+	switch interval {
+	case models.WeeklyInterval:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		year, week := start.ISOWeek()
+		return start, fmt.Sprintf("%d-W%02d", year, week)
+	case models.MonthlyInterval:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.Format("2006-01")
+	default:
+		return day, day.Format("2006-01-02")
+	}
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+func roundWeight(weight float64) float64 {
+	return math.Round(weight*1000) / 1000
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/pricing"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+type PricingService struct {
+	storage  storage.Storage
+	potatoes *PotatoService
+	engine   *pricing.Engine
+}
+
+func NewPricingService(storage storage.Storage, potatoes *PotatoService, engine *pricing.Engine) *PricingService {
+	return &PricingService{
+		storage:  storage,
+		potatoes: potatoes,
+		engine:   engine,
+	}
+}
+
+func (s *PricingService) QuotePrice(id string) (models.PriceQuote, error) {
+	potato, err := s.storage.GetPotato(id)
+	if err != nil {
+		return models.PriceQuote{}, err
+	}
+	return s.Quote(potato, time.Now()), nil
+}
+
+// Quote prices a potato, stored or not, as of the given time.
+func (s *PricingService) Quote(potato models.Potato, at time.Time) models.PriceQuote {
+	input := pricing.Input{
+		Potato: potato,
+		At:     at,
+	}
+	if report, err := s.potatoes.CalculateFreshness(potato, ""); err == nil {
+		input.Freshness = report.Freshness
+	}
+	if variety, err := resolveVariety(s.storage, potato.Variety); err == nil {
+		input.CatalogBasePricePerKg = variety.BasePrice
+	}
+	return s.engine.Quote(input)
+}
+
+// Reprice brings every potato's price in line with the pricing rules and
+// reports the prices that changed.
+func (s *PricingService) Reprice() []models.PriceChange {
+	now := time.Now()
+	var changes []models.PriceChange
+	for _, potato := range s.storage.GetAllPotatoes() {
+		quote := s.Quote(potato, now)
+		if math.Abs(quote.Price-potato.Price) < 0.005 {
+			continue
+		}
+
+		if err := s.storage.SetPotatoPrice(potato.ID, quote.Price); err != nil {
+			continue
+		}
+		changes = append(changes, models.PriceChange{
+			PotatoID: potato.ID,
+			Variety:  potato.Variety,
+			OldPrice: potato.Price,
+			NewPrice: quote.Price,
+		})
+	}
+	return changes
+}
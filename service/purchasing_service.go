@@ -0,0 +1,525 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var (
+	ErrInvalidSupplier        = errors.New("supplier needs an id of 1-64 characters without spaces or slashes, a name of at most 100 characters and lead_time_days between 0 and 365")
+	ErrDuplicateSupplier      = errors.New("a supplier with this id already exists")
+	ErrUnknownSupplier        = errors.New("supplier does not exist")
+	ErrInvalidPurchaseOrder   = errors.New("purchase order needs an id of 1-64 characters without spaces or slashes, a supplier_id and 1-100 lines")
+	ErrInvalidOrderLine       = errors.New("each line needs a variety, a quality of Premium, Standard or Economy, a positive weight_kg and a non-negative unit_cost_per_kg")
+	ErrInvalidExpectedDate    = errors.New("expected_date cannot be before the order date")
+	ErrDuplicatePurchaseOrder = errors.New("a purchase order with this id already exists")
+	ErrPurchaseOrderClosed    = errors.New("purchase order is already received or closed")
+	ErrInvalidReceipt         = errors.New("receipt needs lines, each with a line number and potatoes of positive weight and valid quality")
+	ErrUnknownOrderLine       = errors.New("purchase order has no such line")
+	ErrOverReceipt            = errors.New("delivery exceeds the ordered weight by more than 5%")
+	ErrInvalidReceiptTime     = errors.New("received_at must be between the order date and now")
+	ErrInvalidOrderStatus     = errors.New("status must be open, partially_received, received or closed")
+)
+
+const (
+	maxSupplierField = 100
+	maxLeadTimeDays  = 365
+	maxOrderLines    = 100
+	maxOrderNote     = 500
+
+	// Potatoes arrive whole, so a line counts as filled once it is within
+	// receiptTolerance of its weight and may be over-delivered by as much.
+	receiptTolerance = 0.05
+)
+
+var receiptCounter atomic.Int64
+
+// qualityRanks orders grades so deliveries can be compared with what was
+// ordered.
+var qualityRanks = map[string]int{
+	string(models.Economy):  1,
+	string(models.Standard): 2,
+	string(models.Premium):  3,
+}
+
+// PurchasingService keeps suppliers and purchase orders and receives
+// deliveries into stock. Receipts run under one lock, so two deliveries
+// against the same order cannot both fill its last line.
+type PurchasingService struct {
+	storage  storage.Storage
+	potatoes *PotatoService
+	pricing  *PricingService
+
+	mu sync.Mutex
+}
+
+func NewPurchasingService(storage storage.Storage, potatoes *PotatoService, pricing *PricingService) *PurchasingService {
+	return &PurchasingService{
+		storage:  storage,
+		potatoes: potatoes,
+		pricing:  pricing,
+	}
+}
+
+func (s *PurchasingService) CreateSupplier(supplier models.Supplier) (models.Supplier, error) {
+	supplier.ID = strings.TrimSpace(supplier.ID)
+	supplier, err := normalizeSupplier(supplier)
+	if err != nil {
+		return models.Supplier{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetSupplier(supplier.ID); err == nil {
+		return models.Supplier{}, ErrDuplicateSupplier
+	}
+	supplier.CreatedAt = time.Now()
+	if err := s.storage.AddSupplier(supplier); err != nil {
+		return models.Supplier{}, err
+	}
+	return supplier, nil
+}
+
+func (s *PurchasingService) GetSupplier(id string) (models.Supplier, error) {
+	return s.storage.GetSupplier(id)
+}
+
+// GetAllSuppliers lists suppliers by ID.
+func (s *PurchasingService) GetAllSuppliers() []models.Supplier {
+	suppliers := s.storage.GetAllSuppliers()
+	sort.Slice(suppliers, func(i, j int) bool {
+		return suppliers[i].ID < suppliers[j].ID
+	})
+	return suppliers
+}
+
+// UpdateSupplier changes a supplier's details. Orders already placed keep
+// their expected dates.
+func (s *PurchasingService) UpdateSupplier(id string, supplier models.Supplier) (models.Supplier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.storage.GetSupplier(id)
+	if err != nil {
+		return models.Supplier{}, err
+	}
+	supplier.ID = existing.ID
+	supplier, err = normalizeSupplier(supplier)
+	if err != nil {
+		return models.Supplier{}, err
+	}
+	supplier.CreatedAt = existing.CreatedAt
+	if err := s.storage.UpdateSupplier(id, supplier); err != nil {
+		return models.Supplier{}, err
+	}
+	return supplier, nil
+}
+
+// CreatePurchaseOrder places an order with a supplier. Lines are numbered
+// from 1 in the order given. Without an expected_date, delivery is expected
+// after the supplier's lead time.
+func (s *PurchasingService) CreatePurchaseOrder(order models.PurchaseOrder) (models.PurchaseOrder, error) {
+	order.ID = strings.TrimSpace(order.ID)
+	order.Note = strings.TrimSpace(order.Note)
+	if !validID(order.ID) || order.SupplierID == "" || len(order.Lines) == 0 || len(order.Lines) > maxOrderLines || len(order.Note) > maxOrderNote {
+		return models.PurchaseOrder{}, ErrInvalidPurchaseOrder
+	}
+	supplier, err := s.storage.GetSupplier(order.SupplierID)
+	if err != nil {
+		return models.PurchaseOrder{}, ErrUnknownSupplier
+	}
+
+	order.OrderedKg, order.TotalCost = 0, 0
+	for i, line := range order.Lines {
+		variety, err := canonicalVariety(s.storage, line.Variety)
+		if err != nil {
+			return models.PurchaseOrder{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		quality, ok := canonicalQuality(line.Quality)
+		if !ok || line.WeightKg <= 0 || line.UnitCostPerKg < 0 {
+			return models.PurchaseOrder{}, fmt.Errorf("line %d: %w", i+1, ErrInvalidOrderLine)
+		}
+		order.Lines[i] = models.PurchaseOrderLine{
+			Line:          i + 1,
+			Variety:       variety,
+			Quality:       quality,
+			WeightKg:      roundWeight(line.WeightKg),
+			UnitCostPerKg: roundPrice(line.UnitCostPerKg),
+		}
+		order.OrderedKg += order.Lines[i].WeightKg
+		order.TotalCost += order.Lines[i].WeightKg * order.Lines[i].UnitCostPerKg
+	}
+	order.OrderedKg = roundWeight(order.OrderedKg)
+	order.TotalCost = roundPrice(order.TotalCost)
+
+	order.OrderedAt = time.Now()
+	if order.ExpectedDate.IsZero() {
+		order.ExpectedDate = order.OrderedAt.AddDate(0, 0, supplier.LeadTimeDays)
+	} else if order.ExpectedDate.Before(startOfDay(order.OrderedAt)) {
+		return models.PurchaseOrder{}, ErrInvalidExpectedDate
+	}
+	order.Status = models.PurchaseOrderOpen
+	order.ReceivedKg = 0
+	order.ReceivedAt = nil
+	order.ClosedAt = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetPurchaseOrder(order.ID); err == nil {
+		return models.PurchaseOrder{}, ErrDuplicatePurchaseOrder
+	}
+	if err := s.storage.AddPurchaseOrder(order); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return order, nil
+}
+
+func (s *PurchasingService) GetPurchaseOrder(id string) (models.PurchaseOrder, error) {
+	return s.storage.GetPurchaseOrder(id)
+}
+
+// GetPurchaseOrders lists matching orders most recently placed first.
+func (s *PurchasingService) GetPurchaseOrders(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	switch filter.Status {
+	case "", models.PurchaseOrderOpen, models.PurchaseOrderPartial, models.PurchaseOrderReceived, models.PurchaseOrderClosed:
+	default:
+		return nil, ErrInvalidOrderStatus
+	}
+
+	orders := []models.PurchaseOrder{}
+	for _, order := range s.storage.GetAllPurchaseOrders() {
+		if filter.SupplierID != "" && order.SupplierID != filter.SupplierID {
+			continue
+		}
+		if filter.Status != "" && order.Status != filter.Status {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].OrderedAt.Equal(orders[j].OrderedAt) {
+			return orders[i].OrderedAt.After(orders[j].OrderedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders, nil
+}
+
+// ClosePurchaseOrder stops expecting whatever an order has not yet
+// delivered.
+func (s *PurchasingService) ClosePurchaseOrder(id string) (models.PurchaseOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, err := s.storage.GetPurchaseOrder(id)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if order.Status == models.PurchaseOrderReceived || order.Status == models.PurchaseOrderClosed {
+		return models.PurchaseOrder{}, ErrPurchaseOrderClosed
+	}
+	now := time.Now()
+	order.Status = models.PurchaseOrderClosed
+	order.ClosedAt = &now
+	if err := s.storage.UpdatePurchaseOrder(id, order); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return order, nil
+}
+
+// Receive books a delivery against an order and adds the potatoes to
+// stock. Each potato is costed at its line's unit cost, priced by the
+// pricing engine and named after the order and line, e.g. po-1001-2-7.
+// Either the whole delivery is stored or, if any potato is invalid or does
+// not fit, none of it is. The order is received once every line is filled,
+// and partially received until then.
+func (s *PurchasingService) Receive(orderID string, request models.ReceiptRequest) (models.Receipt, error) {
+	if len(request.Lines) == 0 {
+		return models.Receipt{}, ErrInvalidReceipt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, err := s.storage.GetPurchaseOrder(orderID)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+	if order.Status == models.PurchaseOrderReceived || order.Status == models.PurchaseOrderClosed {
+		return models.Receipt{}, ErrPurchaseOrderClosed
+	}
+	supplier, err := s.storage.GetSupplier(order.SupplierID)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+
+	now := time.Now()
+	receivedAt := now
+	if request.ReceivedAt != nil {
+		receivedAt = *request.ReceivedAt
+		if receivedAt.After(now) || receivedAt.Before(order.OrderedAt) {
+			return models.Receipt{}, ErrInvalidReceiptTime
+		}
+	}
+	harvestDate, origin := request.HarvestDate, supplier.Origin
+	if request.LotID != "" {
+		lot, err := s.storage.GetLot(request.LotID)
+		if err != nil {
+			return models.Receipt{}, ErrUnknownLot
+		}
+		harvestDate = lot.HarvestDate
+		if lot.Origin != "" {
+			origin = lot.Origin
+		}
+	}
+	if harvestDate.IsZero() {
+		harvestDate = receivedAt
+	}
+
+	receipt := models.Receipt{
+		PurchaseOrderID: order.ID,
+		SupplierID:      order.SupplierID,
+		ReceivedAt:      receivedAt,
+		OnTime:          receivedAt.Before(startOfDay(order.ExpectedDate).AddDate(0, 0, 1)),
+		LotID:           request.LotID,
+		Lines:           []models.ReceiptLine{},
+	}
+	var potatoes []models.Potato
+	for _, item := range request.Lines {
+		index := slices.IndexFunc(order.Lines, func(line models.PurchaseOrderLine) bool {
+			return line.Line == item.Line
+		})
+		if index < 0 {
+			return models.Receipt{}, fmt.Errorf("line %d: %w", item.Line, ErrUnknownOrderLine)
+		}
+		if len(item.Potatoes) == 0 {
+			return models.Receipt{}, fmt.Errorf("line %d: %w", item.Line, ErrInvalidReceipt)
+		}
+		line := &order.Lines[index]
+
+		received := models.ReceiptLine{
+			Line:      line.Line,
+			Variety:   line.Variety,
+			Ordered:   line.Quality,
+			Qualities: []models.QualityShare{},
+			PotatoIDs: []string{},
+		}
+		for _, delivered := range item.Potatoes {
+			quality := line.Quality
+			if delivered.Quality != "" {
+				var ok bool
+				if quality, ok = canonicalQuality(delivered.Quality); !ok {
+					return models.Receipt{}, fmt.Errorf("line %d: %w", item.Line, ErrInvalidReceipt)
+				}
+			}
+			if delivered.Weight <= 0 {
+				return models.Receipt{}, fmt.Errorf("line %d: %w", item.Line, ErrInvalidReceipt)
+			}
+
+			line.ReceivedCount++
+			line.ReceivedKg += delivered.Weight
+			potato := models.Potato{
+				ID:           fmt.Sprintf("%s-%d-%d", order.ID, line.Line, line.ReceivedCount),
+				Variety:      line.Variety,
+				Origin:       origin,
+				Weight:       delivered.Weight,
+				Quality:      quality,
+				HarvestDate:  harvestDate,
+				PurchaseCost: roundPrice(delivered.Weight * line.UnitCostPerKg),
+				LocationID:   request.LocationID,
+				LotID:        request.LotID,
+			}
+			if s.pricing != nil {
+				potato.Price = s.pricing.Quote(potato, now).Price
+			}
+			potatoes = append(potatoes, potato)
+
+			received.Potatoes++
+			received.WeightKg += potato.Weight
+			received.Cost += potato.PurchaseCost
+			received.Qualities = addQualityShare(received.Qualities, quality, potato.Weight, 1)
+			received.PotatoIDs = append(received.PotatoIDs, potato.ID)
+			if qualityRanks[quality] < qualityRanks[line.Quality] {
+				received.BelowSpec++
+			}
+		}
+		if line.ReceivedKg > line.WeightKg*(1+receiptTolerance) {
+			return models.Receipt{}, fmt.Errorf("line %d: %w", item.Line, ErrOverReceipt)
+		}
+		line.ReceivedKg = roundWeight(line.ReceivedKg)
+		received.WeightKg = roundWeight(received.WeightKg)
+		received.Cost = roundPrice(received.Cost)
+		receipt.Lines = append(receipt.Lines, received)
+	}
+
+	if _, err := s.potatoes.ReceivePotatoes(potatoes); err != nil {
+		return models.Receipt{}, err
+	}
+
+	order.ReceivedKg = 0
+	filled := true
+	for _, line := range order.Lines {
+		order.ReceivedKg += line.ReceivedKg
+		if line.ReceivedKg < line.WeightKg*(1-receiptTolerance) {
+			filled = false
+		}
+	}
+	order.ReceivedKg = roundWeight(order.ReceivedKg)
+	order.Status = models.PurchaseOrderPartial
+	if filled {
+		order.Status = models.PurchaseOrderReceived
+		order.ReceivedAt = &receivedAt
+	}
+	if err := s.storage.UpdatePurchaseOrder(order.ID, order); err != nil {
+		return models.Receipt{}, err
+	}
+	receipt.ID = fmt.Sprintf("rcv%d", receiptCounter.Add(1))
+	s.storage.AddReceipt(receipt)
+	return receipt, nil
+}
+
+// GetReceipts lists an order's deliveries oldest first.
+func (s *PurchasingService) GetReceipts(orderID string) ([]models.Receipt, error) {
+	if _, err := s.storage.GetPurchaseOrder(orderID); err != nil {
+		return nil, err
+	}
+	receipts := []models.Receipt{}
+	for _, receipt := range s.storage.GetReceipts() {
+		if receipt.PurchaseOrderID == orderID {
+			receipts = append(receipts, receipt)
+		}
+	}
+	return receipts, nil
+}
+
+func (s *PurchasingService) GetPerformance(id string) (models.SupplierPerformance, error) {
+	supplier, err := s.storage.GetSupplier(id)
+	if err != nil {
+		return models.SupplierPerformance{}, err
+	}
+	return s.performance(supplier, s.storage.GetAllPurchaseOrders(), s.storage.GetReceipts()), nil
+}
+
+// GetAllPerformance rates every supplier, best on-time rate first.
+func (s *PurchasingService) GetAllPerformance() []models.SupplierPerformance {
+	orders := s.storage.GetAllPurchaseOrders()
+	receipts := s.storage.GetReceipts()
+	performance := []models.SupplierPerformance{}
+	for _, supplier := range s.GetAllSuppliers() {
+		performance = append(performance, s.performance(supplier, orders, receipts))
+	}
+	sort.SliceStable(performance, func(i, j int) bool {
+		return performance[i].OnTimeRate > performance[j].OnTimeRate
+	})
+	return performance
+}
+
+// performance rates a supplier from its orders and deliveries. The fill
+// rate only counts orders that are no longer open, since the rest may still
+// be delivered. Lead time runs from the order to each delivery.
+func (s *PurchasingService) performance(supplier models.Supplier, orders []models.PurchaseOrder, receipts []models.Receipt) models.SupplierPerformance {
+	performance := models.SupplierPerformance{
+		Supplier: supplier,
+		Quality:  []models.QualityShare{},
+	}
+	orderedAt := make(map[string]time.Time)
+	completedOrdered, completedReceived := 0.0, 0.0
+	for _, order := range orders {
+		if order.SupplierID != supplier.ID {
+			continue
+		}
+		orderedAt[order.ID] = order.OrderedAt
+		performance.Orders++
+		performance.OrderedKg += order.OrderedKg
+		performance.ReceivedKg += order.ReceivedKg
+		switch order.Status {
+		case models.PurchaseOrderOpen, models.PurchaseOrderPartial:
+			performance.OpenOrders++
+		default:
+			completedOrdered += order.OrderedKg
+			completedReceived += order.ReceivedKg
+		}
+	}
+
+	leadTime, belowSpec := 0.0, 0
+	for _, receipt := range receipts {
+		if receipt.SupplierID != supplier.ID {
+			continue
+		}
+		performance.Deliveries++
+		if receipt.OnTime {
+			performance.OnTimeDeliveries++
+		}
+		if at, ok := orderedAt[receipt.PurchaseOrderID]; ok {
+			leadTime += receipt.ReceivedAt.Sub(at).Hours() / 24
+		}
+		for _, line := range receipt.Lines {
+			performance.Potatoes += line.Potatoes
+			belowSpec += line.BelowSpec
+			for _, share := range line.Qualities {
+				performance.Quality = addQualityShare(performance.Quality, share.Quality, share.WeightKg, share.Potatoes)
+			}
+		}
+	}
+
+	if performance.Deliveries > 0 {
+		performance.OnTimeRate = round2(float64(performance.OnTimeDeliveries) / float64(performance.Deliveries) * 100)
+		performance.AverageLeadTimeDays = round2(leadTime / float64(performance.Deliveries))
+	}
+	if completedOrdered > 0 {
+		performance.FillRate = round2(completedReceived / completedOrdered * 100)
+	}
+	if performance.Potatoes > 0 {
+		performance.BelowSpecRate = round2(float64(belowSpec) / float64(performance.Potatoes) * 100)
+		for i := range performance.Quality {
+			performance.Quality[i].Percent = round2(float64(performance.Quality[i].Potatoes) / float64(performance.Potatoes) * 100)
+		}
+	}
+	performance.OrderedKg = roundWeight(performance.OrderedKg)
+	performance.ReceivedKg = roundWeight(performance.ReceivedKg)
+	return performance
+}
+
+func normalizeSupplier(supplier models.Supplier) (models.Supplier, error) {
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	supplier.Origin = strings.TrimSpace(supplier.Origin)
+	supplier.Contact = strings.TrimSpace(supplier.Contact)
+	if !validID(supplier.ID) || supplier.Name == "" || supplier.LeadTimeDays < 0 || supplier.LeadTimeDays > maxLeadTimeDays {
+		return models.Supplier{}, ErrInvalidSupplier
+	}
+	for _, field := range []string{supplier.Name, supplier.Origin, supplier.Contact} {
+		if len(field) > maxSupplierField {
+			return models.Supplier{}, ErrInvalidSupplier
+		}
+	}
+	return supplier, nil
+}
+
+// addQualityShare adds potatoes of one quality to a distribution kept in
+// grade order, best first.
+func addQualityShare(shares []models.QualityShare, quality string, weightKg float64, potatoes int) []models.QualityShare {
+	for i := range shares {
+		if shares[i].Quality == quality {
+			shares[i].Potatoes += potatoes
+			shares[i].WeightKg = roundWeight(shares[i].WeightKg + weightKg)
+			return shares
+		}
+	}
+	shares = append(shares, models.QualityShare{Quality: quality, Potatoes: potatoes, WeightKg: roundWeight(weightKg)})
+	sort.Slice(shares, func(i, j int) bool {
+		return qualityRanks[shares[i].Quality] > qualityRanks[shares[j].Quality]
+	})
+	return shares
+}
+
+// startOfDay is midnight UTC on t's date.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
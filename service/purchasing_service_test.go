@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+// newTestPurchasingService has order po1 from supplier farm for 2 kg of
+// Standard Russets (line 1) and 1 kg of Premium Russets (line 2), and one
+// 5 kg bin to receive them into.
+func newTestPurchasingService(t *testing.T) (*PurchasingService, *storage.InMemoryStorage) {
+	t.Helper()
+	warehouses, store := newTestWarehouseService(t)
+	if _, err := warehouses.UpdateLocation("b", models.Location{Name: "Bin B", CapacityKg: 5}); err != nil {
+		t.Fatalf("resize location: %v", err)
+	}
+	if err := store.AddVariety(models.Variety{Name: "Russet", StarchLevel: models.StarchLevels[0], ShelfLifeDays: 60}); err != nil {
+		t.Fatalf("add variety: %v", err)
+	}
+	potatoes := NewPotatoService(store, warehouses.freshness, warehouses)
+	s := NewPurchasingService(store, potatoes, nil)
+	if _, err := s.CreateSupplier(models.Supplier{ID: "farm", Name: "Farm", LeadTimeDays: 2}); err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+	order := models.PurchaseOrder{ID: "po1", SupplierID: "farm", Lines: []models.PurchaseOrderLine{
+		{Variety: "Russet", Quality: "Standard", WeightKg: 2, UnitCostPerKg: 1},
+		{Variety: "Russet", Quality: "Premium", WeightKg: 1, UnitCostPerKg: 2},
+	}}
+	if _, err := s.CreatePurchaseOrder(order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	return s, store
+}
+
+func delivery(line int, weights ...float64) models.ReceiptLineRequest {
+	request := models.ReceiptLineRequest{Line: line}
+	for _, weight := range weights {
+		request.Potatoes = append(request.Potatoes, models.ReceivedPotato{Weight: weight})
+	}
+	return request
+}
+
+func TestReceiveOverReceiptTolerance(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []models.ReceiptLineRequest
+		wantErr    error
+		wantStatus models.PurchaseOrderStatus
+	}{
+		{name: "short delivery", lines: []models.ReceiptLineRequest{delivery(1, 1)}, wantStatus: models.PurchaseOrderPartial},
+		{name: "within 5% short fills the line", lines: []models.ReceiptLineRequest{delivery(1, 1.9), delivery(2, 0.96)}, wantStatus: models.PurchaseOrderReceived},
+		{name: "exactly 5% over", lines: []models.ReceiptLineRequest{delivery(1, 1.05, 1.05), delivery(2, 1)}, wantStatus: models.PurchaseOrderReceived},
+		{name: "more than 5% over", lines: []models.ReceiptLineRequest{delivery(1, 1.1, 1.1)}, wantErr: ErrOverReceipt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestPurchasingService(t)
+			_, err := s.Receive("po1", models.ReceiptRequest{Lines: tt.lines})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			order, _ := s.GetPurchaseOrder("po1")
+			if order.Status != tt.wantStatus {
+				t.Errorf("status %s, want %s", order.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReceiveRollsBackFailedDelivery(t *testing.T) {
+	tests := []struct {
+		name    string
+		request models.ReceiptRequest
+		wantErr error
+	}{
+		{
+			name:    "second line over-received",
+			request: models.ReceiptRequest{Lines: []models.ReceiptLineRequest{delivery(1, 1), delivery(2, 0.6, 0.6)}},
+			wantErr: ErrOverReceipt,
+		},
+		{
+			name:    "second line unknown",
+			request: models.ReceiptRequest{Lines: []models.ReceiptLineRequest{delivery(1, 1), delivery(3, 1)}},
+			wantErr: ErrUnknownOrderLine,
+		},
+		{
+			name:    "bin too small for the whole delivery",
+			request: models.ReceiptRequest{Lines: []models.ReceiptLineRequest{delivery(1, 0.5), delivery(2, 0.6)}, LocationID: "a"},
+			wantErr: ErrLocationFull,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestPurchasingService(t)
+			if _, err := s.Receive("po1", tt.request); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			if n := len(store.GetAllPotatoes()); n != 0 {
+				t.Errorf("%d potatoes stocked from a refused delivery", n)
+			}
+			if receipts, _ := s.GetReceipts("po1"); len(receipts) != 0 {
+				t.Errorf("%d receipts recorded", len(receipts))
+			}
+			order, _ := s.GetPurchaseOrder("po1")
+			if order.Status != models.PurchaseOrderOpen || order.ReceivedKg != 0 {
+				t.Errorf("order %s with %v kg received, want open with none", order.Status, order.ReceivedKg)
+			}
+			for _, line := range order.Lines {
+				if line.ReceivedKg != 0 || line.ReceivedCount != 0 {
+					t.Errorf("line %d: %v kg in %d potatoes received, want none", line.Line, line.ReceivedKg, line.ReceivedCount)
+				}
+			}
+
+			// The order can still be received in full afterwards, under
+			// the same potato IDs.
+			receipt, err := s.Receive("po1", models.ReceiptRequest{Lines: []models.ReceiptLineRequest{delivery(1, 2), delivery(2, 1)}})
+			if err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+			if id := receipt.Lines[0].PotatoIDs[0]; id != "po1-1-1" {
+				t.Errorf("first potato %s, want po1-1-1", id)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/taxonomy"
+)
+
+var ErrInvalidAllergen = errors.New("allergens must be dairy, gluten, eggs, peanuts, tree-nuts, soy, fish, shellfish, sesame or mustard")
+
+// Common names guests use for allergens. "nuts" covers both peanuts and tree
+// nuts, since that is what someone asking for it needs.
+var allergenAliases = map[string][]models.Allergen{
+	"milk":     {models.AllergenDairy},
+	"lactose":  {models.AllergenDairy},
+	"wheat":    {models.AllergenGluten},
+	"egg":      {models.AllergenEggs},
+	"peanut":   {models.AllergenPeanuts},
+	"tree-nut": {models.AllergenTreeNuts},
+	"nuts":     {models.AllergenPeanuts, models.AllergenTreeNuts},
+	"soya":     {models.AllergenSoy},
+	"seafood":  {models.AllergenFish, models.AllergenShellfish},
+}
+
+// NormalizeAllergens canonicalizes allergen names and expands aliases. Unknown
+// names are rejected so a typo in an exclusion list never silently lets an
+// allergen through.
+func (s *RecipeService) NormalizeAllergens(raw []models.Allergen) ([]models.Allergen, error) {
+	allergens := normalizeAllergens(raw)
+	for _, allergen := range allergens {
+		if !hasAllergen(models.Allergens, allergen) {
+			return nil, ErrInvalidAllergen
+		}
+	}
+	return allergens, nil
+}
+
+func normalizeAllergens(raw []models.Allergen) []models.Allergen {
+	allergens := make([]models.Allergen, 0, len(raw))
+	for _, value := range raw {
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(string(value))), "_", "-")
+		expanded, ok := allergenAliases[name]
+		if !ok {
+			expanded = []models.Allergen{models.Allergen(name)}
+		}
+		for _, allergen := range expanded {
+			if !hasAllergen(allergens, allergen) {
+				allergens = append(allergens, allergen)
+			}
+		}
+	}
+	return allergens
+}
+
+// mergeAllergens keeps any allergens declared on the recipe (for example a
+// "may contain" warning) and adds every allergen detected in the
+// ingredients, in catalog order.
+func mergeAllergens(declared []models.Allergen, ingredients []string) []models.Allergen {
+	detected := taxonomy.DetectAllergens(ingredients)
+	merged := make([]models.Allergen, 0, len(declared)+len(detected))
+	for _, allergen := range models.Allergens {
+		if hasAllergen(declared, allergen) || hasAllergen(detected, allergen) {
+			merged = append(merged, allergen)
+		}
+	}
+	for _, allergen := range declared {
+		if !hasAllergen(models.Allergens, allergen) {
+			merged = append(merged, allergen)
+		}
+	}
+	return merged
+}
+
+func excludesAllergens(recipe models.Recipe, excluded []models.Allergen) bool {
+	for _, allergen := range excluded {
+		if hasAllergen(recipe.Allergens, allergen) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAllergen(allergens []models.Allergen, allergen models.Allergen) bool {
+	for _, a := range allergens {
+		if a == allergen {
+			return true
+		}
+	}
+	return false
+}
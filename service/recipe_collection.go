@@ -0,0 +1,288 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var (
+	ErrInvalidOwner            = errors.New("user must be 1-64 characters without spaces or slashes")
+	ErrInvalidCollection       = errors.New("collection name must be 1-100 characters")
+	ErrDuplicateCollectionName = errors.New("you already have a collection with this name")
+	ErrDuplicateRecipe         = errors.New("recipe is already in the collection")
+	ErrRecipeNotInCollection   = errors.New("recipe is not in the collection")
+	ErrInvalidCollectionOrder  = errors.New("order must list every recipe in the collection exactly once")
+)
+
+const (
+	maxCollectionRecipes     = 200
+	maxCollectionDescription = 500
+)
+
+var collectionCounter atomic.Int64
+
+func (s *RecipeService) CreateCollection(owner string, collection models.Collection) (models.Collection, error) {
+	collection.ID = fmt.Sprintf("c%d", collectionCounter.Add(1))
+	collection.Owner = owner
+	collection.ShareToken = ""
+	collection, err := s.normalizeCollection(collection)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	collection.CreatedAt = time.Now()
+	collection.UpdatedAt = collection.CreatedAt
+	if err := s.storage.AddCollection(collection); err != nil {
+		return models.Collection{}, err
+	}
+	return collection, nil
+}
+
+func (s *RecipeService) GetCollections(owner string) []models.Collection {
+	collections := s.storage.GetCollectionsByOwner(owner)
+	sort.Slice(collections, func(i, j int) bool {
+		return strings.ToLower(collections[i].Name) < strings.ToLower(collections[j].Name)
+	})
+	return collections
+}
+
+func (s *RecipeService) GetCollection(owner, id string) (models.CollectionDetail, error) {
+	collection, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.CollectionDetail{}, err
+	}
+	return s.collectionDetail(collection), nil
+}
+
+// UpdateCollection replaces the name, description and recipe list. The
+// share link, if any, survives the update.
+func (s *RecipeService) UpdateCollection(owner, id string, update models.Collection) (models.Collection, error) {
+	existing, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	existing.Name = update.Name
+	existing.Description = update.Description
+	existing.RecipeIDs = update.RecipeIDs
+	return s.saveCollection(existing)
+}
+
+func (s *RecipeService) DeleteCollection(owner, id string) error {
+	if _, err := s.ownedCollection(owner, id); err != nil {
+		return err
+	}
+	return s.storage.DeleteCollection(id)
+}
+
+// AddRecipeToCollection inserts a recipe at a 1-based position; zero or a
+// position past the end appends it.
+func (s *RecipeService) AddRecipeToCollection(owner, id, recipeID string, position int) (models.Collection, error) {
+	collection, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if containsString(collection.RecipeIDs, recipeID) {
+		return models.Collection{}, ErrDuplicateRecipe
+	}
+	if position < 0 {
+		return models.Collection{}, errors.New("position must be zero or a positive integer")
+	}
+
+	index := len(collection.RecipeIDs)
+	if position > 0 && position <= index {
+		index = position - 1
+	}
+	ids := make([]string, 0, len(collection.RecipeIDs)+1)
+	ids = append(ids, collection.RecipeIDs[:index]...)
+	ids = append(ids, recipeID)
+	ids = append(ids, collection.RecipeIDs[index:]...)
+	collection.RecipeIDs = ids
+
+	return s.saveCollection(collection)
+}
+
+func (s *RecipeService) RemoveRecipeFromCollection(owner, id, recipeID string) (models.Collection, error) {
+	collection, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	ids := make([]string, 0, len(collection.RecipeIDs))
+	for _, existing := range collection.RecipeIDs {
+		if existing != recipeID {
+			ids = append(ids, existing)
+		}
+	}
+	if len(ids) == len(collection.RecipeIDs) {
+		return models.Collection{}, ErrRecipeNotInCollection
+	}
+	collection.RecipeIDs = ids
+
+	return s.saveCollection(collection)
+}
+
+// ReorderCollection sets a new order. It only accepts a permutation of the
+// current recipes, so a stale client cannot drop or re-add recipes by
+// accident.
+func (s *RecipeService) ReorderCollection(owner, id string, recipeIDs []string) (models.Collection, error) {
+	collection, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	if len(recipeIDs) != len(collection.RecipeIDs) {
+		return models.Collection{}, ErrInvalidCollectionOrder
+	}
+	seen := make(map[string]bool, len(recipeIDs))
+	for _, recipeID := range recipeIDs {
+		if seen[recipeID] || !containsString(collection.RecipeIDs, recipeID) {
+			return models.Collection{}, ErrInvalidCollectionOrder
+		}
+		seen[recipeID] = true
+	}
+	collection.RecipeIDs = recipeIDs
+
+	return s.saveCollection(collection)
+}
+
+// ShareCollection returns the collection's read-only share token, creating
+// one if needed. Sharing twice returns the same link.
+func (s *RecipeService) ShareCollection(owner, id string) (models.Collection, error) {
+	collection, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if collection.ShareToken != "" {
+		return collection, nil
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return models.Collection{}, err
+	}
+	collection.ShareToken = token
+	return s.saveCollection(collection)
+}
+
+// UnshareCollection revokes the share link. Sharing again afterwards issues
+// a new token, so old links stay dead.
+func (s *RecipeService) UnshareCollection(owner, id string) (models.Collection, error) {
+	collection, err := s.ownedCollection(owner, id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	collection.ShareToken = ""
+	return s.saveCollection(collection)
+}
+
+func (s *RecipeService) GetSharedCollection(token string) (models.CollectionDetail, error) {
+	collection, err := s.storage.GetCollectionByShareToken(token)
+	if err != nil {
+		return models.CollectionDetail{}, err
+	}
+	return s.collectionDetail(collection), nil
+}
+
+// ownedCollection loads a collection on behalf of a user. Collections of
+// other users are reported as missing rather than forbidden, so IDs cannot
+// be probed.
+func (s *RecipeService) ownedCollection(owner, id string) (models.Collection, error) {
+	if !validOwner(owner) {
+		return models.Collection{}, ErrInvalidOwner
+	}
+	collection, err := s.storage.GetCollection(id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if collection.Owner != owner {
+		return models.Collection{}, storage.ErrCollectionNotFound
+	}
+	return collection, nil
+}
+
+func (s *RecipeService) saveCollection(collection models.Collection) (models.Collection, error) {
+	collection, err := s.normalizeCollection(collection)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	collection.UpdatedAt = time.Now()
+	if err := s.storage.UpdateCollection(collection.ID, collection); err != nil {
+		return models.Collection{}, err
+	}
+	return collection, nil
+}
+
+func (s *RecipeService) normalizeCollection(collection models.Collection) (models.Collection, error) {
+	if !validOwner(collection.Owner) {
+		return models.Collection{}, ErrInvalidOwner
+	}
+
+	collection.Name = strings.TrimSpace(collection.Name)
+	collection.Description = strings.TrimSpace(collection.Description)
+	if collection.Name == "" || len(collection.Name) > 100 {
+		return models.Collection{}, ErrInvalidCollection
+	}
+	if len(collection.Description) > maxCollectionDescription {
+		return models.Collection{}, fmt.Errorf("collection description must be at most %d characters", maxCollectionDescription)
+	}
+	for _, other := range s.storage.GetCollectionsByOwner(collection.Owner) {
+		if other.ID != collection.ID && strings.EqualFold(other.Name, collection.Name) {
+			return models.Collection{}, ErrDuplicateCollectionName
+		}
+	}
+
+	if len(collection.RecipeIDs) > maxCollectionRecipes {
+		return models.Collection{}, fmt.Errorf("a collection can hold at most %d recipes", maxCollectionRecipes)
+	}
+	ids := make([]string, 0, len(collection.RecipeIDs))
+	for _, recipeID := range collection.RecipeIDs {
+		if containsString(ids, recipeID) {
+			return models.Collection{}, fmt.Errorf("%w: %s", ErrDuplicateRecipe, recipeID)
+		}
+		if _, err := s.storage.GetRecipe(recipeID); err != nil {
+			return models.Collection{}, fmt.Errorf("%w: %s", err, recipeID)
+		}
+		ids = append(ids, recipeID)
+	}
+	collection.RecipeIDs = ids
+
+	return collection, nil
+}
+
+func (s *RecipeService) collectionDetail(collection models.Collection) models.CollectionDetail {
+	detail := models.CollectionDetail{Collection: collection, Recipes: make([]models.Recipe, 0, len(collection.RecipeIDs))}
+	for _, recipeID := range collection.RecipeIDs {
+		if recipe, err := s.storage.GetRecipe(recipeID); err == nil {
+			detail.Recipes = append(detail.Recipes, recipe)
+		}
+	}
+	return detail
+}
+
+func validOwner(owner string) bool {
+	return owner != "" && len(owner) <= 64 && !strings.ContainsAny(owner, " \t\n/")
+}
+
+// validID accepts an ID that can be used as a path segment in a URL.
+func validID(id string) bool {
+	return id != "" && len(id) <= 64 && !strings.ContainsAny(id, " \t\n/")
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
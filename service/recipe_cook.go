@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+var (
+	ErrInvalidCookQuantity = errors.New("potatoes must be positive")
+	ErrInsufficientStock   = errors.New("not enough potatoes of the recipe's variety in stock")
+)
+
+// Cook takes potatoes of the recipe's variety out of stock, oldest harvest
+// first, to record that the recipe was cooked. Without a quantity it uses
+// one potato per serving. Quarantined potatoes are never used, and nothing
+// is removed when stock is short. The event is kept for lot traceability.
+func (s *RecipeService) Cook(id string, potatoes int) (models.CookEvent, error) {
+	recipe, err := s.storage.GetRecipe(id)
+	if err != nil {
+		return models.CookEvent{}, err
+	}
+	if potatoes == 0 {
+		potatoes = max(recipe.Servings, 1)
+	}
+	if potatoes < 0 {
+		return models.CookEvent{}, ErrInvalidCookQuantity
+	}
+
+	stock := slices.DeleteFunc(s.storage.GetPotatoesByVariety(recipe.Variety), func(p models.Potato) bool {
+		return p.Quarantined
+	})
+	if len(stock) < potatoes {
+		return models.CookEvent{}, ErrInsufficientStock
+	}
+	sort.Slice(stock, func(i, j int) bool {
+		return stock[i].HarvestDate.Before(stock[j].HarvestDate)
+	})
+
+	event := models.CookEvent{
+		RecipeID: recipe.ID,
+		Variety:  recipe.Variety,
+		CookedAt: time.Now(),
+	}
+	for _, potato := range stock {
+		if len(event.PotatoIDs) == potatoes {
+			break
+		}
+		// Skip potatoes removed by someone else since we listed them.
+		if err := s.storage.DeletePotato(potato.ID, models.RemovalCooked); err == nil {
+			event.PotatoIDs = append(event.PotatoIDs, potato.ID)
+			if potato.LotID != "" && !slices.Contains(event.LotIDs, potato.LotID) {
+				event.LotIDs = append(event.LotIDs, potato.LotID)
+			}
+		}
+	}
+	event.Potatoes = len(event.PotatoIDs)
+	if event.Potatoes > 0 {
+		s.storage.AddCookEvent(event)
+	}
+	return event, nil
+}
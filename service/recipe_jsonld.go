@@ -0,0 +1,430 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+	"github.com/williamdumont/potato-demo/taxonomy"
+)
+
+var (
+	ErrNoJSONLDRecipes    = errors.New("document contains no schema.org Recipe")
+	ErrVarietyNotInferred = errors.New("could not infer the potato variety from the name, ingredients or keywords")
+	ErrMissingTotalTime   = errors.New("recipe has no totalTime, cookTime or prepTime")
+)
+
+const (
+	schemaOrgContext = "https://schema.org"
+	importAuthor     = "jsonld-import"
+)
+
+var importCounter atomic.Int64
+
+var schemaOrgDiets = map[models.DietaryFlag]string{
+	models.Vegan:      "https://schema.org/VeganDiet",
+	models.GlutenFree: "https://schema.org/GlutenFreeDiet",
+}
+
+var (
+	isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+	integerPattern     = regexp.MustCompile(`\d+`)
+)
+
+// ImportRecipesJSONLD creates recipes from a decoded schema.org JSON-LD
+// document: a single Recipe, an array of nodes or an @graph. Nodes of other
+// types are skipped. Each recipe is imported independently, so one bad
+// entry does not stop the rest; failures are reported by their position
+// among the Recipe nodes.
+func (s *RecipeService) ImportRecipesJSONLD(document interface{}, author string) (models.RecipeImportResult, error) {
+	nodes := jsonLDRecipeNodes(document)
+	if len(nodes) == 0 {
+		return models.RecipeImportResult{}, ErrNoJSONLDRecipes
+	}
+	if author == "" {
+		author = importAuthor
+	}
+
+	result := models.RecipeImportResult{Imported: []models.Recipe{}, Failed: []models.RecipeImportFailure{}}
+	for i, node := range nodes {
+		recipe, err := s.importJSONLDRecipe(node, author)
+		if err != nil {
+			result.Failed = append(result.Failed, models.RecipeImportFailure{
+				Index: i,
+				Name:  jsonLDText(node["name"]),
+				Error: err.Error(),
+			})
+			continue
+		}
+		result.Imported = append(result.Imported, recipe)
+	}
+	return result, nil
+}
+
+// importJSONLDRecipe saves one Recipe node. A node whose identifier matches
+// an existing recipe updates it as a new revision, so re-importing a site
+// export does not create duplicates. A node without one gets the next free
+// generated ID, skipping any a client has already taken.
+func (s *RecipeService) importJSONLDRecipe(node map[string]interface{}, author string) (models.Recipe, error) {
+	recipe, err := recipeFromJSONLD(s.storage, node)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	recipe.UpdatedBy = author
+
+	if recipe.ID != "" {
+		if _, err := s.storage.GetRecipe(recipe.ID); err == nil {
+			return s.UpdateRecipe(recipe.ID, recipe)
+		}
+		return s.CreateRecipe(recipe)
+	}
+	for {
+		recipe.ID = fmt.Sprintf("ri%d", importCounter.Add(1))
+		created, err := s.CreateRecipe(recipe)
+		if !errors.Is(err, ErrRecipeIDTaken) {
+			return created, err
+		}
+	}
+}
+
+func recipeFromJSONLD(store storage.Storage, node map[string]interface{}) (models.Recipe, error) {
+	recipe := models.Recipe{
+		ID:           jsonLDText(node["identifier"]),
+		Name:         jsonLDText(node["name"]),
+		Ingredients:  jsonLDStrings(firstPresent(node, "recipeIngredient", "ingredients")),
+		Instructions: jsonLDStrings(node["recipeInstructions"]),
+		Servings:     jsonLDYield(node["recipeYield"]),
+	}
+	if strings.ContainsAny(recipe.ID, " /?#") {
+		recipe.ID = ""
+	}
+	if recipe.Name == "" {
+		return models.Recipe{}, ErrInvalidRecipe
+	}
+
+	minutes, err := jsonLDCookingTime(node)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	recipe.CookingTime = minutes
+
+	for _, keyword := range jsonLDKeywords(node["keywords"]) {
+		if validTag(keyword) && len(recipe.Tags) < maxTags && !containsString(recipe.Tags, keyword) {
+			recipe.Tags = append(recipe.Tags, keyword)
+		}
+	}
+
+	variety, ok := inferVariety(store, recipe)
+	if !ok {
+		return models.Recipe{}, ErrVarietyNotInferred
+	}
+	recipe.Variety = variety
+
+	if method, ok := parseCookingMethod(jsonLDText(node["cookingMethod"])); ok {
+		recipe.CookingMethod = method
+	} else {
+		recipe.CookingMethod = taxonomy.InferCookingMethod(recipe.Name, recipe.Instructions)
+	}
+	recipe.Difficulty = jsonLDDifficulty(jsonLDText(node["educationalLevel"]), recipe)
+
+	return recipe, nil
+}
+
+// ExportRecipeJSONLD renders a recipe as schema.org JSON-LD. Nutrition is only
+// included when every ingredient could be measured, so the website never shows
+// a partial total as if it were complete.
+func (s *RecipeService) ExportRecipeJSONLD(recipe models.Recipe) models.RecipeJSONLD {
+	doc := models.RecipeJSONLD{
+		Context:            schemaOrgContext,
+		Type:               "Recipe",
+		Identifier:         recipe.ID,
+		Name:               recipe.Name,
+		RecipeIngredient:   recipe.Ingredients,
+		RecipeInstructions: make([]models.HowToStep, 0, len(recipe.Instructions)),
+		Keywords:           strings.Join(recipe.Tags, ", "),
+		CookingMethod:      string(recipe.CookingMethod),
+		EducationalLevel:   recipe.Difficulty,
+	}
+	if doc.RecipeIngredient == nil {
+		doc.RecipeIngredient = []string{}
+	}
+	for _, step := range recipe.Instructions {
+		doc.RecipeInstructions = append(doc.RecipeInstructions, models.HowToStep{Type: "HowToStep", Text: step})
+	}
+	if recipe.CookingTime > 0 {
+		doc.TotalTime = formatISODuration(recipe.CookingTime)
+	}
+	if recipe.Servings > 0 {
+		doc.RecipeYield = fmt.Sprintf("%d servings", recipe.Servings)
+	}
+	for _, flag := range recipe.Dietary {
+		if diet, ok := schemaOrgDiets[flag]; ok {
+			doc.SuitableForDiet = append(doc.SuitableForDiet, diet)
+		}
+	}
+	if !recipe.UpdatedAt.IsZero() {
+		doc.DateModified = recipe.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	if report := calculateNutrition(recipe); report.Complete {
+		doc.Nutrition = &models.NutritionInformation{
+			Type:                "NutritionInformation",
+			Calories:            fmt.Sprintf("%.0f calories", report.PerServing.Calories),
+			CarbohydrateContent: fmt.Sprintf("%g g", report.PerServing.Carbs),
+			ProteinContent:      fmt.Sprintf("%g g", report.PerServing.Protein),
+			FiberContent:        fmt.Sprintf("%g g", report.PerServing.Fiber),
+		}
+	}
+
+	return doc
+}
+
+// jsonLDRecipeNodes walks a JSON-LD document and collects the nodes typed as
+// Recipe, looking inside arrays and @graph containers.
+func jsonLDRecipeNodes(value interface{}) []map[string]interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		var nodes []map[string]interface{}
+		for _, item := range v {
+			nodes = append(nodes, jsonLDRecipeNodes(item)...)
+		}
+		return nodes
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			return jsonLDRecipeNodes(graph)
+		}
+		if jsonLDHasType(v, "Recipe") {
+			return []map[string]interface{}{v}
+		}
+	}
+	return nil
+}
+
+// jsonLDHasType accepts both "Recipe" and ["Recipe", "NewsArticle"] style
+// @type values, with or without the schema.org prefix.
+func jsonLDHasType(node map[string]interface{}, want string) bool {
+	for _, t := range jsonLDStrings(node["@type"]) {
+		if strings.TrimPrefix(strings.TrimPrefix(t, "https://schema.org/"), "http://schema.org/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLDText reads a single text value, unwrapping objects such as
+// {"@type": "PropertyValue", "value": "..."} or {"text": "..."}.
+func jsonLDText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return cleanJSONLDText(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		if len(v) > 0 {
+			return jsonLDText(v[0])
+		}
+	case map[string]interface{}:
+		return jsonLDText(firstPresent(v, "text", "value", "name"))
+	}
+	return ""
+}
+
+// jsonLDStrings flattens the shapes schema.org allows for lists of text: a
+// newline-separated string, an array of strings, HowToStep objects, or
+// HowToSection objects grouping further steps.
+func jsonLDStrings(value interface{}) []string {
+	var out []string
+	switch v := value.(type) {
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if line = cleanJSONLDText(line); line != "" {
+				out = append(out, line)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			out = append(out, jsonLDStrings(item)...)
+		}
+	case map[string]interface{}:
+		if items, ok := v["itemListElement"]; ok {
+			return jsonLDStrings(items)
+		}
+		if text := jsonLDText(v); text != "" {
+			out = append(out, text)
+		}
+	}
+	return out
+}
+
+func jsonLDKeywords(value interface{}) []string {
+	var keywords []string
+	for _, raw := range jsonLDStrings(value) {
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+				keywords = append(keywords, part)
+			}
+		}
+	}
+	return keywords
+}
+
+// jsonLDYield takes the first whole number out of recipeYield, which may be
+// a number, "4", "4 servings", "Serves 4-6" or a list of those.
+func jsonLDYield(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		if match := integerPattern.FindString(v); match != "" {
+			n, _ := strconv.Atoi(match)
+			return n
+		}
+	case []interface{}:
+		for _, item := range v {
+			if n := jsonLDYield(item); n > 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+func jsonLDCookingTime(node map[string]interface{}) (int, error) {
+	if total := jsonLDText(node["totalTime"]); total != "" {
+		return parseISODuration(total)
+	}
+
+	minutes := 0
+	for _, key := range []string{"prepTime", "cookTime"} {
+		if raw := jsonLDText(node[key]); raw != "" {
+			m, err := parseISODuration(raw)
+			if err != nil {
+				return 0, err
+			}
+			minutes += m
+		}
+	}
+	if minutes == 0 {
+		return 0, ErrMissingTotalTime
+	}
+	return minutes, nil
+}
+
+// parseISODuration converts an ISO 8601 duration such as "PT1H30M" or
+// "P0DT45M" to whole minutes, rounding seconds up.
+func parseISODuration(raw string) (int, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(raw))
+	match := isoDurationPattern.FindStringSubmatch(normalized)
+	if match == nil || normalized == "P" || strings.HasSuffix(normalized, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", raw)
+	}
+
+	days, _ := strconv.Atoi(match[1])
+	hours, _ := strconv.Atoi(match[2])
+	minutes, _ := strconv.Atoi(match[3])
+	seconds, _ := strconv.ParseFloat(match[4], 64)
+
+	total := days*24*60 + hours*60 + minutes + int(math.Ceil(seconds/60))
+	if total <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", raw)
+	}
+	return total, nil
+}
+
+func formatISODuration(minutes int) string {
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("PT%dM", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("PT%dH", hours)
+	default:
+		return fmt.Sprintf("PT%dH%dM", hours, minutes)
+	}
+}
+
+// inferVariety finds the catalogued variety a recipe is for by looking for
+// variety names and aliases, as whole words, in the recipe name, then the
+// ingredients, then the keywords. The longest match wins, so "sweet potato"
+// is not read as the alias "sweet". An ingredient line only counts when it
+// mentions potatoes or a variety by its full name, so "red onion" does not
+// make a red potato recipe.
+func inferVariety(store storage.Storage, recipe models.Recipe) (string, bool) {
+	varieties := store.GetAllVarieties()
+	sources := []struct {
+		lines       []string
+		ingredients bool
+	}{
+		{[]string{recipe.Name}, false},
+		{recipe.Ingredients, true},
+		{recipe.Tags, false},
+	}
+
+	for _, source := range sources {
+		best, longest := "", 0
+		for _, line := range source.lines {
+			line = strings.ToLower(line)
+			mentionsPotato := taxonomy.ContainsWord(line, "potato")
+			for _, variety := range varieties {
+				for i, term := range append([]string{variety.Name}, variety.Aliases...) {
+					if source.ingredients && i > 0 && !mentionsPotato {
+						continue
+					}
+					term = strings.ToLower(term)
+					if len(term) < longest || !taxonomy.ContainsWord(line, term) {
+						continue
+					}
+					if len(term) > longest || variety.Name < best {
+						best, longest = variety.Name, len(term)
+					}
+				}
+			}
+		}
+		if best != "" {
+			return best, true
+		}
+	}
+	return "", false
+}
+
+// jsonLDDifficulty reads the difficulty from educationalLevel, which is
+// where our own exports put it. Recipe has no dedicated field, so for other
+// sources it is inferred: short recipes with few steps are Easy, long or
+// many-step ones Hard.
+func jsonLDDifficulty(level string, recipe models.Recipe) string {
+	for _, difficulty := range []string{"Easy", "Medium", "Hard"} {
+		if strings.EqualFold(level, difficulty) {
+			return difficulty
+		}
+	}
+
+	switch {
+	case recipe.CookingTime <= 30 && len(recipe.Instructions) <= 5:
+		return "Easy"
+	case recipe.CookingTime >= 90 || len(recipe.Instructions) > 10:
+		return "Hard"
+	default:
+		return "Medium"
+	}
+}
+
+func cleanJSONLDText(text string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+func firstPresent(node map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := node[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/search"
+	"github.com/williamdumont/potato-demo/seed"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+func newTestRecipeService(t *testing.T) *RecipeService {
+	t.Helper()
+	store := storage.NewInMemoryStorage()
+	seed.LoadSampleData(store)
+	return NewRecipeService(store, search.NewRecipeIndex())
+}
+
+// decodeJSONLD turns a value into the generic document the import handler
+// decodes from a request body.
+func decodeJSONLD(t *testing.T, value interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return document
+}
+
+func importOne(t *testing.T, s *RecipeService, document interface{}) models.Recipe {
+	t.Helper()
+	result, err := s.ImportRecipesJSONLD(document, "")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(result.Failed) > 0 || len(result.Imported) != 1 {
+		t.Fatalf("import: got %d imported, failures %+v", len(result.Imported), result.Failed)
+	}
+	return result.Imported[0]
+}
+
+func TestJSONLDRoundTrip(t *testing.T) {
+	s := newTestRecipeService(t)
+	source := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "Recipe",
+		"identifier":       "jsonld-gratin",
+		"name":             "Yukon Gold Gratin",
+		"recipeIngredient": []string{"1 kg Yukon Gold potatoes", "300 ml cream", "1 clove garlic"},
+		"recipeInstructions": []map[string]string{
+			{"@type": "HowToStep", "text": "Slice the potatoes thinly."},
+			{"@type": "HowToStep", "text": "Layer with cream and bake."},
+		},
+		"recipeYield":      "6 servings",
+		"totalTime":        "PT1H30M",
+		"keywords":         "gratin, comfort food",
+		"cookingMethod":    "baked",
+		"educationalLevel": "Medium",
+	}
+
+	first := importOne(t, s, decodeJSONLD(t, source))
+	exported := s.ExportRecipeJSONLD(first)
+	second := importOne(t, s, decodeJSONLD(t, exported))
+
+	if first.ID != "jsonld-gratin" || second.ID != first.ID {
+		t.Errorf("ID: first %q, second %q, want jsonld-gratin", first.ID, second.ID)
+	}
+	if second.Revision != first.Revision+1 {
+		t.Errorf("Revision: got %d after %d, want a new revision", second.Revision, first.Revision)
+	}
+
+	checks := []struct {
+		field       string
+		first, then interface{}
+	}{
+		{"Name", first.Name, second.Name},
+		{"Variety", first.Variety, second.Variety},
+		{"CookingTime", first.CookingTime, second.CookingTime},
+		{"Servings", first.Servings, second.Servings},
+		{"CookingMethod", first.CookingMethod, second.CookingMethod},
+		{"Difficulty", first.Difficulty, second.Difficulty},
+	}
+	for _, check := range checks {
+		if check.first != check.then {
+			t.Errorf("%s: %v after the round trip, want %v", check.field, check.then, check.first)
+		}
+	}
+	if !slices.Equal(first.Ingredients, second.Ingredients) {
+		t.Errorf("Ingredients: %q after the round trip, want %q", second.Ingredients, first.Ingredients)
+	}
+	if !slices.Equal(first.Instructions, second.Instructions) {
+		t.Errorf("Instructions: %q after the round trip, want %q", second.Instructions, first.Instructions)
+	}
+	if !slices.Equal(first.Tags, second.Tags) {
+		t.Errorf("Tags: %q after the round trip, want %q", second.Tags, first.Tags)
+	}
+	if !slices.Equal(first.Dietary, second.Dietary) {
+		t.Errorf("Dietary: %q after the round trip, want %q", second.Dietary, first.Dietary)
+	}
+
+	want := models.Recipe{
+		Name:          "Yukon Gold Gratin",
+		Variety:       "Yukon Gold",
+		CookingTime:   90,
+		Servings:      6,
+		CookingMethod: models.Baked,
+		Difficulty:    "Medium",
+	}
+	if first.Name != want.Name || first.Variety != want.Variety || first.CookingTime != want.CookingTime ||
+		first.Servings != want.Servings || first.CookingMethod != want.CookingMethod || first.Difficulty != want.Difficulty {
+		t.Errorf("imported %+v, want fields of %+v", first, want)
+	}
+	if !slices.Equal(first.Tags, []string{"gratin", "comfort food"}) {
+		t.Errorf("Tags: got %q, want [gratin comfort food]", first.Tags)
+	}
+}
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int
+	}{
+		{"PT45M", 45},
+		{"PT2H", 120},
+		{"PT1H30M", 90},
+		{"P1DT2H", 26 * 60},
+		{"P1D", 24 * 60},
+		{"pt1h5m", 65},
+		{"PT90S", 2},
+		{" PT10M ", 10},
+	}
+	for _, tt := range tests {
+		got, err := parseISODuration(tt.raw)
+		if err != nil {
+			t.Errorf("parseISODuration(%q): %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseISODuration(%q) = %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseISODurationRejectsMalformed(t *testing.T) {
+	for _, raw := range []string{"", "P", "PT", "P1DT", "PT0M", "1H30M", "PT1.5H", "PT-5M", "PT1M30H", "45 minutes"} {
+		if got, err := parseISODuration(raw); err == nil {
+			t.Errorf("parseISODuration(%q) = %d, want an error", raw, got)
+		}
+	}
+}
+
+func TestISODurationRoundTrip(t *testing.T) {
+	for _, minutes := range []int{1, 45, 59, 60, 90, 120, 26 * 60, 24*60 + 1} {
+		formatted := formatISODuration(minutes)
+		got, err := parseISODuration(formatted)
+		if err != nil {
+			t.Errorf("parseISODuration(formatISODuration(%d) = %q): %v", minutes, formatted, err)
+			continue
+		}
+		if got != minutes {
+			t.Errorf("formatISODuration(%d) = %q, which parses back as %d", minutes, formatted, got)
+		}
+	}
+
+	for _, raw := range []string{"PT1H30M", "PT45M", "PT2H"} {
+		minutes, err := parseISODuration(raw)
+		if err != nil {
+			t.Fatalf("parseISODuration(%q): %v", raw, err)
+		}
+		if got := formatISODuration(minutes); got != raw {
+			t.Errorf("formatISODuration(parseISODuration(%q)) = %q", raw, got)
+		}
+	}
+	if got := formatISODuration(26 * 60); got != "PT26H" {
+		t.Errorf("formatISODuration(P1DT2H) = %q, want PT26H", got)
+	}
+}
+
+func TestInferVariety(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	seed.LoadSampleData(store)
+
+	tests := []struct {
+		name   string
+		recipe models.Recipe
+		want   string
+	}{
+		{"name", models.Recipe{Name: "Russet Fries"}, "Russet"},
+		{"alias", models.Recipe{Name: "Candied Yams"}, "Sweet Potato"},
+		{"longest match", models.Recipe{Name: "Sweet Potato Pie"}, "Sweet Potato"},
+		{"ingredient", models.Recipe{Name: "Gratin", Ingredients: []string{"1 kg red potatoes"}}, "Red Potato"},
+		{"name before ingredients", models.Recipe{Name: "Fingerling Salad", Ingredients: []string{"2 russet potatoes"}}, "Fingerling"},
+		{"keyword", models.Recipe{Name: "Hash", Tags: []string{"purple majesty"}}, "Purple Potato"},
+	}
+	for _, tt := range tests {
+		got, ok := inferVariety(store, tt.recipe)
+		if !ok || got != tt.want {
+			t.Errorf("%s: inferVariety = %q, %v, want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestInferVarietyFailures(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	seed.LoadSampleData(store)
+
+	tests := []struct {
+		name   string
+		recipe models.Recipe
+	}{
+		{"empty", models.Recipe{}},
+		{"no variety", models.Recipe{Name: "Mystery Gratin", Ingredients: []string{"potatoes", "cream"}}},
+		{"alias in other ingredient", models.Recipe{Name: "Salad", Ingredients: []string{"1 red onion", "1 purple cabbage"}}},
+		{"part of a word", models.Recipe{Name: "Redcurrant Tart", Ingredients: []string{"sweetener"}}},
+		{"unknown keywords", models.Recipe{Name: "Stew", Tags: []string{"winter", "comfort food"}}},
+	}
+	for _, tt := range tests {
+		if got, ok := inferVariety(store, tt.recipe); ok {
+			t.Errorf("%s: inferVariety = %q, want no variety", tt.name, got)
+		}
+	}
+
+	if got, ok := inferVariety(storage.NewInMemoryStorage(), models.Recipe{Name: "Russet Fries"}); ok {
+		t.Errorf("empty catalog: inferVariety = %q, want no variety", got)
+	}
+}
+
+func TestImportReportsUninferredVariety(t *testing.T) {
+	s := newTestRecipeService(t)
+	document := decodeJSONLD(t, []map[string]interface{}{
+		{"@type": "Recipe", "name": "Mystery Gratin", "recipeIngredient": []string{"potatoes"}, "totalTime": "PT1H"},
+		{"@type": "Recipe", "name": "Yukon Mash", "recipeIngredient": []string{"potatoes"}, "totalTime": "PT30M"},
+	})
+
+	result, err := s.ImportRecipesJSONLD(document, "")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(result.Imported) != 1 || result.Imported[0].Variety != "Yukon Gold" {
+		t.Errorf("imported %+v, want only Yukon Mash", result.Imported)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Index != 0 || result.Failed[0].Error != ErrVarietyNotInferred.Error() {
+		t.Errorf("failed %+v, want Mystery Gratin at index 0 with %q", result.Failed, ErrVarietyNotInferred)
+	}
+}
+
+func TestImportSkipsTakenGeneratedIDs(t *testing.T) {
+	s := newTestRecipeService(t)
+	taken := fmt.Sprintf("ri%d", importCounter.Load()+1)
+	if _, err := s.CreateRecipe(testRecipe(taken, "Client Russets")); err != nil {
+		t.Fatalf("create %s: %v", taken, err)
+	}
+
+	imported := importOne(t, s, decodeJSONLD(t, map[string]interface{}{
+		"@type": "Recipe", "name": "Russet Wedges", "recipeIngredient": []string{"4 russet potatoes"}, "totalTime": "PT40M",
+	}))
+	if imported.ID == taken || imported.Revision != 1 {
+		t.Errorf("imported as %s revision %d, want a new recipe beside %s", imported.ID, imported.Revision, taken)
+	}
+	if recipe, err := s.GetRecipe(taken); err != nil || recipe.Name != "Client Russets" {
+		t.Errorf("%s = %q, %v, want it untouched", taken, recipe.Name, err)
+	}
+}
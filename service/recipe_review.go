@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+var ErrInvalidRating = errors.New("rating must be between 1 and 5")
+
+const maxReviewLength = 2000
+
+var reviewCounter atomic.Int64
+
+func (s *RecipeService) SubmitReview(recipeID string, review models.Review) (models.Review, error) {
+	if review.Rating < 1 || review.Rating > 5 {
+		return models.Review{}, ErrInvalidRating
+	}
+	review.Comment = strings.TrimSpace(review.Comment)
+	if len(review.Comment) > maxReviewLength {
+		return models.Review{}, fmt.Errorf("review comment must be at most %d characters", maxReviewLength)
+	}
+
+	review.ID = fmt.Sprintf("rv%d", reviewCounter.Add(1))
+	review.RecipeID = recipeID
+	review.CreatedAt = time.Now()
+
+	if err := s.storage.AddReview(review); err != nil {
+		return models.Review{}, err
+	}
+	return review, nil
+}
+
+func (s *RecipeService) GetReviews(recipeID string) ([]models.Review, error) {
+	if _, err := s.storage.GetRecipe(recipeID); err != nil {
+		return nil, err
+	}
+	reviews := s.storage.GetReviews(recipeID)
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].CreatedAt.After(reviews[j].CreatedAt) })
+	return reviews, nil
+}
+
+func (s *RecipeService) RecordView(recipeID string) {
+	s.storage.AddRecipeView(recipeID)
+}
+
+func (s *RecipeService) GetRecipeStats(recipeID string) (models.RecipeStats, error) {
+	recipe, err := s.storage.GetRecipe(recipeID)
+	if err != nil {
+		return models.RecipeStats{}, err
+	}
+	return s.newRatingModel().stats(recipe, s.storage.GetRecipeViews(recipe.ID)), nil
+}
+
+// TopRecipes ranks recipes by their Bayesian rating so that a single 5-star
+// review does not outrank a recipe with dozens of 4.5-star ones. Views break
+// ties between recipes that have the same score.
+func (s *RecipeService) TopRecipes(limit int) []models.RecipeStats {
+	model := s.newRatingModel()
+
+	var ranked []models.RecipeStats
+	for _, recipe := range s.storage.GetAllRecipes() {
+		stats := model.stats(recipe, s.storage.GetRecipeViews(recipe.ID))
+		if stats.RatingCount == 0 && stats.ViewCount == 0 {
+			continue
+		}
+		ranked = append(ranked, stats)
+	}
+	sortStats(ranked)
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+func sortStats(stats []models.RecipeStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Score != stats[j].Score {
+			return stats[i].Score > stats[j].Score
+		}
+		if stats[i].ViewCount != stats[j].ViewCount {
+			return stats[i].ViewCount > stats[j].ViewCount
+		}
+		return stats[i].RecipeID < stats[j].RecipeID
+	})
+}
+
+// ratingModel holds the catalogue-wide prior used for Bayesian averaging:
+// every recipe starts with `confidence` virtual ratings at the global mean.
+type ratingModel struct {
+	reviews    map[string][]models.Review
+	mean       float64
+	confidence float64
+}
+
+func (s *RecipeService) newRatingModel() ratingModel {
+	reviews := s.storage.GetAllReviews()
+
+	total, count := 0, 0
+	for _, list := range reviews {
+		for _, review := range list {
+			total += review.Rating
+			count++
+		}
+	}
+
+	model := ratingModel{reviews: reviews, mean: 3, confidence: 1}
+	if count > 0 {
+		model.mean = float64(total) / float64(count)
+		model.confidence = math.Max(1, float64(count)/float64(len(reviews)))
+	}
+	return model
+}
+
+func (m ratingModel) stats(recipe models.Recipe, views int) models.RecipeStats {
+	reviews := m.reviews[recipe.ID]
+	sum := 0
+	for _, review := range reviews {
+		sum += review.Rating
+	}
+
+	stats := models.RecipeStats{
+		RecipeID:    recipe.ID,
+		Name:        recipe.Name,
+		Variety:     recipe.Variety,
+		RatingCount: len(reviews),
+		ViewCount:   views,
+	}
+	if len(reviews) > 0 {
+		stats.AverageRating = round2(float64(sum) / float64(len(reviews)))
+	}
+	stats.Score = round2((m.confidence*m.mean + float64(sum)) / (m.confidence + float64(len(reviews))))
+	return stats
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var ErrInvalidRevision = errors.New("revision must be a positive integer")
+
+const anonymousAuthor = "anonymous"
+
+func (s *RecipeService) UpdateRecipe(id string, recipe models.Recipe) (models.Recipe, error) {
+	recipe.ID = id
+	recipe, err := s.normalizeRecipe(recipe)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	if recipe.UpdatedBy == "" {
+		recipe.UpdatedBy = anonymousAuthor
+	}
+
+	if err := s.storage.UpdateRecipe(id, recipe); err != nil {
+		return models.Recipe{}, err
+	}
+
+	return s.storage.GetRecipe(id)
+}
+
+func (s *RecipeService) GetRevisions(id string) ([]models.RecipeRevision, error) {
+	revisions := s.storage.GetRecipeRevisions(id)
+	if len(revisions) == 0 {
+		return nil, storage.ErrRecipeNotFound
+	}
+	return revisions, nil
+}
+
+func (s *RecipeService) GetRevision(id string, revision int) (models.RecipeRevision, error) {
+	revisions, err := s.GetRevisions(id)
+	if err != nil {
+		return models.RecipeRevision{}, err
+	}
+	if revision < 1 {
+		return models.RecipeRevision{}, ErrInvalidRevision
+	}
+	// Revisions are numbered without gaps, but the oldest may have been
+	// dropped from the history.
+	first := revisions[0].Revision
+	if revision < first || revision-first >= len(revisions) {
+		return models.RecipeRevision{}, storage.ErrRevisionNotFound
+	}
+	return revisions[revision-first], nil
+}
+
+// RevertRecipe restores the content of an earlier revision. History is never
+// rewritten: the restored content is saved as a new revision.
+func (s *RecipeService) RevertRecipe(id string, revision int, author string) (models.Recipe, error) {
+	target, err := s.GetRevision(id, revision)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	recipe := target.Recipe
+	recipe.UpdatedBy = author
+	return s.UpdateRecipe(id, recipe)
+}
+
+func (s *RecipeService) DiffRevisions(id string, from, to int) (models.RecipeDiff, error) {
+	older, err := s.GetRevision(id, from)
+	if err != nil {
+		return models.RecipeDiff{}, err
+	}
+	newer, err := s.GetRevision(id, to)
+	if err != nil {
+		return models.RecipeDiff{}, err
+	}
+
+	return models.RecipeDiff{
+		RecipeID:     id,
+		From:         from,
+		To:           to,
+		Fields:       diffRecipeFields(older.Recipe, newer.Recipe),
+		Ingredients:  diffLines(older.Recipe.Ingredients, newer.Recipe.Ingredients),
+		Instructions: diffLines(older.Recipe.Instructions, newer.Recipe.Instructions),
+	}, nil
+}
+
+func diffRecipeFields(a, b models.Recipe) []models.FieldChange {
+	changes := []models.FieldChange{}
+	add := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, models.FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("name", a.Name, b.Name)
+	add("variety", a.Variety, b.Variety)
+	add("cooking_time", a.CookingTime, b.CookingTime)
+	add("difficulty", a.Difficulty, b.Difficulty)
+	add("servings", a.Servings, b.Servings)
+	add("cooking_method", a.CookingMethod, b.CookingMethod)
+	add("tags", strings.Join(a.Tags, ", "), strings.Join(b.Tags, ", "))
+	add("dietary", joinFlags(a.Dietary), joinFlags(b.Dietary))
+
+	return changes
+}
+
+// diffLines produces a line diff from the longest common subsequence of the
+// two lists, which is plenty for recipe-sized inputs.
+func diffLines(a, b []string) []models.LineDiff {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := []models.LineDiff{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, models.LineDiff{Op: models.DiffEqual, Line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, models.LineDiff{Op: models.DiffRemove, Line: a[i]})
+			i++
+		default:
+			diff = append(diff, models.LineDiff{Op: models.DiffAdd, Line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, models.LineDiff{Op: models.DiffRemove, Line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, models.LineDiff{Op: models.DiffAdd, Line: b[j]})
+	}
+
+	return diff
+}
+
+func joinFlags(flags []models.DietaryFlag) string {
+	parts := make([]string, len(flags))
+	for i, flag := range flags {
+		parts[i] = string(flag)
+	}
+	return strings.Join(parts, ", ")
+}
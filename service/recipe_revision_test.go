@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+func testRecipe(id, name string) models.Recipe {
+	return models.Recipe{
+		ID:          id,
+		Name:        name,
+		Variety:     "Russet",
+		CookingTime: 30,
+		Difficulty:  "Easy",
+		Ingredients: []string{"4 Russet potatoes"},
+		Servings:    4,
+	}
+}
+
+func TestCreateRecipeRejectsExistingID(t *testing.T) {
+	s := newTestRecipeService(t)
+	if _, err := s.CreateRecipe(testRecipe("r-new", "Baked Russets")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := s.CreateRecipe(testRecipe("r-new", "Someone Else's Russets")); !errors.Is(err, ErrRecipeIDTaken) {
+		t.Fatalf("second create: err = %v, want %v", err, ErrRecipeIDTaken)
+	}
+	recipe, err := s.GetRecipe("r-new")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if recipe.Name != "Baked Russets" || recipe.Revision != 1 {
+		t.Errorf("recipe %q at revision %d, want Baked Russets at revision 1", recipe.Name, recipe.Revision)
+	}
+	if revisions, _ := s.GetRevisions("r-new"); len(revisions) != 1 {
+		t.Errorf("%d revisions, want 1", len(revisions))
+	}
+}
+
+func TestRevisionHistoryIsCapped(t *testing.T) {
+	s := newTestRecipeService(t)
+	if _, err := s.CreateRecipe(testRecipe("r-busy", "Busy Russets")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	updates := 120
+	for i := 1; i <= updates; i++ {
+		recipe := testRecipe("r-busy", fmt.Sprintf("Busy Russets %d", i))
+		if _, err := s.UpdateRecipe("r-busy", recipe); err != nil {
+			t.Fatalf("update %d: %v", i, err)
+		}
+	}
+
+	latest := updates + 1
+	revisions, err := s.GetRevisions("r-busy")
+	if err != nil {
+		t.Fatalf("revisions: %v", err)
+	}
+	if len(revisions) != 100 {
+		t.Fatalf("%d revisions kept, want 100", len(revisions))
+	}
+	if first, last := revisions[0].Revision, revisions[len(revisions)-1].Revision; first != latest-99 || last != latest {
+		t.Errorf("revisions %d-%d kept, want %d-%d", first, last, latest-99, latest)
+	}
+
+	if _, err := s.GetRevision("r-busy", 1); !errors.Is(err, storage.ErrRevisionNotFound) {
+		t.Errorf("dropped revision: err = %v, want %v", err, storage.ErrRevisionNotFound)
+	}
+	revision, err := s.GetRevision("r-busy", latest-1)
+	if err != nil || revision.Recipe.Name != fmt.Sprintf("Busy Russets %d", updates-1) {
+		t.Errorf("revision %d = %q, %v", latest-1, revision.Recipe.Name, err)
+	}
+
+	reverted, err := s.RevertRecipe("r-busy", latest-99, "tester")
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if reverted.Revision != latest+1 {
+		t.Errorf("reverted to revision %d, want %d", reverted.Revision, latest+1)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+var (
+	ErrInvalidServings = errors.New("servings must be a positive integer")
+	ErrInvalidUnits    = errors.New("units must be metric or imperial")
+)
+
+// ScaleRecipe returns the recipe resized to the requested number of servings
+// and, when units is set, converted to that unit system. A zero servings value
+// keeps the original yield so the endpoint can be used for conversion only.
+// It scales the recipe it is given, so callers that already loaded the
+// recipe do not read it again.
+func (s *RecipeService) ScaleRecipe(recipe models.Recipe, servings int, units models.UnitSystem) (models.ScaledRecipe, error) {
+	if servings < 0 {
+		return models.ScaledRecipe{}, ErrInvalidServings
+	}
+	if units != "" && units != models.Metric && units != models.Imperial {
+		return models.ScaledRecipe{}, ErrInvalidUnits
+	}
+
+	return scaleRecipe(recipe, servings, units), nil
+}
+
+func scaleRecipe(recipe models.Recipe, servings int, units models.UnitSystem) models.ScaledRecipe {
+	factor := scalingFactor(recipe.Servings, servings)
+	if servings == 0 {
+		servings = max(recipe.Servings, 1)
+	}
+
+	structured := make([]models.Ingredient, 0, len(recipe.Ingredients))
+	lines := make([]string, 0, len(recipe.Ingredients))
+	for _, line := range recipe.Ingredients {
+		ingredient := scaleIngredient(parseIngredient(line), factor, units)
+		structured = append(structured, ingredient)
+		lines = append(lines, ingredient.Text)
+	}
+
+	scaled := recipe
+	scaled.Servings = servings
+	scaled.Ingredients = lines
+	scaled.CookingTime = scaleCookingTime(recipe.CookingTime, factor)
+
+	return models.ScaledRecipe{
+		Recipe: scaled,
+		Scaling: models.RecipeScaling{
+			OriginalServings:    recipe.Servings,
+			Factor:              math.Round(factor*100) / 100,
+			Units:               units,
+			OriginalCookingTime: recipe.CookingTime,
+			CookingTimeHint:     cookingTimeHint(recipe.CookingTime, scaled.CookingTime, factor),
+			Ingredients:         structured,
+		},
+	}
+}
+
+// scalingFactor is what ingredient quantities are multiplied by to turn a
+// recipe's yield into servings. Zero servings keeps the yield, and a recipe
+// without one counts as a single serving.
+func scalingFactor(recipeServings, servings int) float64 {
+	if servings == 0 {
+		return 1
+	}
+	return float64(servings) / float64(max(recipeServings, 1))
+}
+
+// scaleCookingTime grows cooking time with the cube root of the batch size:
+// bigger batches take longer to heat through, but nowhere near linearly.
+func scaleCookingTime(minutes int, factor float64) int {
+	if factor == 1 || minutes <= 0 {
+		return minutes
+	}
+	scaled := int(math.Round(float64(minutes) * math.Cbrt(factor)))
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
+
+func cookingTimeHint(original, scaled int, factor float64) string {
+	switch {
+	case factor == 1:
+		return ""
+	case factor >= 4:
+		return fmt.Sprintf("Allow about %d minutes instead of %d; cook in batches or across several pans and check doneness rather than the clock", scaled, original)
+	case factor > 1:
+		return fmt.Sprintf("Allow about %d minutes instead of %d; larger batches heat through more slowly", scaled, original)
+	default:
+		return fmt.Sprintf("Allow about %d minutes instead of %d; smaller batches cook faster, start checking early", scaled, original)
+	}
+}
@@ -2,35 +2,51 @@ package service
 
 import (
 	"errors"
+	"sort"
+	"strings"
 
 	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/search"
 	"github.com/williamdumont/potato-demo/storage"
 )
 
 var (
 	ErrInvalidRecipe = errors.New("invalid recipe data")
+	ErrRecipeIDTaken = errors.New("a recipe with this id already exists")
+	ErrEmptyQuery    = errors.New("search query must not be empty")
+
+	ErrNoRecipesForVariety = errors.New("no recipes found for variety")
 )
 
 type RecipeService struct {
 	storage storage.Storage
+	index   *search.RecipeIndex
 }
 
-func NewRecipeService(storage storage.Storage) *RecipeService {
+func NewRecipeService(storage storage.Storage, index *search.RecipeIndex) *RecipeService {
 	return &RecipeService{
 		storage: storage,
+		index:   index,
 	}
 }
 
 func (s *RecipeService) CreateRecipe(recipe models.Recipe) (models.Recipe, error) {
-	if err := s.validateRecipe(recipe); err != nil {
+	recipe, err := s.normalizeRecipe(recipe)
+	if err != nil {
 		return models.Recipe{}, err
 	}
+	if recipe.UpdatedBy == "" {
+		recipe.UpdatedBy = anonymousAuthor
+	}
 
 	if err := s.storage.AddRecipe(recipe); err != nil {
+		if errors.Is(err, storage.ErrRecipeExists) {
+			return models.Recipe{}, ErrRecipeIDTaken
+		}
 		return models.Recipe{}, err
 	}
 
-	return recipe, nil
+	return s.storage.GetRecipe(recipe.ID)
 }
 
 func (s *RecipeService) GetRecipe(id string) (models.Recipe, error) {
@@ -42,23 +58,118 @@ func (s *RecipeService) GetAllRecipes() []models.Recipe {
 }
 
 func (s *RecipeService) GetRecipesByVariety(variety string) []models.Recipe {
+	if name, err := canonicalVariety(s.storage, variety); err == nil {
+		variety = name
+	}
 	return s.storage.GetRecipesByVariety(variety)
 }
 
-func (s *RecipeService) RecommendRecipe(variety string, difficulty string) (models.Recipe, error) {
+func (s *RecipeService) FilterRecipes(filter models.RecipeFilter) []models.Recipe {
+	var candidates []models.Recipe
+	if filter.Variety != "" {
+		candidates = s.storage.GetRecipesByVariety(filter.Variety)
+	} else {
+		candidates = s.storage.GetAllRecipes()
+	}
+
+	recipes := make([]models.Recipe, 0, len(candidates))
+	for _, recipe := range candidates {
+		if matchesFilter(recipe, filter) {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes
+}
+
+// VarietyRecipes returns every recipe for a variety sorted by name, as
+// bundled into a cookbook.
+func (s *RecipeService) VarietyRecipes(variety string) ([]models.Recipe, error) {
+	variety, err := canonicalVariety(s.storage, variety)
+	if err != nil {
+		return nil, err
+	}
 	recipes := s.storage.GetRecipesByVariety(variety)
+	if len(recipes) == 0 {
+		return nil, ErrNoRecipesForVariety
+	}
+	sort.Slice(recipes, func(i, j int) bool {
+		if recipes[i].Name != recipes[j].Name {
+			return recipes[i].Name < recipes[j].Name
+		}
+		return recipes[i].ID < recipes[j].ID
+	})
+	return recipes, nil
+}
 
-	for _, recipe := range recipes {
-		if difficulty == "" || recipe.Difficulty == difficulty {
-			return recipe, nil
+func (s *RecipeService) SearchRecipes(query string, limit int) ([]models.RecipeSearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrEmptyQuery
+	}
+
+	hits := s.index.Search(query, limit)
+	results := make([]models.RecipeSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		recipe, err := s.storage.GetRecipe(hit.ID)
+		if err != nil {
+			continue
 		}
+		results = append(results, models.RecipeSearchResult{
+			Recipe:       recipe,
+			Score:        hit.Score,
+			MatchedTerms: hit.MatchedTerms,
+			Highlights:   hit.Highlights,
+		})
 	}
+	return results, nil
+}
 
-	if len(recipes) > 0 {
-		return recipes[0], nil
+// RecommendRecipe prefers recipes matching the requested difficulty and,
+// among those, the best Bayesian-rated one. Unrated recipes sit at the
+// catalogue mean, so a well-reviewed recipe beats them and a poorly reviewed
+// one falls behind. Recipes containing an excluded allergen are never
+// considered, whatever their rating.
+func (s *RecipeService) RecommendRecipe(variety string, difficulty string, excludeAllergens []models.Allergen) (models.Recipe, error) {
+	variety, err := canonicalVariety(s.storage, variety)
+	if err != nil {
+		return models.Recipe{}, err
 	}
 
-	return models.Recipe{}, errors.New("no recipes found for variety")
+	var recipes []models.Recipe
+	for _, recipe := range s.storage.GetRecipesByVariety(variety) {
+		if excludesAllergens(recipe, excludeAllergens) {
+			recipes = append(recipes, recipe)
+		}
+	}
+	if len(recipes) == 0 {
+		if len(excludeAllergens) > 0 {
+			return models.Recipe{}, errors.New("no recipes found for variety without the excluded allergens")
+		}
+		return models.Recipe{}, ErrNoRecipesForVariety
+	}
+
+	candidates := recipes
+	if difficulty != "" {
+		var matching []models.Recipe
+		for _, recipe := range recipes {
+			if recipe.Difficulty == difficulty {
+				matching = append(matching, recipe)
+			}
+		}
+		if len(matching) > 0 {
+			candidates = matching
+		}
+	}
+
+	model := s.newRatingModel()
+	byID := make(map[string]models.Recipe, len(candidates))
+	ranked := make([]models.RecipeStats, 0, len(candidates))
+	for _, recipe := range candidates {
+		byID[recipe.ID] = recipe
+		ranked = append(ranked, model.stats(recipe, s.storage.GetRecipeViews(recipe.ID)))
+	}
+	sortStats(ranked)
+
+	return byID[ranked[0].RecipeID], nil
 }
 
 func (s *RecipeService) validateRecipe(recipe models.Recipe) error {
@@ -70,5 +181,22 @@ func (s *RecipeService) validateRecipe(recipe models.Recipe) error {
 		return errors.New("cooking time must be positive")
 	}
 
-	return nil
+	return validateRecipeTaxonomy(recipe)
+}
+
+// normalizeRecipe canonicalizes the taxonomy and the variety name before
+// validation, so aliases such as "yukon" are stored as "Yukon Gold".
+func (s *RecipeService) normalizeRecipe(recipe models.Recipe) (models.Recipe, error) {
+	recipe = normalizeRecipeTaxonomy(recipe)
+	if err := s.validateRecipe(recipe); err != nil {
+		return models.Recipe{}, err
+	}
+
+	variety, err := canonicalVariety(s.storage, recipe.Variety)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	recipe.Variety = variety
+
+	return recipe, nil
 }
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/taxonomy"
+)
+
+var (
+	ErrInvalidCookingMethod = errors.New("cooking method must be one of Baked, Fried, Mashed, Boiled, Roasted")
+	ErrInvalidTag           = errors.New("tags must be 1-32 characters of letters, digits, spaces or hyphens")
+	ErrInvalidDietaryFlag   = errors.New("dietary flags must be vegan, gluten-free or dairy-free")
+)
+
+const maxTags = 10
+
+// normalizeRecipeTaxonomy canonicalizes the spelling of the cooking method,
+// tags, dietary flags and allergens, infers dietary flags from the
+// ingredients when none are given and adds every detected allergen. Values it
+// cannot make sense of are left for validateRecipe to reject.
+func normalizeRecipeTaxonomy(recipe models.Recipe) models.Recipe {
+	if method, ok := parseCookingMethod(string(recipe.CookingMethod)); ok {
+		recipe.CookingMethod = method
+	}
+	recipe.Tags = normalizeTags(recipe.Tags)
+	if len(recipe.Dietary) == 0 {
+		recipe.Dietary = taxonomy.InferDietary(recipe.Ingredients)
+	} else {
+		recipe.Dietary = normalizeDietary(recipe.Dietary)
+	}
+	recipe.Allergens = mergeAllergens(normalizeAllergens(recipe.Allergens), recipe.Ingredients)
+	return recipe
+}
+
+func normalizeTags(raw []string) []string {
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func normalizeDietary(raw []models.DietaryFlag) []models.DietaryFlag {
+	flags := make([]models.DietaryFlag, 0, len(raw))
+	for _, value := range raw {
+		flag := models.DietaryFlag(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(string(value))), "_", "-"))
+		if !hasDietaryFlag(flags, flag) {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+func validateRecipeTaxonomy(recipe models.Recipe) error {
+	if recipe.CookingMethod != "" {
+		if _, ok := parseCookingMethod(string(recipe.CookingMethod)); !ok {
+			return ErrInvalidCookingMethod
+		}
+	}
+
+	if len(recipe.Tags) > maxTags {
+		return fmt.Errorf("a recipe can have at most %d tags", maxTags)
+	}
+	for _, tag := range recipe.Tags {
+		if !validTag(tag) {
+			return ErrInvalidTag
+		}
+	}
+
+	inferred := taxonomy.InferDietary(recipe.Ingredients)
+	for _, flag := range recipe.Dietary {
+		if !hasDietaryFlag(models.DietaryFlags, flag) {
+			return ErrInvalidDietaryFlag
+		}
+		if !hasDietaryFlag(inferred, flag) {
+			return fmt.Errorf("recipe cannot be %s: its ingredients rule it out", flag)
+		}
+	}
+
+	for _, allergen := range recipe.Allergens {
+		if !hasAllergen(models.Allergens, allergen) {
+			return ErrInvalidAllergen
+		}
+	}
+
+	return nil
+}
+
+func parseCookingMethod(raw string) (models.CookingMethod, bool) {
+	for _, method := range models.CookingMethods {
+		if strings.EqualFold(string(method), strings.TrimSpace(raw)) {
+			return method, true
+		}
+	}
+	return "", false
+}
+
+func validTag(tag string) bool {
+	if tag == "" || len(tag) > 32 {
+		return false
+	}
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeFilter applies the same canonicalization to filter values as to
+// recipes, so "?variety=yukon&cooking_method=baked&dietary=Vegan" matches
+// stored recipes.
+func (s *RecipeService) NormalizeFilter(filter models.RecipeFilter) (models.RecipeFilter, error) {
+	if filter.Variety != "" {
+		variety, err := canonicalVariety(s.storage, filter.Variety)
+		if err != nil {
+			return models.RecipeFilter{}, err
+		}
+		filter.Variety = variety
+	}
+
+	if filter.CookingMethod != "" {
+		method, ok := parseCookingMethod(string(filter.CookingMethod))
+		if !ok {
+			return models.RecipeFilter{}, ErrInvalidCookingMethod
+		}
+		filter.CookingMethod = method
+	}
+
+	filter.Tags = normalizeTags(filter.Tags)
+	filter.Dietary = normalizeDietary(filter.Dietary)
+	for _, flag := range filter.Dietary {
+		if !hasDietaryFlag(models.DietaryFlags, flag) {
+			return models.RecipeFilter{}, ErrInvalidDietaryFlag
+		}
+	}
+
+	allergens, err := s.NormalizeAllergens(filter.ExcludeAllergens)
+	if err != nil {
+		return models.RecipeFilter{}, err
+	}
+	filter.ExcludeAllergens = allergens
+
+	return filter, nil
+}
+
+func matchesFilter(recipe models.Recipe, filter models.RecipeFilter) bool {
+	if filter.Variety != "" && recipe.Variety != filter.Variety {
+		return false
+	}
+	if filter.CookingMethod != "" && recipe.CookingMethod != filter.CookingMethod {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !containsString(recipe.Tags, tag) {
+			return false
+		}
+	}
+	for _, flag := range filter.Dietary {
+		if !hasDietaryFlag(recipe.Dietary, flag) {
+			return false
+		}
+	}
+	return excludesAllergens(recipe, filter.ExcludeAllergens)
+}
+
+func hasDietaryFlag(flags []models.DietaryFlag, flag models.DietaryFlag) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
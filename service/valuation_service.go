@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+	"github.com/williamdumont/potato-demo/valuation"
+)
+
+var (
+	ErrInvalidValuationPeriod = errors.New("from must not be after as_of")
+	ErrValuationBeforeLedger  = errors.New("as_of and from must not be before the ledger start")
+)
+
+type ValuationService struct {
+	storage  storage.Storage
+	currency string
+}
+
+func NewValuationService(storage storage.Storage, currency string) *ValuationService {
+	return &ValuationService{
+		storage:  storage,
+		currency: currency,
+	}
+}
+
+// Report values stock at query.AsOf, now by default, using every method
+// unless some are named. Without From, costs of goods removed cover all
+// removals up to AsOf. Older movements are dropped from the ledger, so
+// neither AsOf nor From may be before the checkpoint it starts from.
+func (s *ValuationService) Report(query models.ValuationQuery) (models.ValuationReport, error) {
+	if query.AsOf.IsZero() {
+		query.AsOf = time.Now()
+	}
+	if !query.From.IsZero() && query.AsOf.Before(query.From) {
+		return models.ValuationReport{}, ErrInvalidValuationPeriod
+	}
+	if len(query.Methods) == 0 {
+		query.Methods = models.ValuationMethods
+	}
+	if query.Variety != "" {
+		variety, err := canonicalVariety(s.storage, query.Variety)
+		if err != nil {
+			return models.ValuationReport{}, err
+		}
+		query.Variety = variety
+	}
+
+	checkpoint, movements := s.storage.GetStockLedger()
+	if !checkpoint.Timestamp.IsZero() {
+		if query.AsOf.Before(checkpoint.Timestamp) || (!query.From.IsZero() && query.From.Before(checkpoint.Timestamp)) {
+			return models.ValuationReport{}, ErrValuationBeforeLedger
+		}
+	}
+
+	report := valuation.Compute(checkpoint, movements, query)
+	report.Currency = s.currency
+	if !checkpoint.Timestamp.IsZero() {
+		report.LedgerStart = &checkpoint.Timestamp
+	}
+	if !query.From.IsZero() {
+		report.From = &query.From
+	}
+	return report, nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var (
+	ErrInvalidVariety     = errors.New("invalid variety data")
+	ErrUnknownVariety     = errors.New("variety is not in the catalog")
+	ErrInvalidStarchLevel = errors.New("starch level must be one of low, medium, high")
+	ErrInvalidShelfLife   = errors.New("shelf life must be a positive number of days")
+	ErrVarietyConflict    = errors.New("variety name or alias is already used by another variety")
+	ErrVarietyRename      = errors.New("variety names cannot be changed; add the new name as an alias instead")
+	ErrVarietyInUse       = errors.New("variety is still used by potatoes, recipes, open purchase orders or rules")
+)
+
+const maxVarietyNameLength = 50
+
+// VarietyRules is configuration that may name varieties: the freshness and
+// pricing rules, degradation policies and forecast settings.
+type VarietyRules interface {
+	UsesVariety(name string) bool
+}
+
+// VarietyService maintains the catalog. Creates, updates and deletes run
+// under one lock, so the conflict and in-use checks still hold when the
+// change is saved.
+type VarietyService struct {
+	storage storage.Storage
+	rules   []VarietyRules
+
+	mu sync.Mutex
+}
+
+func NewVarietyService(storage storage.Storage, rules ...VarietyRules) *VarietyService {
+	return &VarietyService{
+		storage: storage,
+		rules:   rules,
+	}
+}
+
+func (s *VarietyService) CreateVariety(variety models.Variety) (models.Variety, error) {
+	variety, err := normalizeVariety(variety)
+	if err != nil {
+		return models.Variety{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkConflicts(variety, ""); err != nil {
+		return models.Variety{}, err
+	}
+
+	variety.CreatedAt = time.Now()
+	variety.UpdatedAt = variety.CreatedAt
+	if err := s.storage.AddVariety(variety); err != nil {
+		if errors.Is(err, storage.ErrVarietyExists) {
+			return models.Variety{}, ErrVarietyConflict
+		}
+		return models.Variety{}, err
+	}
+
+	return variety, nil
+}
+
+// GetVariety looks a variety up by name or alias, ignoring case.
+func (s *VarietyService) GetVariety(name string) (models.Variety, error) {
+	return resolveVariety(s.storage, name)
+}
+
+func (s *VarietyService) GetAllVarieties() []models.Variety {
+	varieties := s.storage.GetAllVarieties()
+	sort.Slice(varieties, func(i, j int) bool {
+		return varieties[i].Name < varieties[j].Name
+	})
+	return varieties
+}
+
+// UpdateVariety replaces everything but the name. Potatoes and recipes store
+// the canonical name, so renaming would orphan them; an alias covers the
+// same need without touching existing data.
+func (s *VarietyService) UpdateVariety(name string, variety models.Variety) (models.Variety, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := resolveVariety(s.storage, name)
+	if err != nil {
+		return models.Variety{}, err
+	}
+	if variety.Name != "" && !strings.EqualFold(strings.TrimSpace(variety.Name), existing.Name) {
+		return models.Variety{}, ErrVarietyRename
+	}
+	variety.Name = existing.Name
+
+	variety, err = normalizeVariety(variety)
+	if err != nil {
+		return models.Variety{}, err
+	}
+	if err := s.checkConflicts(variety, existing.Name); err != nil {
+		return models.Variety{}, err
+	}
+
+	variety.CreatedAt = existing.CreatedAt
+	variety.UpdatedAt = time.Now()
+	if err := s.storage.UpdateVariety(existing.Name, variety); err != nil {
+		return models.Variety{}, err
+	}
+
+	return variety, nil
+}
+
+// DeleteVariety refuses to remove a variety that stock, recipes, open
+// purchase orders or rules still refer to, since they would no longer pass
+// validation on their next update or would silently stop applying.
+func (s *VarietyService) DeleteVariety(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := resolveVariety(s.storage, name)
+	if err != nil {
+		return err
+	}
+	if s.inUse(existing.Name) {
+		return ErrVarietyInUse
+	}
+	return s.storage.DeleteVariety(existing.Name)
+}
+
+// inUse reports whether anything refers to a variety. Stock includes
+// quarantined potatoes and those linked to lots, and meal plans reach a
+// variety only through their recipes.
+func (s *VarietyService) inUse(variety string) bool {
+	if len(s.storage.GetPotatoesByVariety(variety)) > 0 || len(s.storage.GetRecipesByVariety(variety)) > 0 {
+		return true
+	}
+	for _, order := range s.storage.GetAllPurchaseOrders() {
+		if order.Status != models.PurchaseOrderOpen && order.Status != models.PurchaseOrderPartial {
+			continue
+		}
+		for _, line := range order.Lines {
+			if line.Variety == variety {
+				return true
+			}
+		}
+	}
+	for _, rules := range s.rules {
+		if rules.UsesVariety(variety) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanonicalVariety reports the catalog name for a name or alias. It lets the
+// telemetry layer bound metric labels to catalogued varieties.
+func (s *VarietyService) CanonicalVariety(name string) (string, bool) {
+	variety, err := resolveVariety(s.storage, name)
+	if err != nil {
+		return "", false
+	}
+	return variety.Name, true
+}
+
+// checkConflicts rejects a variety whose name or aliases collide with those of
+// any other catalogued variety, so every input resolves to exactly one entry.
+func (s *VarietyService) checkConflicts(variety models.Variety, except string) error {
+	names := make(map[string]bool, len(variety.Aliases)+1)
+	names[strings.ToLower(variety.Name)] = true
+	for _, alias := range variety.Aliases {
+		names[strings.ToLower(alias)] = true
+	}
+
+	for _, other := range s.storage.GetAllVarieties() {
+		if strings.EqualFold(other.Name, except) {
+			continue
+		}
+		if names[strings.ToLower(other.Name)] {
+			return ErrVarietyConflict
+		}
+		for _, alias := range other.Aliases {
+			if names[strings.ToLower(alias)] {
+				return ErrVarietyConflict
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeVariety(variety models.Variety) (models.Variety, error) {
+	variety.Name = strings.TrimSpace(variety.Name)
+	if variety.Name == "" || len(variety.Name) > maxVarietyNameLength {
+		return models.Variety{}, ErrInvalidVariety
+	}
+
+	seen := map[string]bool{strings.ToLower(variety.Name): true}
+	aliases := make([]string, 0, len(variety.Aliases))
+	for _, alias := range variety.Aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || seen[strings.ToLower(alias)] {
+			continue
+		}
+		if len(alias) > maxVarietyNameLength {
+			return models.Variety{}, ErrInvalidVariety
+		}
+		seen[strings.ToLower(alias)] = true
+		aliases = append(aliases, alias)
+	}
+	variety.Aliases = aliases
+
+	variety.StarchLevel = models.StarchLevel(strings.ToLower(strings.TrimSpace(string(variety.StarchLevel))))
+	if !slices.Contains(models.StarchLevels, variety.StarchLevel) {
+		return models.Variety{}, ErrInvalidStarchLevel
+	}
+
+	methods := make([]models.CookingMethod, 0, len(variety.BestCookingMethods))
+	for _, raw := range variety.BestCookingMethods {
+		method, ok := parseCookingMethod(string(raw))
+		if !ok {
+			return models.Variety{}, ErrInvalidCookingMethod
+		}
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	variety.BestCookingMethods = methods
+
+	if variety.ShelfLifeDays <= 0 {
+		return models.Variety{}, ErrInvalidShelfLife
+	}
+	if variety.BasePrice < 0 {
+		return models.Variety{}, ErrInvalidPrice
+	}
+
+	return variety, nil
+}
+
+// resolveVariety finds the catalog entry for a name or alias, ignoring case
+// and surrounding whitespace.
+func resolveVariety(store storage.Storage, name string) (models.Variety, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.Variety{}, ErrUnknownVariety
+	}
+	if variety, err := store.GetVariety(name); err == nil {
+		return variety, nil
+	}
+	for _, variety := range store.GetAllVarieties() {
+		for _, alias := range variety.Aliases {
+			if strings.EqualFold(alias, name) {
+				return variety, nil
+			}
+		}
+	}
+	return models.Variety{}, ErrUnknownVariety
+}
+
+// canonicalVariety is resolveVariety for callers that only store the name.
+func canonicalVariety(store storage.Storage, name string) (string, error) {
+	variety, err := resolveVariety(store, name)
+	if err != nil {
+		return "", err
+	}
+	return variety.Name, nil
+}
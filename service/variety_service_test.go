@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+// namedRules stands in for a rule set that names some varieties.
+type namedRules []string
+
+func (r namedRules) UsesVariety(name string) bool {
+	for _, variety := range r {
+		if strings.EqualFold(variety, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeleteVarietyInUse(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  models.PurchaseOrderStatus
+		rules   namedRules
+		wantErr error
+	}{
+		{name: "unused", wantErr: nil},
+		{name: "open order", status: models.PurchaseOrderOpen, wantErr: ErrVarietyInUse},
+		{name: "partly received order", status: models.PurchaseOrderPartial, wantErr: ErrVarietyInUse},
+		{name: "closed order", status: models.PurchaseOrderClosed, wantErr: nil},
+		{name: "named by rules", rules: namedRules{"kennebec"}, wantErr: ErrVarietyInUse},
+		{name: "rules name another variety", rules: namedRules{"Russet"}, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewInMemoryStorage()
+			if err := store.AddVariety(models.Variety{Name: "Kennebec", StarchLevel: models.StarchLevels[0], ShelfLifeDays: 60}); err != nil {
+				t.Fatalf("add variety: %v", err)
+			}
+			if tt.status != "" {
+				order := models.PurchaseOrder{ID: "po1", Status: tt.status, Lines: []models.PurchaseOrderLine{{Line: 1, Variety: "Kennebec", WeightKg: 10}}}
+				if err := store.AddPurchaseOrder(order); err != nil {
+					t.Fatalf("add order: %v", err)
+				}
+			}
+			s := NewVarietyService(store, tt.rules)
+
+			if err := s.DeleteVariety("kennebec"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("delete: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/williamdumont/potato-demo/freshness"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+var (
+	ErrInvalidWarehouse   = errors.New("warehouse needs an id of 1-64 characters without spaces or slashes and a name of at most 100 characters")
+	ErrInvalidLocation    = errors.New("location needs an id of 1-64 characters without spaces or slashes, a name of at most 100 characters and a positive capacity_kg")
+	ErrDuplicateWarehouse = errors.New("a warehouse with this id already exists")
+	ErrDuplicateLocation  = errors.New("a location with this id already exists")
+	ErrUnknownWarehouse   = errors.New("warehouse does not exist")
+	ErrUnknownLocation    = errors.New("location does not exist")
+	ErrWarehouseInUse     = errors.New("warehouse still has locations")
+	ErrLocationInUse      = errors.New("location still holds potatoes")
+	ErrCapacityBelowStock = errors.New("capacity_kg is below the weight already stored")
+	ErrLocationFull       = errors.New("location does not have room for this potato")
+	ErrNoCapacity         = errors.New("no location has room for this potato")
+	ErrLocationChange     = errors.New("location_id can only be changed with a transfer")
+	ErrInvalidTransfer    = errors.New("transfer needs potato_ids and a to_location_id")
+)
+
+const (
+	defaultTransferLimit = 100
+	maxWarehouseName     = 100
+
+	// capacityTolerance absorbs the rounding residue in maintained weights.
+	capacityTolerance = 1e-6
+)
+
+var transferCounter atomic.Int64
+
+// WarehouseService places potatoes in locations and moves them between
+// them. Every placement runs under one lock, so two potatoes cannot both
+// take the last free space in a location.
+type WarehouseService struct {
+	storage   storage.Storage
+	freshness *freshness.Engine
+
+	mu sync.Mutex
+}
+
+func NewWarehouseService(storage storage.Storage, freshness *freshness.Engine) *WarehouseService {
+	return &WarehouseService{
+		storage:   storage,
+		freshness: freshness,
+	}
+}
+
+func (s *WarehouseService) CreateWarehouse(warehouse models.Warehouse) (models.Warehouse, error) {
+	warehouse.ID = strings.TrimSpace(warehouse.ID)
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+	warehouse.Address = strings.TrimSpace(warehouse.Address)
+	if !validOwner(warehouse.ID) || warehouse.Name == "" || len(warehouse.Name) > maxWarehouseName {
+		return models.Warehouse{}, ErrInvalidWarehouse
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetWarehouse(warehouse.ID); err == nil {
+		return models.Warehouse{}, ErrDuplicateWarehouse
+	}
+	warehouse.CreatedAt = time.Now()
+	if err := s.storage.AddWarehouse(warehouse); err != nil {
+		return models.Warehouse{}, err
+	}
+	return warehouse, nil
+}
+
+func (s *WarehouseService) GetWarehouse(id string) (models.WarehouseStock, error) {
+	warehouse, err := s.storage.GetWarehouse(id)
+	if err != nil {
+		return models.WarehouseStock{}, err
+	}
+	return s.warehouseStock(warehouse, s.storage.GetInventoryAggregates()), nil
+}
+
+// GetAllWarehouses lists warehouses by ID with the stock in each location.
+func (s *WarehouseService) GetAllWarehouses() []models.WarehouseStock {
+	warehouses := s.storage.GetAllWarehouses()
+	sort.Slice(warehouses, func(i, j int) bool {
+		return warehouses[i].ID < warehouses[j].ID
+	})
+	aggregates := s.storage.GetInventoryAggregates()
+	stocks := make([]models.WarehouseStock, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		stocks = append(stocks, s.warehouseStock(warehouse, aggregates))
+	}
+	return stocks
+}
+
+// DeleteWarehouse refuses to remove a warehouse until its locations have
+// been removed, which in turn requires them to be empty.
+func (s *WarehouseService) DeleteWarehouse(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetWarehouse(id); err != nil {
+		return err
+	}
+	for _, location := range s.storage.GetAllLocations() {
+		if location.WarehouseID == id {
+			return ErrWarehouseInUse
+		}
+	}
+	return s.storage.DeleteWarehouse(id)
+}
+
+func (s *WarehouseService) CreateLocation(warehouseID string, location models.Location) (models.LocationStock, error) {
+	if _, err := s.storage.GetWarehouse(warehouseID); err != nil {
+		return models.LocationStock{}, err
+	}
+	location.ID = strings.TrimSpace(location.ID)
+	location.WarehouseID = warehouseID
+	location, err := s.normalizeLocation(location)
+	if err != nil {
+		return models.LocationStock{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetLocation(location.ID); err == nil {
+		return models.LocationStock{}, ErrDuplicateLocation
+	}
+	location.CreatedAt = time.Now()
+	if err := s.storage.AddLocation(location); err != nil {
+		return models.LocationStock{}, err
+	}
+	return locationStock(location, models.AggregateTotals{}), nil
+}
+
+func (s *WarehouseService) GetLocation(id string) (models.LocationStock, error) {
+	location, err := s.storage.GetLocation(id)
+	if err != nil {
+		return models.LocationStock{}, err
+	}
+	return locationStock(location, s.storage.GetInventoryAggregates().ByLocation[id]), nil
+}
+
+// UpdateLocation changes a location's name, storage condition or capacity.
+// It stays in its warehouse, and its capacity cannot drop below what it
+// already holds. Potatoes already there keep their storage condition until
+// they are next placed.
+func (s *WarehouseService) UpdateLocation(id string, location models.Location) (models.LocationStock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.storage.GetLocation(id)
+	if err != nil {
+		return models.LocationStock{}, err
+	}
+	location.ID = existing.ID
+	location.WarehouseID = existing.WarehouseID
+	location, err = s.normalizeLocation(location)
+	if err != nil {
+		return models.LocationStock{}, err
+	}
+
+	stock := s.storage.GetInventoryAggregates().ByLocation[id]
+	if stock.Weight > location.CapacityKg+capacityTolerance {
+		return models.LocationStock{}, ErrCapacityBelowStock
+	}
+	location.CreatedAt = existing.CreatedAt
+	if err := s.storage.UpdateLocation(id, location); err != nil {
+		return models.LocationStock{}, err
+	}
+	return locationStock(location, stock), nil
+}
+
+func (s *WarehouseService) DeleteLocation(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.GetLocation(id); err != nil {
+		return err
+	}
+	if s.storage.GetInventoryAggregates().ByLocation[id].Count > 0 {
+		return ErrLocationInUse
+	}
+	return s.storage.DeleteLocation(id)
+}
+
+// Place checks that potato fits its location and stores it with save.
+// A new potato without a location goes to the location with the most free
+// space that suits its storage condition; an existing one stays where it
+// is. The potato takes the storage condition of its location, if any.
+func (s *WarehouseService) Place(potato models.Potato, save func(models.Potato) error) (models.Potato, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.storage.GetPotato(potato.ID)
+	exists := err == nil
+	if exists && current.LocationID != "" {
+		if potato.LocationID == "" {
+			potato.LocationID = current.LocationID
+		} else if potato.LocationID != current.LocationID {
+			return models.Potato{}, ErrLocationChange
+		}
+	}
+
+	stock := s.storage.GetInventoryAggregates().ByLocation
+	if exists {
+		totals := stock[current.LocationID]
+		totals.Weight -= current.Weight
+		stock[current.LocationID] = totals
+	}
+
+	location, err := s.locationFor(potato, stock)
+	if err != nil {
+		return models.Potato{}, err
+	}
+
+	potato.LocationID = location.ID
+	if location.StorageCondition != "" {
+		potato.StorageCondition = location.StorageCondition
+	}
+	if err := save(potato); err != nil {
+		return models.Potato{}, err
+	}
+	return potato, nil
+}
+
+// PlaceAll stores a batch of new potatoes, placing each as Place would.
+// Either they all fit and are saved, or none is. A potato whose ID is
+// already in stock, or repeated in the batch, fails the whole batch rather
+// than replace the existing one; checking under the placement lock means
+// no potato can take the ID before the batch is saved.
+func (s *WarehouseService) PlaceAll(potatoes []models.Potato, save func(models.Potato) error) ([]models.Potato, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stock := s.storage.GetInventoryAggregates().ByLocation
+	placed := make([]models.Potato, 0, len(potatoes))
+	ids := make(map[string]bool, len(potatoes))
+	for _, potato := range potatoes {
+		if _, err := s.storage.GetPotato(potato.ID); err == nil || ids[potato.ID] {
+			return nil, fmt.Errorf("potato %s: %w", potato.ID, ErrDuplicatePotato)
+		}
+		ids[potato.ID] = true
+
+		location, err := s.locationFor(potato, stock)
+		if err != nil {
+			return nil, fmt.Errorf("potato %s: %w", potato.ID, err)
+		}
+		totals := stock[location.ID]
+		totals.Weight += potato.Weight
+		stock[location.ID] = totals
+
+		potato.LocationID = location.ID
+		if location.StorageCondition != "" {
+			potato.StorageCondition = location.StorageCondition
+		}
+		placed = append(placed, potato)
+	}
+	for _, potato := range placed {
+		if err := save(potato); err != nil {
+			return nil, err
+		}
+	}
+	return placed, nil
+}
+
+// Transfer moves potatoes to another location. Either all of them move or,
+// if any is missing or they do not all fit, none do. Each move is logged.
+func (s *WarehouseService) Transfer(request models.TransferRequest) ([]models.Transfer, error) {
+	request.Actor = strings.TrimSpace(request.Actor)
+	if request.Actor == "" {
+		request.Actor = "api"
+	}
+	if len(request.PotatoIDs) == 0 || request.ToLocationID == "" {
+		return nil, ErrInvalidTransfer
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	location, err := s.storage.GetLocation(request.ToLocationID)
+	if err != nil {
+		return nil, ErrUnknownLocation
+	}
+
+	var potatoes []models.Potato
+	incoming := 0.0
+	for _, id := range request.PotatoIDs {
+		if slices.ContainsFunc(potatoes, func(p models.Potato) bool { return p.ID == id }) {
+			continue
+		}
+		potato, err := s.storage.GetPotato(id)
+		if err != nil {
+			return nil, fmt.Errorf("potato %s: %w", id, err)
+		}
+		potatoes = append(potatoes, potato)
+		if potato.LocationID != location.ID {
+			incoming += potato.Weight
+		}
+	}
+	if s.storage.GetInventoryAggregates().ByLocation[location.ID].Weight+incoming > location.CapacityKg+capacityTolerance {
+		return nil, ErrLocationFull
+	}
+
+	now := time.Now()
+	transfers := []models.Transfer{}
+	for _, potato := range potatoes {
+		if potato.LocationID == location.ID {
+			continue
+		}
+		transfer := models.Transfer{
+			ID:             fmt.Sprintf("t%d", transferCounter.Add(1)),
+			Timestamp:      now,
+			Actor:          request.Actor,
+			PotatoID:       potato.ID,
+			Variety:        potato.Variety,
+			WeightKg:       potato.Weight,
+			FromLocationID: potato.LocationID,
+			ToLocationID:   location.ID,
+			Reason:         strings.TrimSpace(request.Reason),
+		}
+		if err := s.storage.SetPotatoLocation(potato.ID, location.ID, location.StorageCondition); err != nil {
+			// Removed by someone else since we looked it up.
+			continue
+		}
+		s.storage.AddTransfer(transfer)
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// GetTransfers returns matching transfers newest first. A location matches
+// transfers into or out of it.
+func (s *WarehouseService) GetTransfers(filter models.TransferFilter) []models.Transfer {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTransferLimit
+	}
+
+	transfers := s.storage.GetTransfers()
+	matching := []models.Transfer{}
+	for i := len(transfers) - 1; i >= 0 && len(matching) < filter.Limit; i-- {
+		transfer := transfers[i]
+		if filter.PotatoID != "" && transfer.PotatoID != filter.PotatoID {
+			continue
+		}
+		if filter.LocationID != "" && transfer.FromLocationID != filter.LocationID && transfer.ToLocationID != filter.LocationID {
+			continue
+		}
+		matching = append(matching, transfer)
+	}
+	return matching
+}
+
+// locationFor resolves where potato will be stored, given the weight
+// already in each location.
+func (s *WarehouseService) locationFor(potato models.Potato, stock map[string]models.AggregateTotals) (models.Location, error) {
+	if potato.LocationID == "" {
+		return s.chooseLocation(potato, stock)
+	}
+	location, err := s.storage.GetLocation(potato.LocationID)
+	if err != nil {
+		return models.Location{}, ErrUnknownLocation
+	}
+	if stock[location.ID].Weight+potato.Weight > location.CapacityKg+capacityTolerance {
+		return models.Location{}, ErrLocationFull
+	}
+	return location, nil
+}
+
+// chooseLocation picks the location with the most free space whose storage
+// condition, if it has one, matches the potato's. Ties go to the lowest ID.
+func (s *WarehouseService) chooseLocation(potato models.Potato, stock map[string]models.AggregateTotals) (models.Location, error) {
+	var best models.Location
+	bestFree := 0.0
+	for _, location := range s.storage.GetAllLocations() {
+		if location.StorageCondition != "" && potato.StorageCondition != "" && location.StorageCondition != potato.StorageCondition {
+			continue
+		}
+		free := location.CapacityKg - stock[location.ID].Weight
+		if free+capacityTolerance < potato.Weight {
+			continue
+		}
+		if best.ID == "" || free > bestFree || (free == bestFree && location.ID < best.ID) {
+			best, bestFree = location, free
+		}
+	}
+	if best.ID == "" {
+		return models.Location{}, ErrNoCapacity
+	}
+	return best, nil
+}
+
+func (s *WarehouseService) normalizeLocation(location models.Location) (models.Location, error) {
+	location.Name = strings.TrimSpace(location.Name)
+	if !validOwner(location.ID) || location.Name == "" || len(location.Name) > maxWarehouseName || location.CapacityKg <= 0 {
+		return models.Location{}, ErrInvalidLocation
+	}
+	if location.StorageCondition != "" {
+		condition, err := s.freshness.StorageCondition(location.StorageCondition)
+		if err != nil {
+			return models.Location{}, err
+		}
+		location.StorageCondition = condition
+	}
+	return location, nil
+}
+
+func (s *WarehouseService) warehouseStock(warehouse models.Warehouse, aggregates models.InventoryAggregates) models.WarehouseStock {
+	stock := models.WarehouseStock{
+		Warehouse: warehouse,
+		Locations: []models.LocationStock{},
+	}
+	for _, location := range s.storage.GetAllLocations() {
+		if location.WarehouseID != warehouse.ID {
+			continue
+		}
+		ls := locationStock(location, aggregates.ByLocation[location.ID])
+		stock.Potatoes += ls.Potatoes
+		stock.UsedKg += ls.UsedKg
+		stock.CapacityKg += ls.CapacityKg
+		stock.Locations = append(stock.Locations, ls)
+	}
+	sort.Slice(stock.Locations, func(i, j int) bool {
+		return stock.Locations[i].ID < stock.Locations[j].ID
+	})
+	stock.UsedKg = roundWeight(stock.UsedKg)
+	stock.CapacityKg = roundWeight(stock.CapacityKg)
+	stock.Utilization = utilization(stock.UsedKg, stock.CapacityKg)
+	return stock
+}
+
+// warehouseInventory totals stock per warehouse from the per-location
+// aggregates. With only set, just that warehouse is included.
+func warehouseInventory(store storage.Storage, aggregates models.InventoryAggregates, only string) []models.WarehouseInventory {
+	byWarehouse := make(map[string]*models.WarehouseInventory)
+	for _, warehouse := range store.GetAllWarehouses() {
+		if only == "" || warehouse.ID == only {
+			byWarehouse[warehouse.ID] = &models.WarehouseInventory{WarehouseID: warehouse.ID, Name: warehouse.Name}
+		}
+	}
+	for _, location := range store.GetAllLocations() {
+		inventory, ok := byWarehouse[location.WarehouseID]
+		if !ok {
+			continue
+		}
+		totals := aggregates.ByLocation[location.ID]
+		inventory.TotalPotatoes += totals.Count
+		inventory.TotalWeight += totals.Weight
+		inventory.TotalValue += totals.Value
+		inventory.CapacityKg += location.CapacityKg
+	}
+
+	inventories := make([]models.WarehouseInventory, 0, len(byWarehouse))
+	for _, inventory := range byWarehouse {
+		inventory.TotalWeight = roundWeight(inventory.TotalWeight)
+		inventory.TotalValue = roundPrice(inventory.TotalValue)
+		inventory.CapacityKg = roundWeight(inventory.CapacityKg)
+		inventory.Utilization = utilization(inventory.TotalWeight, inventory.CapacityKg)
+		inventories = append(inventories, *inventory)
+	}
+	sort.Slice(inventories, func(i, j int) bool {
+		return inventories[i].WarehouseID < inventories[j].WarehouseID
+	})
+	return inventories
+}
+
+func locationStock(location models.Location, totals models.AggregateTotals) models.LocationStock {
+	used := roundWeight(totals.Weight)
+	return models.LocationStock{
+		Location:    location,
+		Potatoes:    totals.Count,
+		UsedKg:      used,
+		FreeKg:      roundWeight(max(location.CapacityKg-used, 0)),
+		Utilization: utilization(used, location.CapacityKg),
+	}
+}
+
+// utilization is the percentage of capacity in use.
+func utilization(used, capacity float64) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return round2(used / capacity * 100)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/freshness"
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+// newTestWarehouseService has one warehouse with a 1 kg bin "a" and a 2 kg
+// bin "b".
+func newTestWarehouseService(t *testing.T) (*WarehouseService, *storage.InMemoryStorage) {
+	t.Helper()
+	rules, err := freshness.NewEngine("")
+	if err != nil {
+		t.Fatalf("freshness rules: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	s := NewWarehouseService(store, rules)
+	if _, err := s.CreateWarehouse(models.Warehouse{ID: "w1", Name: "Main"}); err != nil {
+		t.Fatalf("create warehouse: %v", err)
+	}
+	for _, location := range []models.Location{{ID: "a", Name: "Bin A", CapacityKg: 1}, {ID: "b", Name: "Bin B", CapacityKg: 2}} {
+		if _, err := s.CreateLocation("w1", location); err != nil {
+			t.Fatalf("create location %s: %v", location.ID, err)
+		}
+	}
+	return s, store
+}
+
+func TestPlaceAllCapacity(t *testing.T) {
+	potato := func(id, location string, weight float64) models.Potato {
+		return models.Potato{ID: id, Variety: "Russet", Weight: weight, LocationID: location}
+	}
+	tests := []struct {
+		name         string
+		batch        []models.Potato
+		wantErr      error
+		wantLocation map[string]string
+	}{
+		{
+			name:         "fills a bin exactly",
+			batch:        []models.Potato{potato("p1", "a", 0.5), potato("p2", "a", 0.5)},
+			wantLocation: map[string]string{"p1": "a", "p2": "a"},
+		},
+		{
+			name:    "each fits but not together",
+			batch:   []models.Potato{potato("p1", "a", 0.6), potato("p2", "a", 0.6)},
+			wantErr: ErrLocationFull,
+		},
+		{
+			name:         "unplaced potatoes go where there is most room",
+			batch:        []models.Potato{potato("p1", "", 1.5), potato("p2", "", 0.8), potato("p3", "", 0.4)},
+			wantLocation: map[string]string{"p1": "b", "p2": "a", "p3": "b"},
+		},
+		{
+			name:    "no bin has room for the last potato",
+			batch:   []models.Potato{potato("p1", "", 1.8), potato("p2", "", 0.9), potato("p3", "", 0.5)},
+			wantErr: ErrNoCapacity,
+		},
+		{
+			name:    "unknown location",
+			batch:   []models.Potato{potato("p1", "c", 0.1)},
+			wantErr: ErrUnknownLocation,
+		},
+		{
+			name:    "repeated ID",
+			batch:   []models.Potato{potato("p1", "", 0.1), potato("p1", "", 0.1)},
+			wantErr: ErrDuplicatePotato,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestWarehouseService(t)
+			placed, err := s.PlaceAll(tt.batch, store.AddPotato)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if n := len(store.GetAllPotatoes()); n != 0 {
+					t.Errorf("%d potatoes saved from a refused batch", n)
+				}
+				return
+			}
+			for _, potato := range placed {
+				if potato.LocationID != tt.wantLocation[potato.ID] {
+					t.Errorf("%s placed in %q, want %q", potato.ID, potato.LocationID, tt.wantLocation[potato.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceAllCountsExistingStock(t *testing.T) {
+	s, store := newTestWarehouseService(t)
+	if _, err := s.PlaceAll([]models.Potato{{ID: "p1", Variety: "Russet", Weight: 0.7, LocationID: "a"}}, store.AddPotato); err != nil {
+		t.Fatalf("first batch: %v", err)
+	}
+	_, err := s.PlaceAll([]models.Potato{{ID: "p2", Variety: "Russet", Weight: 0.4, LocationID: "a"}}, store.AddPotato)
+	if !errors.Is(err, ErrLocationFull) {
+		t.Fatalf("got %v, want %v", err, ErrLocationFull)
+	}
+	_, err = s.PlaceAll([]models.Potato{{ID: "p1", Variety: "Russet", Weight: 0.1}}, store.AddPotato)
+	if !errors.Is(err, ErrDuplicatePotato) {
+		t.Fatalf("stocked ID: got %v, want %v", err, ErrDuplicatePotato)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/storage"
+)
+
+const (
+	defaultWasteEntries = 50
+	maxWasteEntries     = 500
+)
+
+var (
+	ErrInvalidRemovalReason = errors.New("reason must be sold, cooked, spoiled, damaged or recalled")
+	ErrInvalidWasteReason   = errors.New("reason must be spoiled, damaged or recalled")
+	ErrInvalidWasteLimit    = errors.New("limit must be between 1 and 500")
+)
+
+type WasteService struct {
+	storage   storage.Storage
+	potatoes  *PotatoService
+	forecasts *ForecastService
+}
+
+func NewWasteService(storage storage.Storage, potatoes *PotatoService, forecasts *ForecastService) *WasteService {
+	return &WasteService{
+		storage:   storage,
+		potatoes:  potatoes,
+		forecasts: forecasts,
+	}
+}
+
+// Report totals the potatoes spoiled, damaged or recalled up to query.To,
+// now by default, per variety and reason, and lists the latest query.Limit
+// of them.
+func (s *WasteService) Report(query models.WasteQuery) (models.WasteReport, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if !query.From.IsZero() && query.To.Before(query.From) {
+		return models.WasteReport{}, ErrInvalidDateRange
+	}
+	if query.Reason != "" && !query.Reason.IsWaste() {
+		return models.WasteReport{}, ErrInvalidWasteReason
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultWasteEntries
+	}
+	if query.Limit < 0 || query.Limit > maxWasteEntries {
+		return models.WasteReport{}, ErrInvalidWasteLimit
+	}
+	if query.Variety != "" {
+		variety, err := canonicalVariety(s.storage, query.Variety)
+		if err != nil {
+			return models.WasteReport{}, err
+		}
+		query.Variety = variety
+	}
+
+	report := models.WasteReport{
+		To:        query.To,
+		Varieties: []models.VarietyWaste{},
+		Entries:   []models.StockMovement{},
+	}
+	if !query.From.IsZero() {
+		report.From = &query.From
+	}
+
+	byVariety := make(map[string]*models.VarietyWaste)
+	for _, movement := range s.storage.GetStockMovements() {
+		if movement.Type != models.StockRemoval || !movement.Reason.IsWaste() {
+			continue
+		}
+		if movement.Timestamp.Before(query.From) || movement.Timestamp.After(query.To) {
+			continue
+		}
+		if (query.Variety != "" && movement.Variety != query.Variety) || (query.Reason != "" && movement.Reason != query.Reason) {
+			continue
+		}
+
+		variety, ok := byVariety[movement.Variety]
+		if !ok {
+			variety = &models.VarietyWaste{
+				Variety:  movement.Variety,
+				ByReason: make(map[models.RemovalReason]models.WasteTotals),
+			}
+			byVariety[movement.Variety] = variety
+		}
+		reason := variety.ByReason[movement.Reason]
+		addWaste(&reason, movement)
+		variety.ByReason[movement.Reason] = reason
+		addWaste(&variety.WasteTotals, movement)
+		addWaste(&report.Total, movement)
+		report.Entries = append(report.Entries, movement)
+	}
+
+	for _, variety := range byVariety {
+		for reason, totals := range variety.ByReason {
+			variety.ByReason[reason] = roundWaste(totals)
+		}
+		variety.WasteTotals = roundWaste(variety.WasteTotals)
+		report.Varieties = append(report.Varieties, *variety)
+	}
+	sort.Slice(report.Varieties, func(i, j int) bool {
+		return report.Varieties[i].Variety < report.Varieties[j].Variety
+	})
+	report.Total = roundWaste(report.Total)
+
+	// Movements are oldest first; keep the latest entries, newest first.
+	if len(report.Entries) > query.Limit {
+		report.Entries = report.Entries[len(report.Entries)-query.Limit:]
+	}
+	for i, j := 0, len(report.Entries)-1; i < j; i, j = i+1, j-1 {
+		report.Entries[i], report.Entries[j] = report.Entries[j], report.Entries[i]
+	}
+	return report, nil
+}
+
+// PredictSpoilage lists potatoes in stock that will go Old before they are
+// likely to be used. Each variety is assumed to be used oldest harvest first
+// at its forecast average daily demand; a variety with no demand is not
+// expected to be used at all. Potatoes already Old are left to the
+// degradation policy, and quarantined potatoes to their recall: they are
+// never used, so they take no place in the queue.
+func (s *WasteService) PredictSpoilage(variety string) (models.SpoilagePrediction, error) {
+	if variety != "" {
+		name, err := canonicalVariety(s.storage, variety)
+		if err != nil {
+			return models.SpoilagePrediction{}, err
+		}
+		variety = name
+	}
+
+	forecasts, err := s.forecasts.Forecasts(0)
+	if err != nil {
+		return models.SpoilagePrediction{}, err
+	}
+	demand := make(map[string]float64, len(forecasts))
+	for _, f := range forecasts {
+		demand[f.Variety] = f.AverageDailyDemand
+	}
+
+	stock := make(map[string][]models.Potato)
+	for _, potato := range s.storage.GetAllPotatoes() {
+		if !potato.Quarantined && (variety == "" || potato.Variety == variety) {
+			stock[potato.Variety] = append(stock[potato.Variety], potato)
+		}
+	}
+
+	now := time.Now()
+	prediction := models.SpoilagePrediction{
+		GeneratedAt: now,
+		Potatoes:    []models.SpoilageRisk{},
+	}
+	for name, potatoes := range stock {
+		sort.Slice(potatoes, func(i, j int) bool {
+			if !potatoes[i].HarvestDate.Equal(potatoes[j].HarvestDate) {
+				return potatoes[i].HarvestDate.Before(potatoes[j].HarvestDate)
+			}
+			return potatoes[i].ID < potatoes[j].ID
+		})
+		for i, potato := range potatoes {
+			report, err := s.potatoes.CalculateFreshness(potato, "")
+			if err != nil || !report.ExpiryDate.After(now) {
+				continue
+			}
+
+			risk := models.SpoilageRisk{
+				PotatoID:     potato.ID,
+				Variety:      name,
+				Quality:      potato.Quality,
+				WeightKg:     potato.Weight,
+				Freshness:    report.Freshness,
+				ExpiryDate:   report.ExpiryDate,
+				PurchaseCost: potato.PurchaseCost,
+				Price:        potato.Price,
+			}
+			if rate := demand[name]; rate > 0 {
+				days := float64(i+1) / rate
+				used := now.Add(time.Duration(days * float64(24*time.Hour)))
+				if !used.After(report.ExpiryDate) {
+					continue
+				}
+				risk.ExpectedUseDate = &used
+			}
+
+			prediction.AtRisk++
+			prediction.WeightKg += risk.WeightKg
+			prediction.CostAtRisk += risk.PurchaseCost
+			prediction.ValueAtRisk += risk.Price
+			prediction.Potatoes = append(prediction.Potatoes, risk)
+		}
+	}
+
+	sort.Slice(prediction.Potatoes, func(i, j int) bool {
+		a, b := prediction.Potatoes[i], prediction.Potatoes[j]
+		if !a.ExpiryDate.Equal(b.ExpiryDate) {
+			return a.ExpiryDate.Before(b.ExpiryDate)
+		}
+		return a.PotatoID < b.PotatoID
+	})
+	prediction.WeightKg = roundWeight(prediction.WeightKg)
+	prediction.CostAtRisk = roundPrice(prediction.CostAtRisk)
+	prediction.ValueAtRisk = roundPrice(prediction.ValueAtRisk)
+	return prediction, nil
+}
+
+// parseRemovalReason defaults to sold, the usual way stock leaves.
+func parseRemovalReason(raw string) (models.RemovalReason, error) {
+	if raw == "" {
+		return models.RemovalSold, nil
+	}
+	for _, reason := range models.RemovalReasons {
+		if string(reason) == raw {
+			return reason, nil
+		}
+	}
+	return "", ErrInvalidRemovalReason
+}
+
+func addWaste(totals *models.WasteTotals, removal models.StockMovement) {
+	totals.Count++
+	totals.WeightKg += removal.WeightKg
+	totals.CostLost += removal.PurchaseCost
+	totals.ValueLost += removal.Price
+}
+
+func roundWaste(totals models.WasteTotals) models.WasteTotals {
+	totals.WeightKg = roundWeight(totals.WeightKg)
+	totals.CostLost = roundPrice(totals.CostLost)
+	totals.ValueLost = roundPrice(totals.ValueLost)
+	return totals
+}
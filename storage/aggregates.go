@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"maps"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// countPotato adds potato to the aggregates, or removes it when sign is -1.
+// Callers must hold the write lock.
+func (s *InMemoryStorage) countPotato(potato models.Potato, sign int) {
+	addTotals(&s.aggregates.Total, potato, sign)
+	s.aggregates.ByVariety = addGroupTotals(s.aggregates.ByVariety, potato.Variety, potato, sign)
+	s.aggregates.ByQuality = addGroupTotals(s.aggregates.ByQuality, potato.Quality, potato, sign)
+	s.aggregates.ByLocation = addGroupTotals(s.aggregates.ByLocation, potato.LocationID, potato, sign)
+}
+
+func addGroupTotals(groups map[string]models.AggregateTotals, key string, potato models.Potato, sign int) map[string]models.AggregateTotals {
+	totals := groups[key]
+	addTotals(&totals, potato, sign)
+	if totals.Count == 0 {
+		// Dropping empty groups also discards rounding residue.
+		delete(groups, key)
+	} else {
+		groups[key] = totals
+	}
+	return groups
+}
+
+func addTotals(totals *models.AggregateTotals, potato models.Potato, sign int) {
+	totals.Count += sign
+	if potato.Quarantined {
+		totals.Quarantined += sign
+	}
+	totals.Weight += float64(sign) * potato.Weight
+	totals.Value += float64(sign) * potato.Price
+	if totals.Count == 0 {
+		totals.Quarantined, totals.Weight, totals.Value = 0, 0, 0
+	}
+}
+
+func newAggregates() models.InventoryAggregates {
+	return models.InventoryAggregates{
+		ByVariety:  make(map[string]models.AggregateTotals),
+		ByQuality:  make(map[string]models.AggregateTotals),
+		ByLocation: make(map[string]models.AggregateTotals),
+	}
+}
+
+func copyAggregates(aggregates models.InventoryAggregates) models.InventoryAggregates {
+	aggregates.ByVariety = maps.Clone(aggregates.ByVariety)
+	aggregates.ByQuality = maps.Clone(aggregates.ByQuality)
+	aggregates.ByLocation = maps.Clone(aggregates.ByLocation)
+	return aggregates
+}
+
+func (s *InMemoryStorage) GetInventoryAggregates() models.InventoryAggregates {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyAggregates(s.aggregates)
+}
+
+// RecountInventoryAggregates returns the maintained aggregates alongside a
+// fresh count of every potato, both taken under one lock so concurrent
+// writes cannot show up as drift.
+func (s *InMemoryStorage) RecountInventoryAggregates() (maintained, recounted models.InventoryAggregates, potatoes int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	recounted = newAggregates()
+	for _, potato := range s.potatoes {
+		addTotals(&recounted.Total, potato, 1)
+		recounted.ByVariety = addGroupTotals(recounted.ByVariety, potato.Variety, potato, 1)
+		recounted.ByQuality = addGroupTotals(recounted.ByQuality, potato.Quality, potato, 1)
+		recounted.ByLocation = addGroupTotals(recounted.ByLocation, potato.LocationID, potato, 1)
+	}
+	return copyAggregates(s.aggregates), recounted, len(s.potatoes)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// The audit log keeps the newest maxAuditEntries entries. Once it grows a
+// quarter past that, the oldest are dropped in one go rather than one per
+// new entry.
+const maxAuditEntries = 10000
+
+func (s *InMemoryStorage) AddAuditEntry(entry models.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auditLog = append(s.auditLog, entry)
+	if len(s.auditLog) > maxAuditEntries+maxAuditEntries/4 {
+		s.auditLog = slices.Clone(s.auditLog[len(s.auditLog)-maxAuditEntries:])
+	}
+	return nil
+}
+
+// GetAuditEntries returns the audit log oldest first.
+func (s *InMemoryStorage) GetAuditEntries() []models.AuditEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.auditLog)
+}
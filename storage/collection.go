@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func (s *InMemoryStorage) AddCollection(collection models.Collection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	collection.RecipeIDs = slices.Clone(collection.RecipeIDs)
+	s.collections[collection.ID] = collection
+	return nil
+}
+
+func (s *InMemoryStorage) GetCollection(id string) (models.Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	collection, exists := s.collections[id]
+	if !exists {
+		return models.Collection{}, ErrCollectionNotFound
+	}
+	collection.RecipeIDs = slices.Clone(collection.RecipeIDs)
+	return collection, nil
+}
+
+func (s *InMemoryStorage) GetCollectionsByOwner(owner string) []models.Collection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	collections := []models.Collection{}
+	for _, collection := range s.collections {
+		if collection.Owner == owner {
+			collection.RecipeIDs = slices.Clone(collection.RecipeIDs)
+			collections = append(collections, collection)
+		}
+	}
+	return collections
+}
+
+func (s *InMemoryStorage) GetCollectionByShareToken(token string) (models.Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, collection := range s.collections {
+		if token != "" && collection.ShareToken == token {
+			collection.RecipeIDs = slices.Clone(collection.RecipeIDs)
+			return collection, nil
+		}
+	}
+	return models.Collection{}, ErrCollectionNotFound
+}
+
+func (s *InMemoryStorage) UpdateCollection(id string, collection models.Collection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.collections[id]; !exists {
+		return ErrCollectionNotFound
+	}
+	collection.RecipeIDs = slices.Clone(collection.RecipeIDs)
+	s.collections[id] = collection
+	return nil
+}
+
+func (s *InMemoryStorage) DeleteCollection(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.collections[id]; !exists {
+		return ErrCollectionNotFound
+	}
+	delete(s.collections, id)
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func (s *InMemoryStorage) AddLot(lot models.HarvestLot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lots[lot.ID] = lot
+	return nil
+}
+
+func (s *InMemoryStorage) GetLot(id string) (models.HarvestLot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lot, exists := s.lots[id]
+	if !exists {
+		return models.HarvestLot{}, ErrLotNotFound
+	}
+	return lot, nil
+}
+
+func (s *InMemoryStorage) GetAllLots() []models.HarvestLot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lots := make([]models.HarvestLot, 0, len(s.lots))
+	for _, lot := range s.lots {
+		lots = append(lots, lot)
+	}
+	return lots
+}
+
+func (s *InMemoryStorage) UpdateLot(id string, lot models.HarvestLot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.lots[id]; !exists {
+		return ErrLotNotFound
+	}
+	s.lots[id] = lot
+	return nil
+}
+
+// The cook log keeps the newest maxCookEvents events, dropping the oldest a
+// quarter past that as the audit log does.
+const maxCookEvents = 10000
+
+func (s *InMemoryStorage) AddCookEvent(event models.CookEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.PotatoIDs = slices.Clone(event.PotatoIDs)
+	event.LotIDs = slices.Clone(event.LotIDs)
+	s.cookEvents = append(s.cookEvents, event)
+	if len(s.cookEvents) > maxCookEvents+maxCookEvents/4 {
+		s.cookEvents = slices.Clone(s.cookEvents[len(s.cookEvents)-maxCookEvents:])
+	}
+	return nil
+}
+
+// GetCookEvents returns the retained recipe cooks oldest first.
+func (s *InMemoryStorage) GetCookEvents() []models.CookEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.cookEvents)
+}
@@ -0,0 +1,55 @@
+package storage
+
+import "github.com/williamdumont/potato-demo/models"
+
+// AddMealPlan refuses an ID that is already taken rather than replace the
+// plan; changes go through UpdateMealPlan.
+func (s *InMemoryStorage) AddMealPlan(plan models.MealPlan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.mealPlans[plan.ID]; exists {
+		return ErrMealPlanExists
+	}
+	s.mealPlans[plan.ID] = plan
+	return nil
+}
+
+func (s *InMemoryStorage) GetMealPlan(id string) (models.MealPlan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plan, exists := s.mealPlans[id]
+	if !exists {
+		return models.MealPlan{}, ErrMealPlanNotFound
+	}
+	return plan, nil
+}
+
+func (s *InMemoryStorage) GetAllMealPlans() []models.MealPlan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plans := make([]models.MealPlan, 0, len(s.mealPlans))
+	for _, plan := range s.mealPlans {
+		plans = append(plans, plan)
+	}
+	return plans
+}
+
+func (s *InMemoryStorage) UpdateMealPlan(id string, plan models.MealPlan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.mealPlans[id]; !exists {
+		return ErrMealPlanNotFound
+	}
+	s.mealPlans[id] = plan
+	return nil
+}
+
+func (s *InMemoryStorage) DeleteMealPlan(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.mealPlans[id]; !exists {
+		return ErrMealPlanNotFound
+	}
+	delete(s.mealPlans, id)
+	return nil
+}
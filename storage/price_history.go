@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// A potato's price history keeps its newest maxPriceRecords records, and is
+// dropped priceHistoryRetention after the potato leaves stock.
+const (
+	maxPriceRecords       = 100
+	priceHistoryRetention = 30 * 24 * time.Hour
+)
+
+// priceHistoryExpiry notes when a potato left stock, so its history can be
+// dropped once the retention period has passed.
+type priceHistoryExpiry struct {
+	potatoID  string
+	removedAt time.Time
+}
+
+// recordPrice appends a price record for potato, unless its price is
+// unchanged, and folds the per-kg price into its variety's daily aggregate.
+// Callers must hold the write lock.
+func (s *InMemoryStorage) recordPrice(potato models.Potato, oldPrice float64) {
+	if oldPrice == potato.Price {
+		return
+	}
+
+	now := time.Now()
+	record := models.PriceRecord{
+		PotatoID:   potato.ID,
+		Variety:    potato.Variety,
+		Quality:    potato.Quality,
+		OldPrice:   oldPrice,
+		Price:      potato.Price,
+		RecordedAt: now,
+	}
+	if potato.Weight > 0 {
+		record.PricePerKg = math.Round(potato.Price/potato.Weight*100) / 100
+	}
+	history := append(s.priceHistory[potato.ID], record)
+	if len(history) > maxPriceRecords {
+		history = slices.Clone(history[len(history)-maxPriceRecords:])
+	}
+	s.priceHistory[potato.ID] = history
+
+	if record.PricePerKg == 0 {
+		return
+	}
+	key := strings.ToLower(potato.Variety)
+	day := now.UTC().Truncate(24 * time.Hour)
+	days := s.dailyPrices[key]
+	if n := len(days); n > 0 && days[n-1].Day.Equal(day) {
+		last := &days[n-1]
+		last.High = max(last.High, record.PricePerKg)
+		last.Low = min(last.Low, record.PricePerKg)
+		last.Close = record.PricePerKg
+		last.Total += record.PricePerKg
+		last.Count++
+		return
+	}
+	s.dailyPrices[key] = append(days, models.DailyPrice{
+		Variety: potato.Variety,
+		Day:     day,
+		Open:    record.PricePerKg,
+		High:    record.PricePerKg,
+		Low:     record.PricePerKg,
+		Close:   record.PricePerKg,
+		Total:   record.PricePerKg,
+		Count:   1,
+	})
+}
+
+// expirePriceHistory schedules a removed potato's history to be dropped and
+// drops any whose retention has run out, unless the potato is back in
+// stock. Callers must hold the write lock.
+func (s *InMemoryStorage) expirePriceHistory(potatoID string, now time.Time) {
+	s.priceExpiry = append(s.priceExpiry, priceHistoryExpiry{potatoID: potatoID, removedAt: now})
+
+	cutoff := now.Add(-priceHistoryRetention)
+	expired := 0
+	for _, entry := range s.priceExpiry {
+		if entry.removedAt.After(cutoff) {
+			break
+		}
+		if _, inStock := s.potatoes[entry.potatoID]; !inStock {
+			delete(s.priceHistory, entry.potatoID)
+		}
+		expired++
+	}
+	s.priceExpiry = s.priceExpiry[expired:]
+}
+
+// GetPriceHistory returns a potato's price records oldest first. History
+// outlives the potato for a while, so recently deleted potatoes still have
+// one.
+func (s *InMemoryStorage) GetPriceHistory(potatoID string) []models.PriceRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.priceHistory[potatoID])
+}
+
+// GetDailyPrices returns a variety's daily aggregates oldest first.
+func (s *InMemoryStorage) GetDailyPrices(variety string) []models.DailyPrice {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.dailyPrices[strings.ToLower(variety)])
+}
+
+// GetPricedVarieties returns the varieties with daily aggregates.
+func (s *InMemoryStorage) GetPricedVarieties() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	varieties := make([]string, 0, len(s.dailyPrices))
+	for _, days := range s.dailyPrices {
+		varieties = append(varieties, days[0].Variety)
+	}
+	return varieties
+}
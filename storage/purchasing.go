@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func (s *InMemoryStorage) AddSupplier(supplier models.Supplier) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suppliers[supplier.ID] = supplier
+	return nil
+}
+
+func (s *InMemoryStorage) GetSupplier(id string) (models.Supplier, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	supplier, exists := s.suppliers[id]
+	if !exists {
+		return models.Supplier{}, ErrSupplierNotFound
+	}
+	return supplier, nil
+}
+
+func (s *InMemoryStorage) GetAllSuppliers() []models.Supplier {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	suppliers := make([]models.Supplier, 0, len(s.suppliers))
+	for _, supplier := range s.suppliers {
+		suppliers = append(suppliers, supplier)
+	}
+	return suppliers
+}
+
+func (s *InMemoryStorage) UpdateSupplier(id string, supplier models.Supplier) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.suppliers[id]; !exists {
+		return ErrSupplierNotFound
+	}
+	s.suppliers[id] = supplier
+	return nil
+}
+
+func (s *InMemoryStorage) AddPurchaseOrder(order models.PurchaseOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purchaseOrders[order.ID] = clonePurchaseOrder(order)
+	return nil
+}
+
+func (s *InMemoryStorage) GetPurchaseOrder(id string) (models.PurchaseOrder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	order, exists := s.purchaseOrders[id]
+	if !exists {
+		return models.PurchaseOrder{}, ErrPurchaseOrderNotFound
+	}
+	return clonePurchaseOrder(order), nil
+}
+
+func (s *InMemoryStorage) GetAllPurchaseOrders() []models.PurchaseOrder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	orders := make([]models.PurchaseOrder, 0, len(s.purchaseOrders))
+	for _, order := range s.purchaseOrders {
+		orders = append(orders, clonePurchaseOrder(order))
+	}
+	return orders
+}
+
+func (s *InMemoryStorage) UpdatePurchaseOrder(id string, order models.PurchaseOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.purchaseOrders[id]; !exists {
+		return ErrPurchaseOrderNotFound
+	}
+	s.purchaseOrders[id] = clonePurchaseOrder(order)
+	return nil
+}
+
+// The receipt log keeps the newest maxReceipts deliveries, dropping the
+// oldest a quarter past that as the audit log does.
+const maxReceipts = 10000
+
+func (s *InMemoryStorage) AddReceipt(receipt models.Receipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purchaseReceipts = append(s.purchaseReceipts, cloneReceipt(receipt))
+	if len(s.purchaseReceipts) > maxReceipts+maxReceipts/4 {
+		s.purchaseReceipts = slices.Clone(s.purchaseReceipts[len(s.purchaseReceipts)-maxReceipts:])
+	}
+	return nil
+}
+
+// GetReceipts returns the retained deliveries oldest first.
+func (s *InMemoryStorage) GetReceipts() []models.Receipt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	receipts := make([]models.Receipt, 0, len(s.purchaseReceipts))
+	for _, receipt := range s.purchaseReceipts {
+		receipts = append(receipts, cloneReceipt(receipt))
+	}
+	return receipts
+}
+
+func clonePurchaseOrder(order models.PurchaseOrder) models.PurchaseOrder {
+	order.Lines = slices.Clone(order.Lines)
+	return order
+}
+
+func cloneReceipt(receipt models.Receipt) models.Receipt {
+	receipt.Lines = slices.Clone(receipt.Lines)
+	for i, line := range receipt.Lines {
+		receipt.Lines[i].Qualities = slices.Clone(line.Qualities)
+		receipt.Lines[i].PotatoIDs = slices.Clone(line.PotatoIDs)
+	}
+	return receipt
+}
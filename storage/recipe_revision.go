@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// maxRecipeRevisions bounds the history kept per recipe. Older revisions are
+// dropped, but revision numbers keep counting, so a number is never reused.
+const maxRecipeRevisions = 100
+
+// saveRecipeRevision stores recipe as the current version and appends an
+// immutable revision for it. Callers must hold the write lock.
+func (s *InMemoryStorage) saveRecipeRevision(recipe models.Recipe) models.Recipe {
+	revisions := s.recipeRevisions[recipe.ID]
+	recipe.Revision = 1
+	if len(revisions) > 0 {
+		recipe.Revision = revisions[len(revisions)-1].Revision + 1
+	}
+	recipe.UpdatedAt = time.Now()
+
+	s.recipes[recipe.ID] = copyRecipe(recipe)
+	revisions = append(revisions, models.RecipeRevision{
+		RecipeID:  recipe.ID,
+		Revision:  recipe.Revision,
+		Author:    recipe.UpdatedBy,
+		CreatedAt: recipe.UpdatedAt,
+		Recipe:    copyRecipe(recipe),
+	})
+	if len(revisions) > maxRecipeRevisions {
+		revisions = slices.Clone(revisions[len(revisions)-maxRecipeRevisions:])
+	}
+	s.recipeRevisions[recipe.ID] = revisions
+
+	return recipe
+}
+
+func copyRecipe(recipe models.Recipe) models.Recipe {
+	recipe.Ingredients = slices.Clone(recipe.Ingredients)
+	recipe.Instructions = slices.Clone(recipe.Instructions)
+	recipe.Tags = slices.Clone(recipe.Tags)
+	recipe.Dietary = slices.Clone(recipe.Dietary)
+	recipe.Allergens = slices.Clone(recipe.Allergens)
+	return recipe
+}
+
+func (s *InMemoryStorage) GetRecipeRevisions(id string) []models.RecipeRevision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revisions := make([]models.RecipeRevision, len(s.recipeRevisions[id]))
+	copy(revisions, s.recipeRevisions[id])
+	return revisions
+}
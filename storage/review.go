@@ -0,0 +1,43 @@
+package storage
+
+import "github.com/williamdumont/potato-demo/models"
+
+func (s *InMemoryStorage) AddReview(review models.Review) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.recipes[review.RecipeID]; !exists {
+		return ErrRecipeNotFound
+	}
+	s.reviews[review.RecipeID] = append(s.reviews[review.RecipeID], review)
+	return nil
+}
+
+func (s *InMemoryStorage) GetReviews(recipeID string) []models.Review {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reviews := make([]models.Review, len(s.reviews[recipeID]))
+	copy(reviews, s.reviews[recipeID])
+	return reviews
+}
+
+func (s *InMemoryStorage) GetAllReviews() map[string][]models.Review {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make(map[string][]models.Review, len(s.reviews))
+	for recipeID, reviews := range s.reviews {
+		all[recipeID] = append([]models.Review(nil), reviews...)
+	}
+	return all
+}
+
+func (s *InMemoryStorage) AddRecipeView(recipeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recipeViews[recipeID]++
+}
+
+func (s *InMemoryStorage) GetRecipeViews(recipeID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recipeViews[recipeID]
+}
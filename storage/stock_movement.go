@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+	"github.com/williamdumont/potato-demo/valuation"
+)
+
+// The stock ledger keeps movements from the last stockMovementRetention, and
+// at most maxStockMovements of them. Older movements are folded into a
+// valuation checkpoint once the ledger grows a quarter past either bound, so
+// reports can still value stock from then on. Daily demand is rolled up as
+// movements are recorded and kept for maxDemandDays.
+const (
+	stockMovementRetention = 35 * 24 * time.Hour
+	maxStockMovements      = 100000
+	maxDemandDays          = 366
+)
+
+// receiveStock records a potato entering stock. Storing a potato that is
+// already in stock records an adjustment instead when its variety, weight or
+// cost has changed. Past movements are never edited, so a correction applies
+// from when it is made and reports for earlier dates stay as they were.
+// Callers must hold the write lock.
+func (s *InMemoryStorage) receiveStock(potato models.Potato, current *models.Potato) {
+	movement := models.StockMovement{
+		Type:         models.StockReceipt,
+		PotatoID:     potato.ID,
+		Variety:      potato.Variety,
+		LotID:        potato.LotID,
+		WeightKg:     potato.Weight,
+		PurchaseCost: potato.PurchaseCost,
+		Timestamp:    time.Now(),
+	}
+	if current != nil {
+		if current.Variety == potato.Variety && current.Weight == potato.Weight && current.PurchaseCost == potato.PurchaseCost {
+			return
+		}
+		movement.Type = models.StockAdjustment
+	}
+	s.recordMovement(movement)
+}
+
+// removeStock records a potato leaving stock and returns the removal.
+// Callers must hold the write lock.
+func (s *InMemoryStorage) removeStock(potato models.Potato, reason models.RemovalReason) models.StockMovement {
+	removal := models.StockMovement{
+		Type:         models.StockRemoval,
+		PotatoID:     potato.ID,
+		Variety:      potato.Variety,
+		Quality:      potato.Quality,
+		LotID:        potato.LotID,
+		WeightKg:     potato.Weight,
+		PurchaseCost: potato.PurchaseCost,
+		Price:        potato.Price,
+		Reason:       reason,
+		Timestamp:    time.Now(),
+	}
+	s.recordMovement(removal)
+	if !reason.IsWaste() {
+		s.countDemand(removal)
+	}
+	return removal
+}
+
+// recordMovement appends movement to the ledger and folds the movements past
+// retention into the checkpoint. Callers must hold the write lock.
+func (s *InMemoryStorage) recordMovement(movement models.StockMovement) {
+	if s.stockSince.IsZero() {
+		s.stockSince = movement.Timestamp
+	}
+	s.stockMovements = append(s.stockMovements, movement)
+
+	cutoff := movement.Timestamp.Add(-stockMovementRetention)
+	if len(s.stockMovements) <= maxStockMovements+maxStockMovements/4 &&
+		!s.stockMovements[0].Timestamp.Before(cutoff.Add(-stockMovementRetention/4)) {
+		return
+	}
+	drop := max(len(s.stockMovements)-maxStockMovements, 0)
+	for drop < len(s.stockMovements) && s.stockMovements[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+	s.stockCheckpoint = valuation.Checkpoint(s.stockCheckpoint, s.stockMovements[:drop])
+	s.stockMovements = slices.Clone(s.stockMovements[drop:])
+}
+
+// countDemand adds a sale or cook to its variety's count for the day and
+// drops days older than maxDemandDays. Callers must hold the write lock.
+func (s *InMemoryStorage) countDemand(removal models.StockMovement) {
+	day := removal.Timestamp.UTC().Truncate(24 * time.Hour)
+	days := s.dailyDemand[removal.Variety]
+	if n := len(days); n > 0 && days[n-1].Day.Equal(day) {
+		days[n-1].Quantity++
+		return
+	}
+	days = append(days, models.DailyDemand{Variety: removal.Variety, Day: day, Quantity: 1})
+	oldest := day.AddDate(0, 0, -maxDemandDays)
+	if !days[0].Day.After(oldest) {
+		kept := 0
+		for kept < len(days) && !days[kept].Day.After(oldest) {
+			kept++
+		}
+		days = slices.Clone(days[kept:])
+	}
+	s.dailyDemand[removal.Variety] = days
+}
+
+// GetStockMovements returns the retained receipts, adjustments and removals
+// oldest first.
+func (s *InMemoryStorage) GetStockMovements() []models.StockMovement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.stockMovements)
+}
+
+// GetStockLedger returns the checkpoint the ledger starts from, zero until
+// movements have been dropped, and the movements since, oldest first.
+func (s *InMemoryStorage) GetStockLedger() (models.StockCheckpoint, []models.StockMovement) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stockCheckpoint, slices.Clone(s.stockMovements)
+}
+
+// GetDailyDemand returns the potatoes sold or cooked per variety and UTC
+// day, oldest day first, and when stock was first recorded.
+func (s *InMemoryStorage) GetDailyDemand() ([]models.DailyDemand, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var demand []models.DailyDemand
+	for _, days := range s.dailyDemand {
+		demand = append(demand, days...)
+	}
+	slices.SortFunc(demand, func(a, b models.DailyDemand) int {
+		return a.Day.Compare(b.Day)
+	})
+	return demand, s.stockSince
+}
@@ -3,13 +3,27 @@ package storage
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/williamdumont/potato-demo/models"
 )
 
 var (
-	ErrNotFound      = errors.New("potato not found")
-	ErrRecipeNotFound = errors.New("recipe not found")
+	ErrNotFound              = errors.New("potato not found")
+	ErrRecipeNotFound        = errors.New("recipe not found")
+	ErrRecipeExists          = errors.New("recipe already exists")
+	ErrMealPlanNotFound      = errors.New("meal plan not found")
+	ErrMealPlanExists        = errors.New("meal plan already exists")
+	ErrRevisionNotFound      = errors.New("recipe revision not found")
+	ErrCollectionNotFound    = errors.New("collection not found")
+	ErrVarietyNotFound       = errors.New("variety not found")
+	ErrVarietyExists         = errors.New("variety already exists")
+	ErrWarehouseNotFound     = errors.New("warehouse not found")
+	ErrLocationNotFound      = errors.New("location not found")
+	ErrLotNotFound           = errors.New("harvest lot not found")
+	ErrSupplierNotFound      = errors.New("supplier not found")
+	ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+	ErrPotatoQuarantined     = errors.New("potato is quarantined by a recall and can only be removed as spoiled, damaged or recalled")
 )
 
 type Storage interface {
@@ -17,31 +31,170 @@ type Storage interface {
 	GetPotato(id string) (models.Potato, error)
 	GetAllPotatoes() []models.Potato
 	UpdatePotato(id string, potato models.Potato) error
-	DeletePotato(id string) error
+	SetPotatoPrice(id string, price float64) error
+	SetPotatoQuality(id string, quality string) error
+	SetPotatoQuarantined(id string, quarantined bool) error
+	SetPotatoLocation(id, locationID, storageCondition string) error
+	DeletePotato(id string, reason models.RemovalReason) error
 	GetPotatoesByVariety(variety string) []models.Potato
-	
+
 	AddRecipe(recipe models.Recipe) error
 	GetRecipe(id string) (models.Recipe, error)
 	GetAllRecipes() []models.Recipe
 	GetRecipesByVariety(variety string) []models.Recipe
+	UpdateRecipe(id string, recipe models.Recipe) error
+	GetRecipeRevisions(id string) []models.RecipeRevision
+
+	AddMealPlan(plan models.MealPlan) error
+	GetMealPlan(id string) (models.MealPlan, error)
+	GetAllMealPlans() []models.MealPlan
+	UpdateMealPlan(id string, plan models.MealPlan) error
+	DeleteMealPlan(id string) error
+
+	AddReview(review models.Review) error
+	GetReviews(recipeID string) []models.Review
+	GetAllReviews() map[string][]models.Review
+	AddRecipeView(recipeID string)
+	GetRecipeViews(recipeID string) int
+
+	AddCollection(collection models.Collection) error
+	GetCollection(id string) (models.Collection, error)
+	GetCollectionsByOwner(owner string) []models.Collection
+	GetCollectionByShareToken(token string) (models.Collection, error)
+	UpdateCollection(id string, collection models.Collection) error
+	DeleteCollection(id string) error
+
+	AddVariety(variety models.Variety) error
+	GetVariety(name string) (models.Variety, error)
+	GetAllVarieties() []models.Variety
+	UpdateVariety(name string, variety models.Variety) error
+	DeleteVariety(name string) error
+
+	AddAuditEntry(entry models.AuditEntry) error
+	GetAuditEntries() []models.AuditEntry
+
+	GetPriceHistory(potatoID string) []models.PriceRecord
+	GetDailyPrices(variety string) []models.DailyPrice
+	GetPricedVarieties() []string
+
+	GetInventoryAggregates() models.InventoryAggregates
+	RecountInventoryAggregates() (maintained, recounted models.InventoryAggregates, potatoes int)
+
+	GetStockMovements() []models.StockMovement
+	GetStockLedger() (models.StockCheckpoint, []models.StockMovement)
+	GetDailyDemand() ([]models.DailyDemand, time.Time)
+
+	AddWarehouse(warehouse models.Warehouse) error
+	GetWarehouse(id string) (models.Warehouse, error)
+	GetAllWarehouses() []models.Warehouse
+	DeleteWarehouse(id string) error
+	AddLocation(location models.Location) error
+	GetLocation(id string) (models.Location, error)
+	GetAllLocations() []models.Location
+	UpdateLocation(id string, location models.Location) error
+	DeleteLocation(id string) error
+	AddTransfer(transfer models.Transfer) error
+	GetTransfers() []models.Transfer
+
+	AddLot(lot models.HarvestLot) error
+	GetLot(id string) (models.HarvestLot, error)
+	GetAllLots() []models.HarvestLot
+	UpdateLot(id string, lot models.HarvestLot) error
+	AddCookEvent(event models.CookEvent) error
+	GetCookEvents() []models.CookEvent
+
+	AddSupplier(supplier models.Supplier) error
+	GetSupplier(id string) (models.Supplier, error)
+	GetAllSuppliers() []models.Supplier
+	UpdateSupplier(id string, supplier models.Supplier) error
+	AddPurchaseOrder(order models.PurchaseOrder) error
+	GetPurchaseOrder(id string) (models.PurchaseOrder, error)
+	GetAllPurchaseOrders() []models.PurchaseOrder
+	UpdatePurchaseOrder(id string, order models.PurchaseOrder) error
+	AddReceipt(receipt models.Receipt) error
+	GetReceipts() []models.Receipt
 }
 
+// RecipeListener is called after a recipe has been stored, outside the
+// storage lock, so derived structures such as search indexes stay in sync no
+// matter who writes the recipe. Listeners are called in the order recipes
+// were stored, so a stale version never overwrites a newer one; a listener
+// must not store recipes itself.
+type RecipeListener func(recipe models.Recipe)
+
+// RemovalListener is called after a potato has been removed from stock,
+// outside the storage lock.
+type RemovalListener func(removal models.StockMovement)
+
 type InMemoryStorage struct {
-	potatoes map[string]models.Potato
-	recipes  map[string]models.Recipe
-	mu       sync.RWMutex
+	potatoes         map[string]models.Potato
+	recipes          map[string]models.Recipe
+	recipeRevisions  map[string][]models.RecipeRevision
+	mealPlans        map[string]models.MealPlan
+	reviews          map[string][]models.Review
+	recipeViews      map[string]int
+	collections      map[string]models.Collection
+	varieties        map[string]models.Variety
+	auditLog         []models.AuditEntry
+	priceHistory     map[string][]models.PriceRecord
+	priceExpiry      []priceHistoryExpiry
+	dailyPrices      map[string][]models.DailyPrice
+	aggregates       models.InventoryAggregates
+	stockMovements   []models.StockMovement
+	stockCheckpoint  models.StockCheckpoint
+	stockSince       time.Time
+	dailyDemand      map[string][]models.DailyDemand
+	warehouses       map[string]models.Warehouse
+	locations        map[string]models.Location
+	transfers        []models.Transfer
+	lots             map[string]models.HarvestLot
+	cookEvents       []models.CookEvent
+	suppliers        map[string]models.Supplier
+	purchaseOrders   map[string]models.PurchaseOrder
+	purchaseReceipts []models.Receipt
+	recipeListeners  []RecipeListener
+	removalListeners []RemovalListener
+	mu               sync.RWMutex
+	// recipeSaveMu is held from storing a recipe until its listeners have
+	// run, without blocking readers the way holding mu would.
+	recipeSaveMu sync.Mutex
 }
 
 func NewInMemoryStorage() *InMemoryStorage {
 	return &InMemoryStorage{
-		potatoes: make(map[string]models.Potato),
-		recipes:  make(map[string]models.Recipe),
+		potatoes:        make(map[string]models.Potato),
+		recipes:         make(map[string]models.Recipe),
+		recipeRevisions: make(map[string][]models.RecipeRevision),
+		mealPlans:       make(map[string]models.MealPlan),
+		reviews:         make(map[string][]models.Review),
+		recipeViews:     make(map[string]int),
+		collections:     make(map[string]models.Collection),
+		varieties:       make(map[string]models.Variety),
+		priceHistory:    make(map[string][]models.PriceRecord),
+		dailyPrices:     make(map[string][]models.DailyPrice),
+		aggregates:      newAggregates(),
+		dailyDemand:     make(map[string][]models.DailyDemand),
+		warehouses:      make(map[string]models.Warehouse),
+		locations:       make(map[string]models.Location),
+		lots:            make(map[string]models.HarvestLot),
+		suppliers:       make(map[string]models.Supplier),
+		purchaseOrders:  make(map[string]models.PurchaseOrder),
 	}
 }
 
 func (s *InMemoryStorage) AddPotato(potato models.Potato) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	current, exists := s.potatoes[potato.ID]
+	var previous *models.Potato
+	if exists {
+		s.countPotato(current, -1)
+		potato.Quarantined = potato.Quarantined || current.Quarantined
+		previous = &current
+	}
+	s.recordPrice(potato, current.Price)
+	s.countPotato(potato, 1)
+	s.receiveStock(potato, previous)
 	s.potatoes[potato.ID] = potato
 	return nil
 }
@@ -69,23 +222,123 @@ func (s *InMemoryStorage) GetAllPotatoes() []models.Potato {
 func (s *InMemoryStorage) UpdatePotato(id string, potato models.Potato) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, exists := s.potatoes[id]; !exists {
+	current, exists := s.potatoes[id]
+	if !exists {
+		return ErrNotFound
+	}
+	potato.ID = id
+	// Only SetPotatoQuarantined lifts a quarantine, so a write based on an
+	// older copy of the potato cannot release recalled stock.
+	potato.Quarantined = potato.Quarantined || current.Quarantined
+	s.recordPrice(potato, current.Price)
+	s.countPotato(current, -1)
+	s.countPotato(potato, 1)
+	s.receiveStock(potato, &current)
+	s.potatoes[id] = potato
+	return nil
+}
+
+// SetPotatoPrice changes only the price, so repricing cannot undo a
+// concurrent change to the rest of the potato.
+func (s *InMemoryStorage) SetPotatoPrice(id string, price float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	potato, exists := s.potatoes[id]
+	if !exists {
 		return ErrNotFound
 	}
+	s.countPotato(potato, -1)
+	oldPrice := potato.Price
+	potato.Price = price
+	s.recordPrice(potato, oldPrice)
+	s.countPotato(potato, 1)
 	s.potatoes[id] = potato
 	return nil
 }
 
-func (s *InMemoryStorage) DeletePotato(id string) error {
+// SetPotatoQuality changes only the quality, so degradation cannot undo a
+// concurrent change to the rest of the potato.
+func (s *InMemoryStorage) SetPotatoQuality(id string, quality string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, exists := s.potatoes[id]; !exists {
+	potato, exists := s.potatoes[id]
+	if !exists {
 		return ErrNotFound
 	}
+	s.countPotato(potato, -1)
+	potato.Quality = quality
+	s.countPotato(potato, 1)
+	s.potatoes[id] = potato
+	return nil
+}
+
+// SetPotatoQuarantined changes only the quarantine flag.
+func (s *InMemoryStorage) SetPotatoQuarantined(id string, quarantined bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	potato, exists := s.potatoes[id]
+	if !exists {
+		return ErrNotFound
+	}
+	s.countPotato(potato, -1)
+	potato.Quarantined = quarantined
+	s.countPotato(potato, 1)
+	s.potatoes[id] = potato
+	return nil
+}
+
+// SetPotatoLocation moves a potato to another location, which sets its
+// storage condition unless that is empty.
+func (s *InMemoryStorage) SetPotatoLocation(id, locationID, storageCondition string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	potato, exists := s.potatoes[id]
+	if !exists {
+		return ErrNotFound
+	}
+	s.countPotato(potato, -1)
+	potato.LocationID = locationID
+	if storageCondition != "" {
+		potato.StorageCondition = storageCondition
+	}
+	s.countPotato(potato, 1)
+	s.potatoes[id] = potato
+	return nil
+}
+
+// DeletePotato removes a potato from stock. A quarantined potato can only
+// leave as waste, checked here so a caller working from an older copy
+// cannot sell it.
+func (s *InMemoryStorage) DeletePotato(id string, reason models.RemovalReason) error {
+	s.mu.Lock()
+	potato, exists := s.potatoes[id]
+	if !exists {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	if potato.Quarantined && !reason.IsWaste() {
+		s.mu.Unlock()
+		return ErrPotatoQuarantined
+	}
+	s.countPotato(potato, -1)
+	removal := s.removeStock(potato, reason)
 	delete(s.potatoes, id)
+	s.expirePriceHistory(id, removal.Timestamp)
+	listeners := s.removalListeners
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(removal)
+	}
 	return nil
 }
 
+func (s *InMemoryStorage) OnPotatoRemoved(listener RemovalListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removalListeners = append(s.removalListeners, listener)
+}
+
 func (s *InMemoryStorage) GetPotatoesByVariety(variety string) []models.Potato {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -98,10 +351,50 @@ func (s *InMemoryStorage) GetPotatoesByVariety(variety string) []models.Potato {
 	return potatoes
 }
 
-func (s *InMemoryStorage) AddRecipe(recipe models.Recipe) error {
+func (s *InMemoryStorage) OnRecipeSaved(listener RecipeListener) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.recipes[recipe.ID] = recipe
+	s.recipeListeners = append(s.recipeListeners, listener)
+}
+
+// AddRecipe stores a new recipe as its first revision. An ID that is already
+// taken is refused; only UpdateRecipe adds revisions to a recipe.
+func (s *InMemoryStorage) AddRecipe(recipe models.Recipe) error {
+	s.recipeSaveMu.Lock()
+	defer s.recipeSaveMu.Unlock()
+
+	s.mu.Lock()
+	if _, exists := s.recipes[recipe.ID]; exists {
+		s.mu.Unlock()
+		return ErrRecipeExists
+	}
+	recipe = s.saveRecipeRevision(recipe)
+	listeners := s.recipeListeners
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(recipe)
+	}
+	return nil
+}
+
+func (s *InMemoryStorage) UpdateRecipe(id string, recipe models.Recipe) error {
+	s.recipeSaveMu.Lock()
+	defer s.recipeSaveMu.Unlock()
+
+	s.mu.Lock()
+	if _, exists := s.recipes[id]; !exists {
+		s.mu.Unlock()
+		return ErrRecipeNotFound
+	}
+	recipe.ID = id
+	recipe = s.saveRecipeRevision(recipe)
+	listeners := s.recipeListeners
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(recipe)
+	}
 	return nil
 }
 
@@ -136,4 +429,3 @@ func (s *InMemoryStorage) GetRecipesByVariety(variety string) []models.Recipe {
 	}
 	return recipes
 }
-
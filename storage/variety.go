@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// Varieties are keyed by their lower-cased name so lookups are
+// case-insensitive. Alias resolution is left to the service.
+
+func varietyKey(name string) string {
+	return strings.ToLower(name)
+}
+
+func copyVariety(variety models.Variety) models.Variety {
+	variety.Aliases = slices.Clone(variety.Aliases)
+	variety.BestCookingMethods = slices.Clone(variety.BestCookingMethods)
+	return variety
+}
+
+// AddVariety refuses a name that is already catalogued rather than replace
+// the entry; changes go through UpdateVariety.
+func (s *InMemoryStorage) AddVariety(variety models.Variety) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.varieties[varietyKey(variety.Name)]; exists {
+		return ErrVarietyExists
+	}
+	s.varieties[varietyKey(variety.Name)] = copyVariety(variety)
+	return nil
+}
+
+func (s *InMemoryStorage) GetVariety(name string) (models.Variety, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	variety, exists := s.varieties[varietyKey(name)]
+	if !exists {
+		return models.Variety{}, ErrVarietyNotFound
+	}
+	return copyVariety(variety), nil
+}
+
+func (s *InMemoryStorage) GetAllVarieties() []models.Variety {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	varieties := make([]models.Variety, 0, len(s.varieties))
+	for _, variety := range s.varieties {
+		varieties = append(varieties, copyVariety(variety))
+	}
+	return varieties
+}
+
+func (s *InMemoryStorage) UpdateVariety(name string, variety models.Variety) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.varieties[varietyKey(name)]; !exists {
+		return ErrVarietyNotFound
+	}
+	s.varieties[varietyKey(name)] = copyVariety(variety)
+	return nil
+}
+
+func (s *InMemoryStorage) DeleteVariety(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.varieties[varietyKey(name)]; !exists {
+		return ErrVarietyNotFound
+	}
+	delete(s.varieties, varietyKey(name))
+	return nil
+}
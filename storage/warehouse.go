@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func (s *InMemoryStorage) AddWarehouse(warehouse models.Warehouse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warehouses[warehouse.ID] = warehouse
+	return nil
+}
+
+func (s *InMemoryStorage) GetWarehouse(id string) (models.Warehouse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	warehouse, exists := s.warehouses[id]
+	if !exists {
+		return models.Warehouse{}, ErrWarehouseNotFound
+	}
+	return warehouse, nil
+}
+
+func (s *InMemoryStorage) GetAllWarehouses() []models.Warehouse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	warehouses := make([]models.Warehouse, 0, len(s.warehouses))
+	for _, warehouse := range s.warehouses {
+		warehouses = append(warehouses, warehouse)
+	}
+	return warehouses
+}
+
+func (s *InMemoryStorage) DeleteWarehouse(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.warehouses[id]; !exists {
+		return ErrWarehouseNotFound
+	}
+	delete(s.warehouses, id)
+	return nil
+}
+
+func (s *InMemoryStorage) AddLocation(location models.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locations[location.ID] = location
+	return nil
+}
+
+func (s *InMemoryStorage) GetLocation(id string) (models.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	location, exists := s.locations[id]
+	if !exists {
+		return models.Location{}, ErrLocationNotFound
+	}
+	return location, nil
+}
+
+func (s *InMemoryStorage) GetAllLocations() []models.Location {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	locations := make([]models.Location, 0, len(s.locations))
+	for _, location := range s.locations {
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+func (s *InMemoryStorage) UpdateLocation(id string, location models.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.locations[id]; !exists {
+		return ErrLocationNotFound
+	}
+	s.locations[id] = location
+	return nil
+}
+
+func (s *InMemoryStorage) DeleteLocation(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.locations[id]; !exists {
+		return ErrLocationNotFound
+	}
+	delete(s.locations, id)
+	return nil
+}
+
+// The transfer log keeps the newest maxTransfers transfers, dropping the
+// oldest a quarter past that as the audit log does.
+const maxTransfers = 10000
+
+func (s *InMemoryStorage) AddTransfer(transfer models.Transfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transfers = append(s.transfers, transfer)
+	if len(s.transfers) > maxTransfers+maxTransfers/4 {
+		s.transfers = slices.Clone(s.transfers[len(s.transfers)-maxTransfers:])
+	}
+	return nil
+}
+
+// GetTransfers returns the transfer log oldest first.
+func (s *InMemoryStorage) GetTransfers() []models.Transfer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.transfers)
+}
@@ -0,0 +1,47 @@
+package taxonomy
+
+import (
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// allergenCatalog lists the keywords that reveal each allergen in a
+// lower-cased ingredient line. As with dietary flags, exceptions and
+// qualified words are removed from the line before matching so "butternut
+// squash" is not a tree nut, "scalloped" is not shellfish and "gluten-free
+// breadcrumbs" do not contain gluten.
+var allergenCatalog = []struct {
+	allergen   models.Allergen
+	keywords   []string
+	exceptions []string
+	qualifiers []string
+}{
+	{models.AllergenDairy, dairyKeywords, dairyExceptions, dairyQualifiers},
+	{models.AllergenGluten, glutenKeywords, glutenExceptions, glutenQualifiers},
+	{models.AllergenEggs, []string{"egg", "mayonnaise", "aioli", "meringue"}, []string{"eggplant"}, nil},
+	{models.AllergenPeanuts, []string{"peanut", "groundnut"}, nil, nil},
+	{models.AllergenTreeNuts, []string{"almond", "walnut", "pecan", "cashew", "hazelnut", "pistachio", "macadamia", "pine nut", "brazil nut"}, nil, nil},
+	{models.AllergenSoy, []string{"soy", "tofu", "edamame", "miso", "tamari", "tempeh"}, nil, nil},
+	{models.AllergenFish, []string{"fish", "anchov", "salmon", "tuna", "trout", "cod ", "worcestershire"}, []string{"shellfish"}, nil},
+	{models.AllergenShellfish, []string{"shrimp", "prawn", "crab", "lobster", "clam", "mussel", "oyster", "scallop", "shellfish"}, []string{"scalloped"}, nil},
+	{models.AllergenSesame, []string{"sesame", "tahini"}, nil, nil},
+	{models.AllergenMustard, []string{"mustard"}, nil, nil},
+}
+
+// DetectAllergens matches an ingredient list against the allergen catalog.
+// The result follows catalog order and is never nil, so responses always
+// carry an explicit (possibly empty) list.
+func DetectAllergens(ingredients []string) []models.Allergen {
+	found := []models.Allergen{}
+	for _, entry := range allergenCatalog {
+		for _, ingredient := range ingredients {
+			line := strip(strings.ToLower(ingredient)+" ", entry.exceptions, entry.qualifiers)
+			if containsAny(line, entry.keywords) {
+				found = append(found, entry.allergen)
+				break
+			}
+		}
+	}
+	return found
+}
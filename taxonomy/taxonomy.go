@@ -0,0 +1,157 @@
+package taxonomy
+
+import (
+	"strings"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// Keyword lists are matched against lower-cased ingredient lines at the start
+// of a word, so "ham" does not match "champignon" while "breadcrumbs" still
+// counts as bread. Exceptions are removed first so "coconut milk" or "peanut
+// butter" do not count as dairy, and qualifiers such as "gluten-free" are
+// removed together with the word they describe.
+var (
+	dairyKeywords     = []string{"butter", "milk", "cream", "cheese", "parmesan", "cheddar", "yogurt", "ghee", "buttermilk"}
+	dairyExceptions   = []string{"coconut milk", "oat milk", "almond milk", "soy milk", "peanut butter", "vegan butter", "coconut cream", "cocoa butter", "butternut", "cream of tartar"}
+	dairyQualifiers   = []string{"dairy-free", "dairy free"}
+	animalExceptions  = []string{"eggplant"}
+	animalKeywords    = []string{"bacon", "ham", "pork", "beef", "chicken", "turkey", "sausage", "egg", "fish", "anchov", "shrimp", "gelatin", "lard", "honey", "mayonnaise", "chorizo", "pancetta"}
+	glutenKeywords    = []string{"flour", "bread", "panko", "pasta", "wheat", "barley", "rye", "beer", "soy sauce", "couscous", "noodle", "cracker"}
+	glutenExceptions  = []string{"tamari", "rice flour", "potato flour", "almond flour"}
+	glutenQualifiers  = []string{"gluten-free", "gluten free"}
+	cookingMethodHint = []struct {
+		keywords []string
+		method   models.CookingMethod
+	}{
+		{[]string{"mash", "purée", "puree"}, models.Mashed},
+		{[]string{"fries", "fried", "fry", "chips", "pancake", "hash", "confit", "latke", "rösti", "rosti"}, models.Fried},
+		{[]string{"roast", "herbed", "medley", "crispy"}, models.Roasted},
+		{[]string{"soup", "salad", "boil", "gnocchi", "stew", "chowder"}, models.Boiled},
+		{[]string{"bake", "baked", "casserole", "gratin", "scalloped", "wedge", "candied", "hasselback"}, models.Baked},
+	}
+)
+
+// InferDietary derives dietary flags from an ingredient list. A recipe only
+// gets a flag when none of its ingredients rule it out.
+func InferDietary(ingredients []string) []models.DietaryFlag {
+	dairy, animal, gluten := false, false, false
+	for _, ingredient := range ingredients {
+		line := strings.ToLower(ingredient)
+		dairy = dairy || containsAny(strip(line, dairyExceptions, dairyQualifiers), dairyKeywords)
+		animal = animal || containsAny(stripAll(line, animalExceptions), animalKeywords)
+		gluten = gluten || containsAny(strip(line, glutenExceptions, glutenQualifiers), glutenKeywords)
+	}
+
+	flags := []models.DietaryFlag{}
+	if !dairy && !animal {
+		flags = append(flags, models.Vegan)
+	}
+	if !gluten {
+		flags = append(flags, models.GlutenFree)
+	}
+	if !dairy {
+		flags = append(flags, models.DairyFree)
+	}
+	return flags
+}
+
+// InferCookingMethod guesses the cooking method from the recipe name, falling
+// back to the instructions. It returns an empty method when nothing matches.
+func InferCookingMethod(name string, instructions []string) models.CookingMethod {
+	candidates := append([]string{name}, instructions...)
+	for _, text := range candidates {
+		text = strings.ToLower(text)
+		for _, hint := range cookingMethodHint {
+			if containsAny(text, hint.keywords) {
+				return hint.method
+			}
+		}
+	}
+	return ""
+}
+
+// ContainsWord reports whether text contains word as a whole word, allowing
+// a plural "s" or "es". Both are expected in lower case.
+func ContainsWord(text, word string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		if wordStart(text, start) {
+			for _, suffix := range []string{"", "s", "es"} {
+				if strings.HasPrefix(text[end:], suffix) &&
+					(end+len(suffix) == len(text) || !isWordByte(text[end+len(suffix)])) {
+					return true
+				}
+			}
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+// containsAny reports whether any keyword begins a word of text.
+func containsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		for offset := 0; offset < len(text); {
+			i := strings.Index(text[offset:], keyword)
+			if i < 0 {
+				break
+			}
+			if wordStart(text, offset+i) {
+				return true
+			}
+			offset += i + 1
+		}
+	}
+	return false
+}
+
+// strip removes exception phrases and then every qualifier together with the
+// word that follows it, so "gluten-free flour" leaves nothing to match.
+func strip(text string, exceptions, qualifiers []string) string {
+	text = stripAll(text, exceptions)
+	for _, qualifier := range qualifiers {
+		for offset := 0; offset < len(text); {
+			i := strings.Index(text[offset:], qualifier)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			if !wordStart(text, start) {
+				offset = start + 1
+				continue
+			}
+			end := start + len(qualifier)
+			for end < len(text) && (text[end] == ' ' || text[end] == '-') {
+				end++
+			}
+			for end < len(text) && isWordByte(text[end]) {
+				end++
+			}
+			text = text[:start] + " " + text[end:]
+			offset = start + 1
+		}
+	}
+	return text
+}
+
+func stripAll(text string, phrases []string) string {
+	for _, phrase := range phrases {
+		text = strings.ReplaceAll(text, phrase, "")
+	}
+	return text
+}
+
+func wordStart(text string, i int) bool {
+	return i == 0 || !isWordByte(text[i-1])
+}
+
+// isWordByte treats every byte of a multi-byte character as part of a word,
+// so accented letters do not split one.
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b >= 0x80
+}
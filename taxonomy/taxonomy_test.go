@@ -0,0 +1,106 @@
+package taxonomy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+func TestInferDietaryMatchesWordStarts(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingredients []string
+		want        []models.DietaryFlag
+	}{
+		{"champignon is not ham", []string{"200 g champignons", "1 kg potatoes"}, []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree}},
+		{"ham", []string{"100 g diced ham"}, []models.DietaryFlag{models.GlutenFree, models.DairyFree}},
+		{"plural", []string{"2 eggs"}, []models.DietaryFlag{models.GlutenFree, models.DairyFree}},
+		{"compound", []string{"1 cup breadcrumbs"}, []models.DietaryFlag{models.Vegan, models.DairyFree}},
+		{"graham cracker", []string{"4 graham crackers"}, []models.DietaryFlag{models.Vegan, models.DairyFree}},
+		{"exception", []string{"1 eggplant", "400 ml coconut milk"}, []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree}},
+		{"punctuation", []string{"potatoes (or cheese)"}, []models.DietaryFlag{models.GlutenFree}},
+		{"gluten-free flour", []string{"1 cup gluten-free flour"}, []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree}},
+		{"gluten free pasta", []string{"200 g gluten free pasta"}, []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree}},
+		{"qualifier covers one word", []string{"1 cup gluten-free flour and panko"}, []models.DietaryFlag{models.Vegan, models.DairyFree}},
+		{"dairy-free cheese", []string{"50 g dairy-free cheese"}, []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree}},
+		{"cream of tartar", []string{"1/2 tsp cream of tartar"}, []models.DietaryFlag{models.Vegan, models.GlutenFree, models.DairyFree}},
+		{"cream", []string{"100 ml sour cream"}, []models.DietaryFlag{models.GlutenFree}},
+	}
+	for _, tt := range tests {
+		if got := InferDietary(tt.ingredients); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: InferDietary(%q) = %v, want %v", tt.name, tt.ingredients, got, tt.want)
+		}
+	}
+}
+
+func TestInferCookingMethod(t *testing.T) {
+	tests := []struct {
+		name         string
+		instructions []string
+		want         models.CookingMethod
+	}{
+		{"Garlic Mashed Potatoes", nil, models.Mashed},
+		{"Stir-fry Potatoes", nil, models.Fried},
+		{"Potato Wedges", nil, models.Baked},
+		{"Shashlik Potatoes", nil, ""},
+		{"Grandma's Potatoes", []string{"Roast for 40 minutes"}, models.Roasted},
+	}
+	for _, tt := range tests {
+		if got := InferCookingMethod(tt.name, tt.instructions); got != tt.want {
+			t.Errorf("InferCookingMethod(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectAllergens(t *testing.T) {
+	got := DetectAllergens([]string{"1 butternut squash", "2 tbsp scalloped onions", "1 tsp Dijon mustard", "3 eggs"})
+	want := []models.Allergen{models.AllergenEggs, models.AllergenMustard}
+	if !slices.Equal(got, want) {
+		t.Errorf("DetectAllergens = %v, want %v", got, want)
+	}
+
+	if got := DetectAllergens(nil); got == nil || len(got) != 0 {
+		t.Errorf("DetectAllergens(nil) = %#v, want an empty list", got)
+	}
+}
+
+func TestDetectAllergensQualifiers(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingredients []string
+		want        []models.Allergen
+	}{
+		{"gluten-free breadcrumbs", []string{"1 cup gluten-free breadcrumbs"}, []models.Allergen{}},
+		{"gluten free pasta", []string{"200 g gluten free pasta"}, []models.Allergen{}},
+		{"other allergens kept", []string{"2 tbsp gluten-free soy sauce"}, []models.Allergen{models.AllergenSoy}},
+		{"plain breadcrumbs", []string{"1 cup breadcrumbs"}, []models.Allergen{models.AllergenGluten}},
+		{"dairy-free cheese", []string{"50 g dairy-free cheese"}, []models.Allergen{}},
+		{"cream of tartar", []string{"1/2 tsp cream of tartar"}, []models.Allergen{}},
+	}
+	for _, tt := range tests {
+		if got := DetectAllergens(tt.ingredients); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: DetectAllergens(%q) = %v, want %v", tt.name, tt.ingredients, got, tt.want)
+		}
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		text, word string
+		want       bool
+	}{
+		{"3 yams", "yam", true},
+		{"2 sweet potatoes", "sweet potato", true},
+		{"red onion", "red", true},
+		{"reduced cream", "red", false},
+		{"sweetener", "sweet", false},
+		{"yukon", "yukon", true},
+		{"", "yam", false},
+	}
+	for _, tt := range tests {
+		if got := ContainsWord(tt.text, tt.word); got != tt.want {
+			t.Errorf("ContainsWord(%q, %q) = %v, want %v", tt.text, tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package timeseries
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrUnknownResolution = errors.New("unknown resolution")
+
+// Tier keeps one bucket per Step for Retention. Every sample is folded into
+// every tier, so coarse tiers are built up as samples arrive rather than by
+// a separate compaction pass.
+type Tier struct {
+	Name      string
+	Step      time.Duration
+	Retention time.Duration
+}
+
+// Point is the average of the samples that fell into one bucket, with each
+// value summed over the keys the query matched.
+type Point struct {
+	Time    time.Time
+	Samples int
+	Values  []float64
+}
+
+type bucket struct {
+	start   time.Time
+	samples int
+	sums    map[string][]float64
+}
+
+type tier struct {
+	Tier
+	buckets []bucket
+}
+
+// Store is an in-memory, fixed-width time series keyed by string. Each
+// sample gives a row of values per key; keys missing from a sample count as
+// zero. Only per-bucket sums are kept, so memory depends on the number of
+// keys and tiers, not on how often samples are added. It is safe for
+// concurrent use.
+type Store struct {
+	mu    sync.RWMutex
+	width int
+	tiers []*tier
+}
+
+// New creates a store whose rows have width values, with tiers listed from
+// finest to coarsest.
+func New(width int, tiers ...Tier) *Store {
+	store := &Store{width: width}
+	for _, t := range tiers {
+		store.tiers = append(store.tiers, &tier{Tier: t})
+	}
+	return store
+}
+
+func (s *Store) Tiers() []Tier {
+	tiers := make([]Tier, len(s.tiers))
+	for i, t := range s.tiers {
+		tiers[i] = t.Tier
+	}
+	return tiers
+}
+
+// Add records one sample taken at the given time and drops buckets that
+// have fallen out of their tier's retention. Samples must arrive in time
+// order.
+func (s *Store) Add(at time.Time, rows map[string][]float64) {
+	at = at.UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tiers {
+		start := at.Truncate(t.Step)
+		n := len(t.buckets)
+		if n == 0 || t.buckets[n-1].start.Before(start) {
+			t.buckets = append(t.buckets, bucket{start: start, sums: make(map[string][]float64, len(rows))})
+			n++
+		}
+		b := &t.buckets[n-1]
+		b.samples++
+		for key, values := range rows {
+			sums, ok := b.sums[key]
+			if !ok {
+				sums = make([]float64, s.width)
+				b.sums[key] = sums
+			}
+			for i := 0; i < s.width && i < len(values); i++ {
+				sums[i] += values[i]
+			}
+		}
+
+		cutoff := at.Add(-t.Retention)
+		expired := sort.Search(len(t.buckets), func(i int) bool {
+			return !t.buckets[i].start.Before(cutoff)
+		})
+		if expired > 0 {
+			t.buckets = append(t.buckets[:0], t.buckets[expired:]...)
+		}
+	}
+}
+
+// Query returns the points of the named tier whose buckets start within
+// [from, to], summing the keys accepted by match.
+func (s *Store) Query(resolution string, from, to time.Time, match func(key string) bool) ([]Point, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var t *tier
+	for _, candidate := range s.tiers {
+		if candidate.Name == resolution {
+			t = candidate
+		}
+	}
+	if t == nil {
+		return nil, ErrUnknownResolution
+	}
+
+	from = from.UTC().Truncate(t.Step)
+	points := []Point{}
+	for _, b := range t.buckets {
+		if b.start.Before(from) || b.start.After(to) {
+			continue
+		}
+		point := Point{Time: b.start, Samples: b.samples, Values: make([]float64, s.width)}
+		for key, sums := range b.sums {
+			if !match(key) {
+				continue
+			}
+			for i, sum := range sums {
+				point.Values[i] += sum
+			}
+		}
+		for i := range point.Values {
+			point.Values[i] /= float64(b.samples)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
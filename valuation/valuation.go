@@ -0,0 +1,300 @@
+package valuation
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+// costLayer is a receipt's remaining kilograms in a FIFO queue.
+type costLayer struct {
+	potatoID  string
+	kg        float64
+	costPerKg float64
+}
+
+// ledger is the replayed state of one variety under every method at once.
+type ledger struct {
+	valuation models.VarietyValuation
+
+	onHand map[string]float64 // specific identification: potato ID to cost
+	fifo   []costLayer
+
+	poolKg, poolCost float64 // weighted average
+
+	cogs map[models.ValuationMethod]float64
+}
+
+// Compute replays stock movements up to query.AsOf, starting from the
+// state saved in checkpoint. Stock is costed by weight: FIFO removes the
+// oldest kilograms of a variety first, weighted average removes them at the
+// variety's running average cost per kg, and specific identification uses
+// the removed potato's own cost. Costs of goods removed only count removals
+// from query.From on. An adjustment takes the potato out as it was, at no
+// cost of goods, and receives it again as corrected, so a correction changes
+// values from when it was made.
+func Compute(checkpoint models.StockCheckpoint, movements []models.StockMovement, query models.ValuationQuery) models.ValuationReport {
+	ledgers, _ := replay(checkpoint, movements, query.AsOf, query.From)
+	for variety := range ledgers {
+		if query.Variety != "" && !strings.EqualFold(variety, query.Variety) {
+			delete(ledgers, variety)
+		}
+	}
+
+	report := models.ValuationReport{
+		AsOf:      query.AsOf,
+		Varieties: []models.VarietyValuation{},
+	}
+	totals := make(map[models.ValuationMethod]*models.MethodValuation)
+	for _, method := range query.Methods {
+		totals[method] = &models.MethodValuation{Method: method}
+	}
+
+	for _, l := range ledgers {
+		for _, cost := range l.onHand {
+			if cost == 0 {
+				report.Uncosted++
+			}
+		}
+		valuation := l.valuation
+		valuation.OnHandCount = len(l.onHand)
+		valuation.OnHandKg = round(valuation.OnHandKg, 3)
+		valuation.RemovedKg = round(valuation.RemovedKg, 3)
+		for _, method := range query.Methods {
+			value := round(l.inventoryValue(method), 2)
+			cogs := round(l.cogs[method], 2)
+			valuation.Valuations = append(valuation.Valuations, models.MethodValuation{
+				Method:             method,
+				InventoryValue:     value,
+				CostOfGoodsRemoved: cogs,
+			})
+			totals[method].InventoryValue += value
+			totals[method].CostOfGoodsRemoved += cogs
+		}
+		report.Varieties = append(report.Varieties, valuation)
+	}
+	sort.Slice(report.Varieties, func(i, j int) bool {
+		return report.Varieties[i].Variety < report.Varieties[j].Variety
+	})
+
+	for _, method := range query.Methods {
+		total := *totals[method]
+		total.InventoryValue = round(total.InventoryValue, 2)
+		total.CostOfGoodsRemoved = round(total.CostOfGoodsRemoved, 2)
+		report.Totals = append(report.Totals, total)
+	}
+	return report
+}
+
+// Checkpoint carries checkpoint forward over movements, which follow it
+// oldest first, and returns the state after the last of them. The movements
+// can then be dropped: Compute gives the same values from the new
+// checkpoint, though it no longer costs the removals among them.
+func Checkpoint(checkpoint models.StockCheckpoint, movements []models.StockMovement) models.StockCheckpoint {
+	if len(movements) == 0 {
+		return checkpoint
+	}
+	timestamp := movements[len(movements)-1].Timestamp
+	ledgers, inStock := replay(checkpoint, movements, timestamp, timestamp)
+
+	next := models.StockCheckpoint{
+		Timestamp: timestamp,
+		InStock:   make([]models.StockMovement, 0, len(inStock)),
+		Balances:  make([]models.OpeningBalance, 0, len(ledgers)),
+	}
+	for _, movement := range inStock {
+		next.InStock = append(next.InStock, movement)
+	}
+	sort.Slice(next.InStock, func(i, j int) bool {
+		return next.InStock[i].PotatoID < next.InStock[j].PotatoID
+	})
+	for variety, l := range ledgers {
+		balance := models.OpeningBalance{
+			Variety:  variety,
+			Layers:   make([]models.CostLayer, 0, len(l.fifo)),
+			PoolKg:   l.poolKg,
+			PoolCost: l.poolCost,
+		}
+		for _, layer := range l.fifo {
+			balance.Layers = append(balance.Layers, models.CostLayer{PotatoID: layer.potatoID, Kg: layer.kg, CostPerKg: layer.costPerKg})
+		}
+		next.Balances = append(next.Balances, balance)
+	}
+	sort.Slice(next.Balances, func(i, j int) bool {
+		return next.Balances[i].Variety < next.Balances[j].Variety
+	})
+	return next
+}
+
+// replay restores the ledgers saved in checkpoint and applies movements up
+// to asOf. It also returns each potato on hand as last received or
+// adjusted; a correction may have moved it to another variety's ledger.
+func replay(checkpoint models.StockCheckpoint, movements []models.StockMovement, asOf, from time.Time) (map[string]*ledger, map[string]models.StockMovement) {
+	ledgers := make(map[string]*ledger)
+	ledgerFor := func(variety string) *ledger {
+		l, ok := ledgers[variety]
+		if !ok {
+			l = &ledger{
+				valuation: models.VarietyValuation{Variety: variety},
+				onHand:    make(map[string]float64),
+				cogs:      make(map[models.ValuationMethod]float64),
+			}
+			ledgers[variety] = l
+		}
+		return l
+	}
+
+	inStock := make(map[string]models.StockMovement, len(checkpoint.InStock))
+	for _, balance := range checkpoint.Balances {
+		l := ledgerFor(balance.Variety)
+		for _, layer := range balance.Layers {
+			l.fifo = append(l.fifo, costLayer{potatoID: layer.PotatoID, kg: layer.Kg, costPerKg: layer.CostPerKg})
+		}
+		l.poolKg, l.poolCost = balance.PoolKg, balance.PoolCost
+	}
+	for _, movement := range checkpoint.InStock {
+		l := ledgerFor(movement.Variety)
+		l.onHand[movement.PotatoID] = movement.PurchaseCost
+		l.valuation.OnHandKg += movement.WeightKg
+		inStock[movement.PotatoID] = movement
+	}
+
+	for _, movement := range movements {
+		if movement.Timestamp.After(asOf) {
+			break
+		}
+		switch movement.Type {
+		case models.StockReceipt:
+			ledgerFor(movement.Variety).receive(movement)
+			inStock[movement.PotatoID] = movement
+		case models.StockAdjustment:
+			if previous, ok := inStock[movement.PotatoID]; ok {
+				ledgerFor(previous.Variety).withdraw(previous)
+			}
+			ledgerFor(movement.Variety).receive(movement)
+			inStock[movement.PotatoID] = movement
+		case models.StockRemoval:
+			variety := movement.Variety
+			if previous, ok := inStock[movement.PotatoID]; ok {
+				variety = previous.Variety
+			}
+			ledgerFor(variety).remove(movement, !movement.Timestamp.Before(from))
+			delete(inStock, movement.PotatoID)
+		}
+	}
+	return ledgers, inStock
+}
+
+func (l *ledger) receive(movement models.StockMovement) {
+	l.onHand[movement.PotatoID] = movement.PurchaseCost
+	l.valuation.OnHandKg += movement.WeightKg
+	if movement.WeightKg > 0 {
+		l.fifo = append(l.fifo, costLayer{potatoID: movement.PotatoID, kg: movement.WeightKg, costPerKg: movement.PurchaseCost / movement.WeightKg})
+	}
+	l.poolKg += movement.WeightKg
+	l.poolCost += movement.PurchaseCost
+}
+
+// withdraw takes a potato out of stock for a correction. FIFO gives up the
+// potato's own layer first, and the weighted-average pool the cost the
+// potato brought in.
+func (l *ledger) withdraw(received models.StockMovement) {
+	if _, ok := l.onHand[received.PotatoID]; !ok {
+		return
+	}
+	delete(l.onHand, received.PotatoID)
+	l.valuation.OnHandKg -= received.WeightKg
+
+	remaining := received.WeightKg
+	for i := range l.fifo {
+		if l.fifo[i].potatoID == received.PotatoID {
+			taken := math.Min(l.fifo[i].kg, remaining)
+			l.fifo[i].kg -= taken
+			remaining -= taken
+			if l.fifo[i].kg <= 1e-9 {
+				l.fifo = append(l.fifo[:i], l.fifo[i+1:]...)
+			}
+			break
+		}
+	}
+	l.takeOldest(remaining)
+
+	l.poolKg -= received.WeightKg
+	l.poolCost = math.Max(l.poolCost-received.PurchaseCost, 0)
+	if l.poolKg <= 1e-9 {
+		l.poolKg, l.poolCost = 0, 0
+	}
+}
+
+// takeOldest removes kilograms from the front of the FIFO queue and
+// returns their cost.
+func (l *ledger) takeOldest(kg float64) float64 {
+	cost := 0.0
+	for remaining := kg; remaining > 1e-9 && len(l.fifo) > 0; {
+		head := &l.fifo[0]
+		taken := math.Min(head.kg, remaining)
+		cost += taken * head.costPerKg
+		head.kg -= taken
+		remaining -= taken
+		if head.kg <= 1e-9 {
+			l.fifo = l.fifo[1:]
+		}
+	}
+	return cost
+}
+
+func (l *ledger) remove(movement models.StockMovement, inPeriod bool) {
+	cost, ok := l.onHand[movement.PotatoID]
+	if !ok {
+		// Never received, so there is nothing to cost.
+		return
+	}
+	delete(l.onHand, movement.PotatoID)
+	kg := movement.WeightKg
+	l.valuation.OnHandKg -= kg
+
+	fifoCost := l.takeOldest(kg)
+
+	averageCost := 0.0
+	if l.poolKg > 0 {
+		averageCost = kg * l.poolCost / l.poolKg
+	}
+	l.poolKg -= kg
+	l.poolCost -= averageCost
+	if l.poolKg <= 1e-9 {
+		l.poolKg, l.poolCost = 0, 0
+	}
+
+	if inPeriod {
+		l.valuation.RemovedCount++
+		l.valuation.RemovedKg += kg
+		l.cogs[models.FIFOValuation] += fifoCost
+		l.cogs[models.WeightedAverage] += averageCost
+		l.cogs[models.SpecificIdentification] += cost
+	}
+}
+
+func (l *ledger) inventoryValue(method models.ValuationMethod) float64 {
+	value := 0.0
+	switch method {
+	case models.FIFOValuation:
+		for _, layer := range l.fifo {
+			value += layer.kg * layer.costPerKg
+		}
+	case models.WeightedAverage:
+		value = l.poolCost
+	case models.SpecificIdentification:
+		for _, cost := range l.onHand {
+			value += cost
+		}
+	}
+	return value
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
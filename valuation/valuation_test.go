@@ -0,0 +1,160 @@
+package valuation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/williamdumont/potato-demo/models"
+)
+
+var start = time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+func receipt(minute int, id, variety string, kg, cost float64) models.StockMovement {
+	return models.StockMovement{Type: models.StockReceipt, PotatoID: id, Variety: variety, WeightKg: kg, PurchaseCost: cost, Timestamp: start.Add(time.Duration(minute) * time.Minute)}
+}
+
+func removal(minute int, id, variety string, kg float64) models.StockMovement {
+	return models.StockMovement{Type: models.StockRemoval, PotatoID: id, Variety: variety, WeightKg: kg, Reason: models.RemovalSold, Timestamp: start.Add(time.Duration(minute) * time.Minute)}
+}
+
+func TestCheckpointMatchesFullReplay(t *testing.T) {
+	adjusted := receipt(4, "p2", "Yukon Gold", 0.5, 1.5)
+	adjusted.Type = models.StockAdjustment
+	movements := []models.StockMovement{
+		receipt(0, "p1", "Russet", 0.4, 1.2),
+		receipt(1, "p2", "Russet", 0.5, 1.0),
+		receipt(2, "p3", "Yukon Gold", 0.3, 0.9),
+		removal(3, "p1", "Russet", 0.4),
+		adjusted,
+		receipt(5, "p4", "Russet", 0.6, 2.4),
+		removal(6, "p2", "Yukon Gold", 0.5),
+		receipt(7, "p5", "Yukon Gold", 0.2, 0.8),
+		removal(8, "p4", "Russet", 0.6),
+		removal(9, "p3", "Yukon Gold", 0.3),
+	}
+	asOf := movements[len(movements)-1].Timestamp
+
+	for split := 1; split < len(movements); split++ {
+		query := models.ValuationQuery{AsOf: asOf, From: movements[split].Timestamp, Methods: models.ValuationMethods}
+		want := Compute(models.StockCheckpoint{}, movements, query)
+
+		// Checkpoint in two steps, as storage does when it trims twice.
+		half := split / 2
+		checkpoint := Checkpoint(Checkpoint(models.StockCheckpoint{}, movements[:half]), movements[half:split])
+		if !checkpoint.Timestamp.Equal(movements[split-1].Timestamp) {
+			t.Errorf("split %d: checkpoint at %v, want %v", split, checkpoint.Timestamp, movements[split-1].Timestamp)
+		}
+		got := Compute(checkpoint, movements[split:], query)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("split %d:\n got %+v\nwant %+v", split, got, want)
+		}
+	}
+}
+
+func TestCheckpointKeepsAdjustedVariety(t *testing.T) {
+	adjusted := receipt(1, "p1", "Yukon Gold", 0.5, 2.0)
+	adjusted.Type = models.StockAdjustment
+	checkpoint := Checkpoint(models.StockCheckpoint{}, []models.StockMovement{receipt(0, "p1", "Russet", 0.5, 1.0), adjusted})
+
+	if len(checkpoint.InStock) != 1 || checkpoint.InStock[0].Variety != "Yukon Gold" {
+		t.Fatalf("in stock = %+v, want p1 as Yukon Gold", checkpoint.InStock)
+	}
+	// The removal still names the variety first received; it must be taken
+	// from the Yukon Gold ledger.
+	report := Compute(checkpoint, []models.StockMovement{removal(2, "p1", "Russet", 0.5)}, models.ValuationQuery{AsOf: start.Add(time.Hour), Methods: models.ValuationMethods})
+	for _, variety := range report.Varieties {
+		if variety.Variety == "Yukon Gold" && variety.Valuations[2].CostOfGoodsRemoved != 2.0 {
+			t.Errorf("Yukon Gold cost of goods = %v, want 2", variety.Valuations[2].CostOfGoodsRemoved)
+		}
+		if variety.OnHandCount != 0 {
+			t.Errorf("%s on hand = %d, want 0", variety.Variety, variety.OnHandCount)
+		}
+	}
+}
+
+func TestCostOfGoodsByMethod(t *testing.T) {
+	type values struct{ inventory, cogs float64 }
+	tests := []struct {
+		name      string
+		movements []models.StockMovement
+		from      int // minute from which removals are costed
+		want      map[models.ValuationMethod]values
+	}{
+		{
+			name: "newest potato removed",
+			movements: []models.StockMovement{
+				receipt(0, "p1", "Russet", 1, 1),
+				receipt(1, "p2", "Russet", 1, 3),
+				removal(2, "p2", "Russet", 1),
+			},
+			want: map[models.ValuationMethod]values{
+				models.FIFOValuation:          {inventory: 3, cogs: 1},
+				models.WeightedAverage:        {inventory: 2, cogs: 2},
+				models.SpecificIdentification: {inventory: 1, cogs: 3},
+			},
+		},
+		{
+			name: "removal spans two FIFO layers",
+			movements: []models.StockMovement{
+				receipt(0, "p1", "Russet", 0.5, 1),
+				receipt(1, "p2", "Russet", 1, 4),
+				removal(2, "p2", "Russet", 1),
+			},
+			want: map[models.ValuationMethod]values{
+				models.FIFOValuation:          {inventory: 2, cogs: 3},
+				models.WeightedAverage:        {inventory: 1.67, cogs: 3.33},
+				models.SpecificIdentification: {inventory: 1, cogs: 4},
+			},
+		},
+		{
+			name: "removals before from are not costed",
+			movements: []models.StockMovement{
+				receipt(0, "p1", "Russet", 1, 1),
+				receipt(1, "p2", "Russet", 1, 3),
+				removal(2, "p1", "Russet", 1),
+				removal(3, "p2", "Russet", 1),
+			},
+			from: 3,
+			want: map[models.ValuationMethod]values{
+				models.FIFOValuation:          {inventory: 0, cogs: 3},
+				models.WeightedAverage:        {inventory: 0, cogs: 2},
+				models.SpecificIdentification: {inventory: 0, cogs: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.ValuationQuery{
+				AsOf:    start.Add(time.Hour),
+				From:    start.Add(time.Duration(tt.from) * time.Minute),
+				Methods: models.ValuationMethods,
+			}
+			report := Compute(models.StockCheckpoint{}, tt.movements, query)
+			if len(report.Varieties) != 1 {
+				t.Fatalf("got %d varieties, want 1", len(report.Varieties))
+			}
+			for _, valuation := range report.Varieties[0].Valuations {
+				want := tt.want[valuation.Method]
+				if valuation.InventoryValue != want.inventory || valuation.CostOfGoodsRemoved != want.cogs {
+					t.Errorf("%s: inventory %v, cogs %v; want %v, %v",
+						valuation.Method, valuation.InventoryValue, valuation.CostOfGoodsRemoved, want.inventory, want.cogs)
+				}
+			}
+		})
+	}
+}
+
+func TestUncostedStock(t *testing.T) {
+	report := Compute(models.StockCheckpoint{}, []models.StockMovement{receipt(0, "p1", "Russet", 1, 0), receipt(1, "p2", "Russet", 1, 2)},
+		models.ValuationQuery{AsOf: start.Add(time.Hour), Methods: models.ValuationMethods})
+	if report.Uncosted != 1 {
+		t.Errorf("uncosted = %d, want 1", report.Uncosted)
+	}
+	for _, total := range report.Totals {
+		if total.InventoryValue != 2 {
+			t.Errorf("%s inventory = %v, want 2", total.Method, total.InventoryValue)
+		}
+	}
+}